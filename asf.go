@@ -149,22 +149,77 @@ func (p *pongMessage) String() string {
 		hex.EncodeToString(p.Reserved[:]))
 }
 
-func ping(conn net.Conn, timeout time.Duration) error {
-	res, _, err := sendMessage(conn, newPingMessage(), timeout)
-	if err != nil {
-		return err
+func (p *pongMessage) Pong() *Pong {
+	return &Pong{
+		IANA:                  p.IANA,
+		OEM:                   p.OEM,
+		SupportedEntities:     p.SupEntities,
+		SupportedInteractions: p.SupInteract,
+	}
+}
+
+// Details of the RMCP/ASF Presence Pong (Section 13.2.4)
+type Pong struct {
+	IANA                  uint32 // IANA Enterprise Number
+	OEM                   uint32 // OEM-defined
+	SupportedEntities     uint8
+	SupportedInteractions uint8
+}
+
+func (p *Pong) SupportedIPMI() bool      { return p.SupportedEntities&0x80 != 0 }
+func (p *Pong) ASFVersion() uint8        { return p.SupportedEntities & 0x0f }
+func (p *Pong) SecurityExtensions() bool { return p.SupportedInteractions&0x80 != 0 }
+func (p *Pong) DASH() bool               { return p.SupportedInteractions&0x20 != 0 }
+
+func (p *Pong) String() string {
+	return fmt.Sprintf(
+		`{"IANA":%d,"OEM":%d,"SupportedEntities":%d,"SupportedInteractions":%d}`,
+		p.IANA, p.OEM, p.SupportedEntities, p.SupportedInteractions)
+}
+
+// Returns the pong even if the endpoint does not support IPMI.
+// IPMI packets received while waiting are passed to `late` if it is not nil.
+func ping(conn net.Conn, args *Arguments, late func(response, []byte)) (*Pong, error) {
+	if err := writeMessage(conn, newPingMessage(), args.Timeout, args.Trace); err != nil {
+		return nil, err
 	}
 
-	pong, ok := res.(*pongMessage)
-	if !ok {
-		return &MessageError{
-			Message: "Received an unexpected message (Ping)",
-			Detail:  res.String(),
+	var pong *pongMessage
+	deadline := time.Now().Add(args.Timeout)
+	for pong == nil {
+		timeout := deadline.Sub(time.Now())
+		if timeout <= 0 {
+			return nil, errResponseTimeout
+		}
+
+		res, msg, err := recvMessage(conn, nil, timeout, args)
+		if err != nil {
+			if _, ok := err.(*MessageError); ok && err != ErrMessageTruncated {
+				// Discard a broken or unexpected datagram
+				continue
+			}
+			return nil, err
+		}
+
+		switch r := res.(type) {
+		case *pongMessage:
+			pong = r
+		case *ipmiPacket:
+			// A late response or a payload of the session sharing the connection
+			if late != nil {
+				late(r, msg)
+			}
+		default:
+			return nil, &MessageError{
+				Message: "Received an unexpected message (Ping)",
+				Detail:  res.String(),
+			}
 		}
 	}
+
 	if !pong.SupportedIPMI() {
-		return ErrNotSupportedIPMI
+		return pong.Pong(), ErrNotSupportedIPMI
 	}
 
-	return nil
+	return pong.Pong(), nil
 }
@@ -0,0 +1,287 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Parameter of the system boot options (Table 28-14)
+type BootOptionParameter interface {
+	Selector() uint8
+	Marshal() ([]byte, error)
+	Unmarshal(buf []byte) ([]byte, error)
+}
+
+// Boot option parameter selectors (Table 28-14)
+const (
+	BootOptionSetInProgress          uint8 = 0
+	BootOptionServicePartitionSelect uint8 = 1
+	BootOptionServicePartitionScan   uint8 = 2
+	BootOptionFlagValidBitClearing   uint8 = 3
+	BootOptionBootInfoAcknowledge    uint8 = 4
+	BootOptionBootFlags              uint8 = 5
+	BootOptionBootInitiatorInfo      uint8 = 6
+	BootOptionBootInitiatorMailbox   uint8 = 7
+)
+
+const (
+	bootOptionSetInProgressComplete   = 0x00
+	bootOptionSetInProgressInProgress = 0x01
+)
+
+// Boot Device Selector (Table 28-14, Boot Flags data 2)
+type BootDevice uint8
+
+const (
+	BootDeviceNoOverride BootDevice = iota
+	BootDevicePXE
+	BootDeviceDisk
+	BootDeviceDiskSafeMode
+	BootDeviceDiagnostic
+	BootDeviceCDROM
+	BootDeviceBIOSSetup
+	BootDeviceRemoteFloppy
+	BootDeviceRemoteCDROM
+	BootDeviceRemoteMedia
+	_
+	BootDeviceRemoteDisk
+	BootDeviceFloppy BootDevice = 0x0f
+)
+
+func (d BootDevice) String() string {
+	switch d {
+	case BootDeviceNoOverride:
+		return "No override"
+	case BootDevicePXE:
+		return "Force PXE"
+	case BootDeviceDisk:
+		return "Force boot from default Hard-drive"
+	case BootDeviceDiskSafeMode:
+		return "Force boot from default Hard-drive, request Safe Mode"
+	case BootDeviceDiagnostic:
+		return "Force boot from default Diagnostic Partition"
+	case BootDeviceCDROM:
+		return "Force boot from default CD/DVD"
+	case BootDeviceBIOSSetup:
+		return "Force boot into BIOS Setup"
+	case BootDeviceRemoteFloppy:
+		return "Force boot from remotely connected Floppy/primary removable media"
+	case BootDeviceRemoteCDROM:
+		return "Force boot from remotely connected CD/DVD"
+	case BootDeviceRemoteMedia:
+		return "Force boot from primary remote media"
+	case BootDeviceRemoteDisk:
+		return "Force boot from remotely connected Hard-drive"
+	case BootDeviceFloppy:
+		return "Force boot from Floppy/primary removable media"
+	default:
+		return fmt.Sprintf("Reserved(%d)", d)
+	}
+}
+
+// Set In Progress parameter (Selector 0)
+type BootSetInProgress struct {
+	State uint8 // (0x00: Set complete, 0x01: Set in progress, 0x02: Commit write)
+}
+
+func (p *BootSetInProgress) Selector() uint8          { return BootOptionSetInProgress }
+func (p *BootSetInProgress) Marshal() ([]byte, error) { return []byte{p.State & 0x03}, nil }
+
+func (p *BootSetInProgress) Unmarshal(buf []byte) ([]byte, error) {
+	if err := bootOptionValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.State = buf[0] & 0x03
+	return buf[1:], nil
+}
+
+// Boot Info Acknowledge parameter (Selector 4)
+type BootInfoAcknowledge struct {
+	WriteMask uint8 // Bits to be written (Only for the request)
+	Pending   uint8 // Bitmap of the handlers, 1b means the boot info has not been acknowledged yet
+}
+
+func (p *BootInfoAcknowledge) Selector() uint8 { return BootOptionBootInfoAcknowledge }
+
+func (p *BootInfoAcknowledge) Marshal() ([]byte, error) {
+	return []byte{p.WriteMask, p.Pending}, nil
+}
+
+func (p *BootInfoAcknowledge) Unmarshal(buf []byte) ([]byte, error) {
+	if err := bootOptionValidateLength(p, buf, 2); err != nil {
+		return nil, err
+	}
+	p.Pending = buf[1]
+	return buf[2:], nil
+}
+
+// Boot Flags parameter (Selector 5)
+type BootFlags struct {
+	Valid                   bool // Boot flags are valid
+	Persistent              bool // Apply to all future boots instead of the next boot only
+	EFI                     bool // EFI boot instead of PC compatible (legacy) boot
+	ClearCMOS               bool
+	LockKeyboard            bool
+	Device                  BootDevice
+	ScreenBlank             bool
+	LockResetButton         bool
+	LockPowerButton         bool
+	FirmwareVerbosity       uint8 // (0: Default, 1: Quiet, 2: Verbose)
+	ForceProgressEventTraps bool
+	BypassUserPassword      bool
+	LockSleepButton         bool
+	ConsoleRedirection      uint8 // (0: BIOS setting, 1: Suppress, 2: Request enabled)
+	BIOSSharedModeOverride  bool
+	BIOSMuxControl          uint8
+	DeviceInstance          uint8 // Device instance selector (0: Default)
+}
+
+func (p *BootFlags) Selector() uint8 { return BootOptionBootFlags }
+
+func (p *BootFlags) Marshal() ([]byte, error) {
+	buf := make([]byte, 5)
+	if p.Valid {
+		buf[0] |= 0x80
+	}
+	if p.Persistent {
+		buf[0] |= 0x40
+	}
+	if p.EFI {
+		buf[0] |= 0x20
+	}
+
+	if p.ClearCMOS {
+		buf[1] |= 0x80
+	}
+	if p.LockKeyboard {
+		buf[1] |= 0x40
+	}
+	buf[1] |= byte(p.Device) & 0x0f << 2
+	if p.ScreenBlank {
+		buf[1] |= 0x02
+	}
+	if p.LockResetButton {
+		buf[1] |= 0x01
+	}
+
+	if p.LockPowerButton {
+		buf[2] |= 0x80
+	}
+	buf[2] |= p.FirmwareVerbosity & 0x03 << 5
+	if p.ForceProgressEventTraps {
+		buf[2] |= 0x10
+	}
+	if p.BypassUserPassword {
+		buf[2] |= 0x08
+	}
+	if p.LockSleepButton {
+		buf[2] |= 0x04
+	}
+	buf[2] |= p.ConsoleRedirection & 0x03
+
+	if p.BIOSSharedModeOverride {
+		buf[3] |= 0x08
+	}
+	buf[3] |= p.BIOSMuxControl & 0x07
+
+	buf[4] = p.DeviceInstance & 0x1f
+	return buf, nil
+}
+
+func (p *BootFlags) Unmarshal(buf []byte) ([]byte, error) {
+	if err := bootOptionValidateLength(p, buf, 5); err != nil {
+		return nil, err
+	}
+	p.Valid = buf[0]&0x80 != 0
+	p.Persistent = buf[0]&0x40 != 0
+	p.EFI = buf[0]&0x20 != 0
+
+	p.ClearCMOS = buf[1]&0x80 != 0
+	p.LockKeyboard = buf[1]&0x40 != 0
+	p.Device = BootDevice(buf[1] >> 2 & 0x0f)
+	p.ScreenBlank = buf[1]&0x02 != 0
+	p.LockResetButton = buf[1]&0x01 != 0
+
+	p.LockPowerButton = buf[2]&0x80 != 0
+	p.FirmwareVerbosity = buf[2] >> 5 & 0x03
+	p.ForceProgressEventTraps = buf[2]&0x10 != 0
+	p.BypassUserPassword = buf[2]&0x08 != 0
+	p.LockSleepButton = buf[2]&0x04 != 0
+	p.ConsoleRedirection = buf[2] & 0x03
+
+	p.BIOSSharedModeOverride = buf[3]&0x08 != 0
+	p.BIOSMuxControl = buf[3] & 0x07
+
+	p.DeviceInstance = buf[4] & 0x1f
+	return buf[5:], nil
+}
+
+// Boot option parameter which is not decoded
+type BootOptionRaw struct {
+	ID   uint8 // Parameter selector
+	Data []byte
+}
+
+func (p *BootOptionRaw) Selector() uint8          { return p.ID }
+func (p *BootOptionRaw) Marshal() ([]byte, error) { return p.Data, nil }
+
+func (p *BootOptionRaw) Unmarshal(buf []byte) ([]byte, error) {
+	p.Data = make([]byte, len(buf))
+	copy(p.Data, buf)
+	return nil, nil
+}
+
+func bootOptionValidateLength(p BootOptionParameter, buf []byte, min int) error {
+	if l := len(buf); l < min {
+		return &MessageError{
+			Message: fmt.Sprintf("Invalid boot option parameter %d size : %d/%d", p.Selector(), l, min),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	return nil
+}
+
+// Overrides the boot device of the next boot, or of all future boots if `persistent` is true.
+func (c *Client) SetNextBoot(device BootDevice, persistent, efi bool) error {
+	// Some BMCs do not support the set in progress parameter
+	lock := &SetSystemBootOptionsCommand{
+		Parameter: &BootSetInProgress{State: bootOptionSetInProgressInProgress},
+	}
+	err := c.Execute(lock)
+	if _, ok := err.(*CommandError); err != nil && !ok {
+		return err
+	}
+	locked := err == nil
+
+	err = c.setNextBoot(device, persistent, efi)
+
+	if locked {
+		unlock := &SetSystemBootOptionsCommand{
+			Parameter: &BootSetInProgress{State: bootOptionSetInProgressComplete},
+		}
+		if e := c.Execute(unlock); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (c *Client) setNextBoot(device BootDevice, persistent, efi bool) error {
+	// Clear the acknowledge of the BIOS/POST
+	ack := &SetSystemBootOptionsCommand{
+		Parameter: &BootInfoAcknowledge{WriteMask: 0x01, Pending: 0x01},
+	}
+	if err := c.Execute(ack); err != nil {
+		return err
+	}
+
+	flags := &SetSystemBootOptionsCommand{
+		Parameter: &BootFlags{
+			Valid:      true,
+			Persistent: persistent,
+			EFI:        efi,
+			Device:     device,
+		},
+	}
+	return c.Execute(flags)
+}
@@ -0,0 +1,35 @@
+package ipmigo
+
+// Destination of a bridged request (Section 6.13)
+type BridgeTarget struct {
+	Channel uint8 // Channel number to reach the controller
+	Address uint8 // Slave address of the controller
+}
+
+// Returns a command that is bridged through the targets in order.
+// The last target is the controller that executes the command.
+// (e.g. The transit target and then the target for double bridging)
+func NewBridgedCommand(cmd Command, targets ...BridgeTarget) Command {
+	for i := len(targets) - 1; i >= 0; i-- {
+		var rqAddr uint8 = bmcSlaveAddress
+		if i > 0 {
+			rqAddr = targets[i-1].Address
+		}
+
+		cmd = &SendMessageCommand{
+			Channel:  targets[i].Channel,
+			Tracking: true,
+			RsAddr:   targets[i].Address,
+			RqAddr:   rqAddr,
+			Command:  cmd,
+		}
+	}
+	return cmd
+}
+
+// Returns `true` if the response is an acknowledge of the tracked request,
+// then the response of the bridged request will be received as a separate message.
+func isBridgeAcknowledge(cmd Command, rsm *ipmiResponseMessage) bool {
+	c, ok := cmd.(*SendMessageCommand)
+	return ok && c.Tracking && len(rsm.Data) == 0
+}
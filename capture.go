@@ -0,0 +1,61 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Raw bytes of the executed command
+type CommandCapture struct {
+	Request        []byte // Request data as marshaled, including the group ID of the Group Extension
+	Response       []byte // Response data following the completion code
+	CompletionCode CompletionCode
+}
+
+func (c *CommandCapture) String() string {
+	return fmt.Sprintf(`{"Request":"%s","Response":"%s","CompletionCode":%d}`,
+		hex.EncodeToString(c.Request), hex.EncodeToString(c.Response), c.CompletionCode)
+}
+
+// A capturedCommand records the request and response data of the command
+type capturedCommand struct {
+	Command
+	capture *CommandCapture
+}
+
+func (c *capturedCommand) Retryable() bool { return cmdRetryable(c.Command) }
+
+func (c *capturedCommand) Marshal() ([]byte, error) {
+	buf, err := cmdMarshal(c.Command)
+	if err != nil {
+		return nil, err
+	}
+	c.capture.Request = append([]byte(nil), buf...)
+	return buf, nil
+}
+
+func (c *capturedCommand) Unmarshal(buf []byte) ([]byte, error) {
+	c.capture.Response = append([]byte(nil), buf...)
+	return cmdUnmarshal(c.Command, buf)
+}
+
+// Executes the command and returns the raw bytes of the request and the response.
+// The capture is returned with the error if the request has been sent, and the CommandError
+// also has the request data.
+func (c *Client) ExecuteCapture(cmd Command) (*CommandCapture, error) {
+	capture := &CommandCapture{}
+	err := c.Execute(&capturedCommand{Command: cmd, capture: capture})
+	if e, ok := err.(*CommandError); ok {
+		if _, ok := e.Command.(*capturedCommand); ok {
+			e.Command = cmd
+		}
+		e.Request = capture.Request
+		capture.Response = e.Response
+		capture.CompletionCode = e.CompletionCode
+		return capture, err
+	}
+	if err != nil && capture.Request == nil {
+		return nil, err
+	}
+	return capture, err
+}
@@ -0,0 +1,126 @@
+package ipmigo
+
+import (
+	"fmt"
+	"time"
+)
+
+const chassisEventQueueSize = 16
+
+// Type of the chassis state transition
+type ChassisEventType uint8
+
+const (
+	ChassisEventPowerOn ChassisEventType = iota + 1
+	ChassisEventPowerOff
+	ChassisEventIntrusion
+	ChassisEventIntrusionCleared
+	ChassisEventFanFault
+	ChassisEventFanFaultCleared
+	ChassisEventPowerFault
+	ChassisEventPowerFaultCleared
+)
+
+func (t ChassisEventType) String() string {
+	switch t {
+	case ChassisEventPowerOn:
+		return "Power On"
+	case ChassisEventPowerOff:
+		return "Power Off"
+	case ChassisEventIntrusion:
+		return "Chassis Intrusion"
+	case ChassisEventIntrusionCleared:
+		return "Chassis Intrusion Cleared"
+	case ChassisEventFanFault:
+		return "Cooling/Fan Fault"
+	case ChassisEventFanFaultCleared:
+		return "Cooling/Fan Fault Cleared"
+	case ChassisEventPowerFault:
+		return "Power Fault"
+	case ChassisEventPowerFaultCleared:
+		return "Power Fault Cleared"
+	default:
+		return fmt.Sprintf("Unknown(%d)", t)
+	}
+}
+
+// A state transition of the chassis
+type ChassisEvent struct {
+	Type   ChassisEventType
+	Time   time.Time                // Time when the transition was detected
+	Status *GetChassisStatusCommand // Chassis status after the transition
+}
+
+// A ChassisWatcher polls the chassis status and delivers the state transitions
+type ChassisWatcher struct {
+	client *Client
+	events chan ChassisEvent
+	poller *poller
+
+	last *GetChassisStatusCommand
+}
+
+// Returns the channel of the events, which is closed when the watcher is stopped
+func (w *ChassisWatcher) Events() <-chan ChassisEvent { return w.events }
+
+// Returns the last error of polling
+func (w *ChassisWatcher) Err() error { return w.poller.Err() }
+
+// Stops polling. It is safe to call more than once.
+func (w *ChassisWatcher) Stop() { w.poller.Stop() }
+
+func (w *ChassisWatcher) poll(stop <-chan struct{}) error {
+	cmd := &GetChassisStatusCommand{}
+	if err := w.client.Execute(cmd); err != nil {
+		return err
+	}
+
+	// The first status is the baseline
+	if w.last != nil {
+		for _, t := range chassisTransitions(w.last, cmd) {
+			select {
+			case w.events <- ChassisEvent{Type: t, Time: time.Now(), Status: cmd}:
+			case <-stop:
+				return nil
+			}
+		}
+	}
+	w.last = cmd
+	return nil
+}
+
+// Returns the types of the transitions between the statuses
+func chassisTransitions(prev, cur *GetChassisStatusCommand) []ChassisEventType {
+	var types []ChassisEventType
+	add := func(p, c bool, set, cleared ChassisEventType) {
+		if p != c {
+			if c {
+				types = append(types, set)
+			} else {
+				types = append(types, cleared)
+			}
+		}
+	}
+	add(prev.PowerIsOn, cur.PowerIsOn, ChassisEventPowerOn, ChassisEventPowerOff)
+	add(prev.ChassisIntrusionActive, cur.ChassisIntrusionActive, ChassisEventIntrusion, ChassisEventIntrusionCleared)
+	add(prev.CoolingFanFault, cur.CoolingFanFault, ChassisEventFanFault, ChassisEventFanFaultCleared)
+	add(prev.PowerFault, cur.PowerFault, ChassisEventPowerFault, ChassisEventPowerFaultCleared)
+	return types
+}
+
+// Polls the chassis status from a goroutine at the interval, and delivers the state transitions.
+func (c *Client) WatchChassis(interval time.Duration) (*ChassisWatcher, error) {
+	if interval <= 0 {
+		return nil, &ArgumentError{
+			Value:   interval,
+			Message: "Polling interval must be positive",
+		}
+	}
+
+	w := &ChassisWatcher{
+		client: c,
+		events: make(chan ChassisEvent, chassisEventQueueSize),
+	}
+	w.poller = newPoller(interval, true, w.poll, func() { close(w.events) })
+	return w, nil
+}
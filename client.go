@@ -2,6 +2,9 @@ package ipmigo
 
 import (
 	"fmt"
+	"net"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -37,17 +40,50 @@ func (p PrivilegeLevel) String() string {
 	}
 }
 
+// Selects the cipher suite automatically from the suites which are supported by BMC
+const CipherSuiteIDAuto = ^uint(0)
+
 // An argument for creating an IPMI Client
 type Arguments struct {
-	Version        Version        // IPMI version to use
-	Network        string         // See net.Dial parameter (The default is `udp`)
-	Address        string         // See net.Dial parameter
-	Timeout        time.Duration  // Each connect/read-write timeout (The default is 5sec)
-	Retries        uint           // Number of retries (The default is `0`)
-	Username       string         // Remote server username
-	Password       string         // Remote server password
-	PrivilegeLevel PrivilegeLevel // Session privilege level (The default is `Administrator`)
-	CipherSuiteID  uint           // ID of cipher suite, See Table 22-20 (The default is `0` which no auth and no encrypt)
+	Version         Version        // IPMI version to use
+	Network         string         // See net.Dial parameter (The default is `udp`)
+	Address         string         // See net.Dial parameter (The port 623 is used if omitted)
+	Addresses       []string       // Alternative addresses for redundant interfaces, tried in order when Address fails
+	RotateAddresses bool           // Try all A/AAAA records of each hostname in turn (The default is the first one by net.Dial)
+	Timeout         time.Duration  // Each connect/read-write timeout (The default is 5sec)
+	Retries         uint           // Number of retries (The default is `0`)
+	Backoff         Backoff        // Backoff policy for retries (The default is no delay)
+	Username        string         // Remote server username
+	Password        string         // Remote server password
+	PrivilegeLevel  PrivilegeLevel // Session privilege level (The default is `Administrator`)
+	PrivilegeLookup bool           // Use both username and privilege level for user lookup in RAKP (The default is name-only lookup)
+	AuthType        AuthType       // Forces the authentication type of v1.5 (The default is `AuthTypeNone` which selects one of AuthTypes, set only `AuthTypeNone` to AuthTypes to force none)
+	AuthTypes       []AuthType     // Authentication types of v1.5 in order of preference, a single type is forced (The default is MD5, MD2, Password, None)
+	CipherSuiteID   uint           // ID of cipher suite, See Table 22-20 (The default is `0` which no auth and no encrypt, `CipherSuiteIDAuto` selects the strongest one)
+	PipelineWindow  uint           // Number of outstanding Get SDR requests while walking SDR repository (The default is `0` which no pipelining)
+	SDRReadBytes    uint8          // Initial bytes to read of each Get SDR, which is decreased if BMC can not respond (The default is `32`)
+	FRUReadBytes    uint8          // Initial bytes to read of each Read FRU Data, which is decreased if BMC can not respond (The default is `16`)
+	CompletionRetry *RetryPolicy   // Retries the commands failed with the completion codes (The default is DefaultRetryPolicy, set an empty policy to disable)
+
+	// Bridging options (See ipmitool's `-t`, `-b`, `-T` and `-B` options)
+	TargetAddress  uint8 // Slave address to bridge requests (The default is `0` which no bridging)
+	TargetChannel  uint8 // Channel number of the target
+	TransitAddress uint8 // Slave address of the transit for double bridging (The default is `0` which single bridging)
+	TransitChannel uint8 // Channel number of the transit
+
+	// Path of the OpenIPMI device for in-band access, e.g. `/dev/ipmi0` (Linux only).
+	// The network and session options are ignored when specified.
+	Device string
+
+	// Shares UDP sockets among clients (The default is `nil` which each client has its own socket)
+	Dispatcher *Dispatcher
+
+	// Hook to trace every sent and received packet and the payloads before encryption/after decryption
+	Trace TraceFunc
+
+	// Shows the secrets such as auth codes and session keys in String() outputs and error messages,
+	// which may be logged or passed to the trace hook (The default is `false` which hides them)
+	RevealSecrets bool
 
 	// Workaround options
 
@@ -63,74 +99,326 @@ func (a *Arguments) setDefault() {
 	if a.Network == "" {
 		a.Network = "udp"
 	}
+	if a.Device == "" {
+		if a.Address != "" {
+			a.Address = withDefaultPort(a.Address)
+		}
+		addrs := make([]string, len(a.Addresses))
+		for i, addr := range a.Addresses {
+			addrs[i] = withDefaultPort(addr)
+		}
+		a.Addresses = addrs
+	}
 	if a.Timeout == 0 {
 		a.Timeout = 5 * time.Second
 	}
 	if a.PrivilegeLevel == 0 {
 		a.PrivilegeLevel = PrivilegeAdministrator
 	}
+	if a.SDRReadBytes == 0 {
+		a.SDRReadBytes = sdrDefaultReadBytes
+	}
+	if a.FRUReadBytes == 0 {
+		a.FRUReadBytes = fruDefaultReadBytes
+	}
+	if a.CompletionRetry == nil {
+		p := DefaultRetryPolicy
+		a.CompletionRetry = &p
+	}
+	if a.AuthType != AuthTypeNone {
+		a.AuthTypes = []AuthType{a.AuthType}
+	} else if len(a.AuthTypes) == 0 {
+		a.AuthTypes = []AuthType{AuthTypeMD5, AuthTypeMD2, AuthTypePassword, AuthTypeNone}
+	}
 }
 
+// Validates the arguments, and returns an ArgumentError or ArgumentErrors listing all problems
 func (a *Arguments) validate() error {
+	var errs ArgumentErrors
+	add := func(v interface{}, msg string) {
+		errs = append(errs, &ArgumentError{Value: v, Message: msg})
+	}
+
+	if a.Timeout < 0 {
+		add(a.Timeout, "Timeout must not be negative")
+	}
+	a.Backoff.validate("Backoff", add)
+	if a.CompletionRetry != nil {
+		a.CompletionRetry.Backoff.validate("Completion retry backoff", add)
+	}
+
+	if a.Device != "" {
+		return errs.orNil()
+	}
+
+	switch a.Network {
+	case "", "udp", "udp4", "udp6":
+		if a.Address == "" && len(a.Addresses) == 0 {
+			add(a.Address, "Address is required")
+		}
+		for _, addr := range append([]string{a.Address}, a.Addresses...) {
+			if addr != "" && !validAddress(addr) {
+				add(addr, "Invalid address")
+			}
+		}
+	}
+
 	switch a.Version {
 	case V2_0:
 		if len(a.Password) > passwordMaxLengthV2_0 {
-			return &ArgumentError{
-				Value:   a.Password,
-				Message: "Password is too long",
-			}
+			add(redactedSecret, "Password is too long")
+		}
+		if a.CipherSuiteID == CipherSuiteIDAuto {
+			break
 		}
 		if a.CipherSuiteID < 0 || a.CipherSuiteID > uint(len(cipherSuiteIDs)-1) {
-			return &ArgumentError{
-				Value:   a.CipherSuiteID,
-				Message: "Invalid Cipher Suite ID",
-			}
+			add(a.CipherSuiteID, "Invalid Cipher Suite ID")
 		}
-		if a.CipherSuiteID > 3 {
-			return &ArgumentError{
-				Value:   a.CipherSuiteID,
-				Message: "Unsupported Cipher Suite ID in ipmigo",
+	case V1_5:
+		if len(a.Password) > passwordMaxLengthV1_5 {
+			add(redactedSecret, "Password is too long")
+		}
+		for _, t := range append([]AuthType{a.AuthType}, a.AuthTypes...) {
+			switch t {
+			case AuthTypeNone, AuthTypeMD2, AuthTypeMD5, AuthTypePassword:
+			default:
+				add(t, "Unsupported Authentication Type")
 			}
 		}
-	case V1_5:
-		// TODO Support v1.5 ?
-		fallthrough
 	default:
-		return &ArgumentError{
-			Value:   a.Version,
-			Message: "Unsupported IPMI version",
-		}
+		add(a.Version, "Unsupported IPMI version")
 	}
 
 	if a.PrivilegeLevel < 0 || a.PrivilegeLevel > PrivilegeAdministrator {
-		return &ArgumentError{
-			Value:   a.PrivilegeLevel,
-			Message: "Invalid Privilege Level",
-		}
+		add(a.PrivilegeLevel, "Invalid Privilege Level")
+	}
+
+	if a.SDRReadBytes != 0 && a.SDRReadBytes < sdrHeaderSize {
+		add(a.SDRReadBytes, "SDR read bytes is too small")
+	}
+
+	if a.PipelineWindow > pipelineWindowMax {
+		add(a.PipelineWindow, "Pipeline window is too large")
 	}
 
 	if len(a.Username) > userNameMaxLength {
-		return &ArgumentError{
-			Value:   a.Username,
-			Message: "Username is too long",
+		add(a.Username, "Username is too long")
+	}
+	for _, c := range []byte(a.Username) {
+		// Printable ASCII characters
+		if c < 0x20 || c > 0x7e {
+			add(a.Username, "Username contains an invalid character")
+			break
 		}
 	}
 
-	return nil
+	return errs.orNil()
+}
+
+// Returns true if the address is a host with an optional port
+func validAddress(addr string) bool {
+	host, port, err := net.SplitHostPort(withDefaultPort(addr))
+	if err != nil || host == "" {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 0xffff
+}
+
+// Validates the backoff policy
+func (b *Backoff) validate(name string, add func(v interface{}, msg string)) {
+	if b.Initial < 0 {
+		add(b.Initial, name+" initial delay must not be negative")
+	}
+	if b.Max < 0 {
+		add(b.Max, name+" max delay must not be negative")
+	}
+	if b.Multiplier < 0 {
+		add(b.Multiplier, name+" multiplier must not be negative")
+	}
+	if b.Jitter < 0 || b.Jitter > 1 {
+		add(b.Jitter, name+" jitter must be 0.0 to 1.0")
+	}
+}
+
+func (a *Arguments) options() Options {
+	return Options{Timeout: a.Timeout, Retries: int(a.Retries)}
+}
+
+// Disables the retries of Options
+const NoRetry = -1
+
+// Options for executing a command, which overrides the arguments
+type Options struct {
+	Timeout time.Duration // Read-write timeout (The default is `Arguments.Timeout`)
+	Retries int           // Number of retries (The default is `Arguments.Retries`, a negative value such as `NoRetry` disables)
+}
+
+func (o Options) merge(a *Arguments) Options {
+	if o.Timeout == 0 {
+		o.Timeout = a.Timeout
+	}
+	switch {
+	case o.Retries == 0:
+		o.Retries = int(a.Retries)
+	case o.Retries < 0:
+		o.Retries = 0
+	}
+	return o
 }
 
-// IPMI Client
+// Retry policy of the commands failed with the completion codes, which are usually succeeded by a short retry.
+// Only the commands implementing RetryableCommand are retried, since the others may not be idempotent.
+type RetryPolicy struct {
+	CompletionCodes []CompletionCode // Completion codes to retry
+	Retries         uint             // Number of retries
+	Backoff         Backoff          // Backoff policy for retries
+}
+
+// Default policy which retries while BMC is busy or updating SDR repository
+var DefaultRetryPolicy = RetryPolicy{
+	CompletionCodes: []CompletionCode{CompletionNodeBusy, CompletionTimeout, CompletionSDRInUpdateMode},
+	Retries:         3,
+	Backoff:         Backoff{Initial: 100 * time.Millisecond, Multiplier: 2, Max: 2 * time.Second},
+}
+
+// Returns true if the command is retryable and the error is a command error of the completion codes to retry
+func (p *RetryPolicy) retryable(cmd Command, err error) bool {
+	if !cmdRetryable(cmd) {
+		return false
+	}
+	e, ok := err.(*CommandError)
+	if !ok {
+		return false
+	}
+	for _, code := range p.CompletionCodes {
+		if e.CompletionCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IPMI Client, which is safe for concurrent use by multiple goroutines
 type Client struct {
+	mu      sync.Mutex // Serializes the access to the session
 	session session
 	args    *Arguments
 
-	sdrReadingBytes uint8 // for GetSDRCommand(byte to read of each BMC)
+	sdrReadSize *transferSize // Bytes to read of each Get SDR, which are remembered across the walks (Guarded by mu)
+}
+
+func (c *Client) Open() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session.Open()
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session.Close()
+}
+
+func (c *Client) Ping() error {
+	_, err := c.PingInfo()
+	return err
+}
+
+// Send RMCP/ASF Presence Ping and returns the details of the pong.
+// The pong is returned with ErrNotSupportedIPMI if the endpoint does not support IPMI.
+func (c *Client) PingInfo() (*Pong, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session.Ping()
 }
 
-func (c *Client) Ping() error               { return c.session.Ping() }
-func (c *Client) Open() error               { return c.session.Open() }
-func (c *Client) Close() error              { return c.session.Close() }
-func (c *Client) Execute(cmd Command) error { return c.session.Execute(cmd) }
+func (c *Client) Execute(cmd Command) error {
+	return c.execute(cmd, c.args.options())
+}
+
+// Execute the command with overriding the timeout and the retries of the arguments
+func (c *Client) ExecuteWithOptions(cmd Command, opts Options) error {
+	return c.execute(cmd, opts.merge(c.args))
+}
+
+// Executes the command, retrying on the completion codes of the retry policy.
+// The session is not locked while waiting for the retry.
+func (c *Client) execute(cmd Command, opts Options) error {
+	policy := c.args.CompletionRetry
+	for i := 0; ; i++ {
+		if i > 0 {
+			time.Sleep(policy.Backoff.Delay(i))
+		}
+
+		c.mu.Lock()
+		err := c.session.Execute(c.bridge(cmd), opts)
+		c.mu.Unlock()
+
+		if i >= int(policy.Retries) || !policy.retryable(cmd, err) {
+			return err
+		}
+	}
+}
+
+// Executes the raw command, and returns the completion code and the response data.
+// The data is also returned if the completion code is not `CompletionOK`, and the error is returned only when
+// no response is obtained.
+func (c *Client) ExecuteRaw(netFn NetFn, lun, code uint8, data []byte) (CompletionCode, []byte, error) {
+	cmd := NewRawCommand("Raw", code, NewNetFnRsLUN(netFn, lun), data)
+	if err := c.Execute(cmd); err != nil {
+		if e, ok := err.(*CommandError); ok {
+			return e.CompletionCode, e.Response, nil
+		}
+		return 0, nil, err
+	}
+	return CompletionOK, cmd.Output(), nil
+}
+
+// Returns the parameters of the current session
+func (c *Client) SessionInfo() SessionInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session.Info()
+}
+
+// Returns true if the session is active
+func (c *Client) IsOpen() bool { return c.SessionInfo().Open }
+
+// Changes the privilege level of the current session, and returns the new level.
+// The level is also used when the session is reopened.
+func (c *Client) SetPrivilegeLevel(l PrivilegeLevel) (PrivilegeLevel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Not bridged because the command is for the session
+	cmd := newSetSessionPrivilegeCommand(l)
+	if err := c.session.Execute(cmd, c.args.options()); err != nil {
+		return 0, err
+	}
+	c.args.PrivilegeLevel = cmd.NewLevel
+	return cmd.NewLevel, nil
+}
+
+// Returns true if the requests are bridged to the target
+func (c *Client) bridged() bool {
+	return c.args.TargetAddress != 0 && c.args.TargetAddress != bmcSlaveAddress
+}
+
+// Returns a command bridged to the target if it has been specified
+func (c *Client) bridge(cmd Command) Command {
+	if !c.bridged() {
+		return cmd
+	}
+
+	a := c.args
+
+	target := BridgeTarget{Channel: a.TargetChannel, Address: a.TargetAddress}
+	if a.TransitAddress == 0 || a.TransitAddress == bmcSlaveAddress {
+		return NewBridgedCommand(cmd, target)
+	}
+	return NewBridgedCommand(cmd, BridgeTarget{Channel: a.TransitChannel, Address: a.TransitAddress}, target)
+}
 
 // Create an IPMI Client
 func NewClient(args Arguments) (*Client, error) {
@@ -140,11 +428,129 @@ func NewClient(args Arguments) (*Client, error) {
 	args.setDefault()
 
 	var s session
-	switch args.Version {
-	case V1_5:
+	switch {
+	case args.Device != "":
+		var err error
+		if s, err = newSessionOpenIPMI(&args); err != nil {
+			return nil, err
+		}
+	case args.Version == V1_5:
 		s = newSessionV1_5(&args)
-	case V2_0:
+	case args.Version == V2_0:
 		s = newSessionV2_0(&args)
 	}
-	return &Client{session: s, args: &args, sdrReadingBytes: sdrDefaultReadBytes}, nil
+	return &Client{session: s, args: &args}, nil
+}
+
+// A functional option for NewClientWithOptions
+type Option func(*Arguments)
+
+// Logger used by WithLogger, which is satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+func WithVersion(v Version) Option {
+	return func(a *Arguments) { a.Version = v }
+}
+
+func WithCredentials(username, password string) Option {
+	return func(a *Arguments) { a.Username, a.Password = username, password }
+}
+
+func WithPrivilegeLevel(l PrivilegeLevel) Option {
+	return func(a *Arguments) { a.PrivilegeLevel = l }
+}
+
+func WithCipherSuite(id uint) Option {
+	return func(a *Arguments) { a.CipherSuiteID = id }
+}
+
+func WithTimeout(d time.Duration) Option {
+	return func(a *Arguments) { a.Timeout = d }
+}
+
+func WithRetries(n uint, backoff Backoff) Option {
+	return func(a *Arguments) { a.Retries, a.Backoff = n, backoff }
+}
+
+// Retries the commands failed with the completion codes of the policy
+func WithCompletionRetry(p RetryPolicy) Option {
+	return func(a *Arguments) { a.CompletionRetry = &p }
+}
+
+func WithPipelineWindow(n uint) Option {
+	return func(a *Arguments) { a.PipelineWindow = n }
+}
+
+func WithDispatcher(d *Dispatcher) Option {
+	return func(a *Arguments) { a.Dispatcher = d }
+}
+
+// Logs every sent and received message
+func WithLogger(l Logger) Option {
+	return func(a *Arguments) {
+		a.Trace = func(dir TraceDirection, raw []byte, decoded fmt.Stringer) {
+			if decoded == nil {
+				l.Printf("ipmigo: %s %d bytes", dir, len(raw))
+			} else {
+				l.Printf("ipmigo: %s %s", dir, decoded)
+			}
+		}
+	}
+}
+
+// Create an IPMI Client with the functional options.
+// The defaults are same as Arguments, except that IPMI version is v2.0.
+func NewClientWithOptions(addr string, opts ...Option) (*Client, error) {
+	args := Arguments{Version: V2_0, Address: addr}
+	for _, opt := range opts {
+		opt(&args)
+	}
+	return NewClient(args)
+}
+
+// Bytes of each request of the chunked transfer, which are adjusted to the upper limit that BMC can handle.
+// It is kept by the transfer not to be shared between the targets.
+type transferSize struct {
+	bytes     uint8 // Current bytes of each request
+	max       uint8
+	min       uint8
+	step      uint8
+	growAfter int // Successful requests before trying the larger chunk
+	count     int // Successful requests since bytes was changed
+}
+
+func newTransferSize(max, min, step uint8, growAfter int) *transferSize {
+	return &transferSize{bytes: max, max: max, min: min, step: step, growAfter: growAfter}
+}
+
+// Decreases the bytes of each request, returns false if it can not be decreased
+func (t *transferSize) shrink() bool {
+	if t.bytes <= t.min {
+		return false
+	}
+	if t.bytes > t.step+t.min {
+		t.bytes -= t.step
+	} else {
+		t.bytes = t.min
+	}
+	t.count = 0
+	return true
+}
+
+// Tries the larger bytes after the successful requests, up to the maximum
+func (t *transferSize) grow() {
+	if t.bytes >= t.max {
+		return
+	}
+	if t.count++; t.count < t.growAfter {
+		return
+	}
+	if r := int(t.bytes) + int(t.step); r < int(t.max) {
+		t.bytes = uint8(r)
+	} else {
+		t.bytes = t.max
+	}
+	t.count = 0
 }
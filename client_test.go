@@ -0,0 +1,66 @@
+package ipmigo
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// A session which counts the sequence number without a lock like the real sessions,
+// so the race detector reports the concurrent access
+type seqTestSession struct {
+	sequence uint32
+}
+
+func (s *seqTestSession) Ping() (*Pong, error) { return &Pong{}, nil }
+func (s *seqTestSession) Open() error          { return nil }
+func (s *seqTestSession) Close() error         { return nil }
+func (s *seqTestSession) Info() SessionInfo    { return SessionInfo{} }
+
+func (s *seqTestSession) Execute(cmd Command, opts Options) error {
+	s.sequence++
+	return nil
+}
+
+func TestClientConcurrentExecute(t *testing.T) {
+	s := &seqTestSession{}
+	args := Arguments{Version: V2_0, Address: "192.0.2.1"}
+	args.setDefault()
+	c := &Client{session: s, args: &args}
+
+	const goroutines, executes = 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < executes; j++ {
+				if err := c.Execute(&GetDeviceIDCommand{}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.sequence != goroutines*executes {
+		t.Errorf("sequence = %d, expected %d", s.sequence, goroutines*executes)
+	}
+}
+
+func TestArgumentsAuthType(t *testing.T) {
+	args := Arguments{Version: V1_5, Address: "192.0.2.1", AuthType: AuthTypePassword}
+	if err := args.validate(); err != nil {
+		t.Fatal(err)
+	}
+	args.setDefault()
+	if expected := []AuthType{AuthTypePassword}; !reflect.DeepEqual(args.AuthTypes, expected) {
+		t.Errorf("AuthTypes = %v, expected %v", args.AuthTypes, expected)
+	}
+
+	args = Arguments{Version: V1_5, Address: "192.0.2.1", AuthType: AuthTypeOEM}
+	if err := args.validate(); err == nil {
+		t.Error("no error for the unsupported authentication type")
+	}
+}
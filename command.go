@@ -12,7 +12,10 @@ type CompletionCode uint8
 const (
 	CompletionOK               CompletionCode = 0x00
 	CompletionUnspecifiedError CompletionCode = 0xff
+)
 
+// Generic completion codes (Table 5-2)
+const (
 	CompletionNodeBusy CompletionCode = iota + 0xc0
 	CompletionInvalidCommand
 	CompletionInvalidCommandForLUN
@@ -38,6 +41,58 @@ const (
 	CompletionIllegalCommandDisabled
 )
 
+// Command-specific completion codes of the session commands (Section 22)
+const (
+	// Get Session Challenge
+	CompletionInvalidUserName      CompletionCode = 0x81
+	CompletionNullUserNameDisabled CompletionCode = 0x82
+
+	// Activate Session
+	CompletionNoSessionSlot          CompletionCode = 0x81
+	CompletionNoSlotForUser          CompletionCode = 0x82
+	CompletionNoSlotForPrivilege     CompletionCode = 0x83
+	CompletionSequenceOutOfRange     CompletionCode = 0x84
+	CompletionInvalidSessionID       CompletionCode = 0x85
+	CompletionPrivilegeLimitExceeded CompletionCode = 0x86
+
+	// Set Session Privilege Level
+	CompletionPrivilegeLevelUnavailable CompletionCode = 0x80
+	CompletionPrivilegeLevelExceeded    CompletionCode = 0x81
+	CompletionCantDisableUserAuth       CompletionCode = 0x82
+
+	// Close Session
+	CompletionCloseInvalidSessionID     CompletionCode = 0x87
+	CompletionCloseInvalidSessionHandle CompletionCode = 0x88
+)
+
+// Returns `true` if the completion code is device specific (OEM) code.
+func (c CompletionCode) IsDeviceSpecific() bool { return c >= 0x01 && c <= 0x7e }
+
+// Returns `true` if the completion code is command specific code, whose meaning depends on the command.
+func (c CompletionCode) IsCommandSpecific() bool { return c >= 0x80 && c <= 0xbe }
+
+// Returns `true` if the command is likely to succeed by retrying later.
+func (c CompletionCode) IsTemporary() bool {
+	switch c {
+	case CompletionNodeBusy, CompletionTimeout, CompletionSDRInUpdateMode, CompletionFirmwareUpdateMode,
+		CompletionBMCInitialization:
+		return true
+	}
+	return false
+}
+
+// Returns `true` if the command failed due to the privilege level of the session.
+func (c CompletionCode) IsPrivilegeError() bool { return c == CompletionInsufficientPrivilege }
+
+// Returns `true` if the command or the sub-function is not supported by the device.
+func (c CompletionCode) IsUnsupported() bool {
+	switch c {
+	case CompletionInvalidCommand, CompletionInvalidCommandForLUN, CompletionIllegalCommandDisabled:
+		return true
+	}
+	return false
+}
+
 func (c CompletionCode) String() string {
 	switch c {
 	case CompletionOK:
@@ -91,7 +146,7 @@ func (c CompletionCode) String() string {
 	case CompletionIllegalCommandDisabled:
 		return "Command sub-function has been disabled or is unavailable"
 	default:
-		return fmt.Sprintf("0x%02x", c)
+		return fmt.Sprintf("0x%02x", uint8(c))
 	}
 }
 
@@ -104,6 +159,18 @@ type Command interface {
 	String() string
 }
 
+// A RetryableCommand is the command which can be re-sent safely, such as the read-only commands.
+// The commands are retried on the completion codes of `Arguments.CompletionRetry`.
+type RetryableCommand interface {
+	Command
+	Retryable() bool
+}
+
+func cmdRetryable(c Command) bool {
+	r, ok := c.(RetryableCommand)
+	return ok && r.Retryable()
+}
+
 type RawCommand struct {
 	name       string
 	code       uint8
@@ -139,6 +206,37 @@ func NewRawCommand(name string, code uint8, fn NetFnRsLUN, input []byte) *RawCom
 	}
 }
 
+// Returns the raw command from the data of the hex string in the `ipmitool raw` style.
+// The data is the hex bytes separated by spaces with or without the "0x" prefix (e.g. "0x01 0x02" or "01 02"),
+// or the continuous hex string (e.g. "0102").
+func NewRawCommandHex(netFn NetFn, lun, code uint8, data string) (*RawCommand, error) {
+	input, err := parseRawHex(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewRawCommand("Raw", code, NewNetFnRsLUN(netFn, lun), input), nil
+}
+
+func parseRawHex(s string) ([]byte, error) {
+	buf := []byte{}
+	for _, f := range strings.Fields(s) {
+		h := strings.TrimPrefix(strings.TrimPrefix(f, "0x"), "0X")
+		if len(h) == 1 {
+			h = "0" + h
+		}
+		// A prefixed field is a single byte
+		b, err := hex.DecodeString(h)
+		if err != nil || len(b) == 0 || (h != f && len(b) != 1) {
+			return nil, &ArgumentError{
+				Value:   f,
+				Message: "Invalid hex data",
+			}
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
 func cmdToJSON(c Command) string {
 	s := fmt.Sprintf(`{"Name":"%s","Code":%d,"NetFnRsLUN":%d,`, c.Name(), c.Code(), c.NetFnRsLUN())
 	return strings.Replace(toJSON(c), `{`, s, 1)
@@ -2,9 +2,57 @@ package ipmigo
 
 import (
 	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 	"time"
 )
 
+// Get Chassis Capabilities Command (Section 28.1)
+type GetChassisCapabilitiesCommand struct {
+	// Response Data
+	ProvidesPowerInterlock      bool
+	ProvidesDiagnosticInterrupt bool
+	ProvidesFrontPanelLockout   bool
+	ProvidesIntrusionSensor     bool
+	FRUDeviceAddress            uint8 // Slave address of the chassis FRU info device
+	SDRDeviceAddress            uint8 // Slave address of the chassis SDR device
+	SELDeviceAddress            uint8 // Slave address of the chassis SEL device
+	SMDeviceAddress             uint8 // Slave address of the chassis system management device
+	BridgeDeviceAddress         uint8 // Slave address of the chassis bridge device (BMC if it is not returned)
+}
+
+func (c *GetChassisCapabilitiesCommand) Name() string    { return "Get Chassis Capabilities" }
+func (c *GetChassisCapabilitiesCommand) Code() uint8     { return 0x00 }
+func (c *GetChassisCapabilitiesCommand) Retryable() bool { return true }
+
+func (c *GetChassisCapabilitiesCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnChassisReq, 0)
+}
+
+func (c *GetChassisCapabilitiesCommand) String() string           { return cmdToJSON(c) }
+func (c *GetChassisCapabilitiesCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetChassisCapabilitiesCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 5); err != nil {
+		return nil, err
+	}
+	c.ProvidesPowerInterlock = buf[0]&0x08 != 0
+	c.ProvidesDiagnosticInterrupt = buf[0]&0x04 != 0
+	c.ProvidesFrontPanelLockout = buf[0]&0x02 != 0
+	c.ProvidesIntrusionSensor = buf[0]&0x01 != 0
+	c.FRUDeviceAddress = buf[1]
+	c.SDRDeviceAddress = buf[2]
+	c.SELDeviceAddress = buf[3]
+	c.SMDeviceAddress = buf[4]
+
+	if len(buf) < 6 {
+		c.BridgeDeviceAddress = bmcSlaveAddress
+		return buf[5:], nil
+	}
+	c.BridgeDeviceAddress = buf[5]
+	return buf[6:], nil
+}
+
 // Get Chassis Status Command (Section 28.2)
 type GetChassisStatusCommand struct {
 	// Response Data
@@ -27,6 +75,7 @@ type GetChassisStatusCommand struct {
 
 func (c *GetChassisStatusCommand) Name() string             { return "Get Chassis Status" }
 func (c *GetChassisStatusCommand) Code() uint8              { return 0x01 }
+func (c *GetChassisStatusCommand) Retryable() bool          { return true }
 func (c *GetChassisStatusCommand) NetFnRsLUN() NetFnRsLUN   { return NewNetFnRsLUN(NetFnChassisReq, 0) }
 func (c *GetChassisStatusCommand) String() string           { return cmdToJSON(c) }
 func (c *GetChassisStatusCommand) Marshal() ([]byte, error) { return []byte{}, nil }
@@ -55,14 +104,156 @@ func (c *GetChassisStatusCommand) Unmarshal(buf []byte) ([]byte, error) {
 	return nil, nil
 }
 
+// Chassis Control (Table 28-4)
+type ChassisControl uint8
+
+const (
+	ChassisControlPowerDown ChassisControl = iota
+	ChassisControlPowerUp
+	ChassisControlPowerCycle
+	ChassisControlHardReset
+	ChassisControlDiagnosticInterrupt
+	ChassisControlSoftShutdown
+)
+
+func (c ChassisControl) String() string {
+	switch c {
+	case ChassisControlPowerDown:
+		return "Power Down"
+	case ChassisControlPowerUp:
+		return "Power Up"
+	case ChassisControlPowerCycle:
+		return "Power Cycle"
+	case ChassisControlHardReset:
+		return "Hard Reset"
+	case ChassisControlDiagnosticInterrupt:
+		return "Pulse Diagnostic Interrupt"
+	case ChassisControlSoftShutdown:
+		return "Soft Shutdown"
+	default:
+		return fmt.Sprintf("Reserved(%d)", c)
+	}
+}
+
+// Chassis Control Command (Section 28.3)
+type ChassisControlCommand struct {
+	// Request Data
+	Control ChassisControl
+}
+
+func (c *ChassisControlCommand) Name() string           { return "Chassis Control" }
+func (c *ChassisControlCommand) Code() uint8            { return 0x02 }
+func (c *ChassisControlCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnChassisReq, 0) }
+func (c *ChassisControlCommand) String() string         { return cmdToJSON(c) }
+
+func (c *ChassisControlCommand) Marshal() ([]byte, error) {
+	return []byte{byte(c.Control) & 0x0f}, nil
+}
+
+func (c *ChassisControlCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Set Chassis Capabilities Command (Section 28.7)
+type SetChassisCapabilitiesCommand struct {
+	// Request Data
+	ProvidesFrontPanelLockout bool
+	ProvidesIntrusionSensor   bool
+	FRUDeviceAddress          uint8
+	SDRDeviceAddress          uint8
+	SELDeviceAddress          uint8
+	SMDeviceAddress           uint8
+	BridgeDeviceAddress       uint8 // (Optional, 0: Not sent)
+}
+
+func (c *SetChassisCapabilitiesCommand) Name() string { return "Set Chassis Capabilities" }
+func (c *SetChassisCapabilitiesCommand) Code() uint8  { return 0x05 }
+
+func (c *SetChassisCapabilitiesCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnChassisReq, 0)
+}
+
+func (c *SetChassisCapabilitiesCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetChassisCapabilitiesCommand) Marshal() ([]byte, error) {
+	var flags byte
+	if c.ProvidesFrontPanelLockout {
+		flags |= 0x02
+	}
+	if c.ProvidesIntrusionSensor {
+		flags |= 0x01
+	}
+
+	buf := []byte{flags, c.FRUDeviceAddress, c.SDRDeviceAddress, c.SELDeviceAddress, c.SMDeviceAddress}
+	if c.BridgeDeviceAddress != 0 {
+		buf = append(buf, c.BridgeDeviceAddress)
+	}
+	return buf, nil
+}
+
+func (c *SetChassisCapabilitiesCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// System Restart Cause (Table 28-11)
+type RestartCause uint8
+
+const (
+	RestartCauseUnknown RestartCause = iota
+	RestartCauseChassisControl
+	RestartCauseResetButton
+	RestartCausePowerButton
+	RestartCauseWatchdog
+	RestartCauseOEM
+	RestartCauseAlwaysRestore
+	RestartCauseRestorePrevious
+	RestartCausePEFReset
+	RestartCausePEFPowerCycle
+	RestartCauseSoftReset
+	RestartCauseRTCWakeup
+)
+
+func (r RestartCause) String() string {
+	switch r {
+	case RestartCauseUnknown:
+		return "Unknown"
+	case RestartCauseChassisControl:
+		return "Chassis Control command"
+	case RestartCauseResetButton:
+		return "Reset via pushbutton"
+	case RestartCausePowerButton:
+		return "Power-up via power pushbutton"
+	case RestartCauseWatchdog:
+		return "Watchdog expiration"
+	case RestartCauseOEM:
+		return "OEM"
+	case RestartCauseAlwaysRestore:
+		return "Automatic power-up on AC being applied due to 'always restore' power restore policy"
+	case RestartCauseRestorePrevious:
+		return "Automatic power-up on AC being applied due to 'restore previous power state' power restore policy"
+	case RestartCausePEFReset:
+		return "Reset via PEF"
+	case RestartCausePEFPowerCycle:
+		return "Power-cycle via PEF"
+	case RestartCauseSoftReset:
+		return "Soft reset"
+	case RestartCauseRTCWakeup:
+		return "Power-up via RTC wakeup"
+	default:
+		return fmt.Sprintf("Reserved(%d)", r)
+	}
+}
+
 // Get System Restart Cause Command (Section 28.11)
 type GetSystemRestartCauseCommand struct {
 	// Response Data
-	RestartCause uint8 // (See Table 28-11)
+	RestartCause RestartCause
+	Channel      uint8 // Channel number that the restart was initiated on (Optional)
 }
 
-func (c *GetSystemRestartCauseCommand) Name() string { return "Get System Restart Cause" }
-func (c *GetSystemRestartCauseCommand) Code() uint8  { return 0x07 }
+func (c *GetSystemRestartCauseCommand) Name() string    { return "Get System Restart Cause" }
+func (c *GetSystemRestartCauseCommand) Code() uint8     { return 0x07 }
+func (c *GetSystemRestartCauseCommand) Retryable() bool { return true }
 
 func (c *GetSystemRestartCauseCommand) NetFnRsLUN() NetFnRsLUN {
 	return NewNetFnRsLUN(NetFnChassisReq, 0)
@@ -75,8 +266,98 @@ func (c *GetSystemRestartCauseCommand) Unmarshal(buf []byte) ([]byte, error) {
 	if err := cmdValidateLength(c, buf, 1); err != nil {
 		return nil, err
 	}
-	c.RestartCause = buf[0]
-	return buf[1:], nil
+	c.RestartCause = RestartCause(buf[0] & 0x0f)
+
+	if len(buf) < 2 {
+		c.Channel = 0
+		return buf[1:], nil
+	}
+	c.Channel = buf[1] & 0x0f
+	return buf[2:], nil
+}
+
+// Set System Boot Options Command (Section 28.12)
+type SetSystemBootOptionsCommand struct {
+	// Request Data
+	Parameter   BootOptionParameter
+	MarkInvalid bool // Mark the parameter as invalid / locked
+}
+
+func (c *SetSystemBootOptionsCommand) Name() string { return "Set System Boot Options" }
+func (c *SetSystemBootOptionsCommand) Code() uint8  { return 0x08 }
+
+func (c *SetSystemBootOptionsCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnChassisReq, 0)
+}
+
+func (c *SetSystemBootOptionsCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetSystemBootOptionsCommand) Marshal() ([]byte, error) {
+	if c.Parameter == nil {
+		return nil, &ArgumentError{Value: c.Parameter, Message: "Boot option parameter is required"}
+	}
+	data, err := c.Parameter.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	n := c.Parameter.Selector() & 0x7f
+	if c.MarkInvalid {
+		n |= 0x80
+	}
+	return append([]byte{n}, data...), nil
+}
+
+func (c *SetSystemBootOptionsCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get System Boot Options Command (Section 28.13)
+type GetSystemBootOptionsCommand struct {
+	// Request Data
+	SetSelector   uint8
+	BlockSelector uint8
+
+	// Request and Response Data, the selector is taken from the parameter
+	Parameter BootOptionParameter
+
+	// Response Data
+	ParameterVersion uint8
+	ParameterValid   bool // `false` if the parameter is marked as invalid / locked
+}
+
+func (c *GetSystemBootOptionsCommand) Name() string    { return "Get System Boot Options" }
+func (c *GetSystemBootOptionsCommand) Code() uint8     { return 0x09 }
+func (c *GetSystemBootOptionsCommand) Retryable() bool { return true }
+
+func (c *GetSystemBootOptionsCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnChassisReq, 0)
+}
+
+func (c *GetSystemBootOptionsCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetSystemBootOptionsCommand) Marshal() ([]byte, error) {
+	if c.Parameter == nil {
+		return nil, &ArgumentError{Value: c.Parameter, Message: "Boot option parameter is required"}
+	}
+	return []byte{c.Parameter.Selector() & 0x7f, c.SetSelector, c.BlockSelector}, nil
+}
+
+func (c *GetSystemBootOptionsCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.ParameterVersion = buf[0] & 0x0f
+	c.ParameterValid = buf[1]&0x80 == 0
+
+	if s := buf[1] & 0x7f; s != c.Parameter.Selector()&0x7f {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Mismatch parameter selector in %s Response : %d - %d",
+				c.Name(), c.Parameter.Selector(), s),
+			Detail: hex.EncodeToString(buf),
+		}
+	}
+	return c.Parameter.Unmarshal(buf[2:])
 }
 
 // Get POH Counter Command (Section 28.14)
@@ -88,6 +369,7 @@ type GetPOHCounterCommand struct {
 
 func (c *GetPOHCounterCommand) Name() string             { return "Get POH Counter" }
 func (c *GetPOHCounterCommand) Code() uint8              { return 0x0f }
+func (c *GetPOHCounterCommand) Retryable() bool          { return true }
 func (c *GetPOHCounterCommand) NetFnRsLUN() NetFnRsLUN   { return NewNetFnRsLUN(NetFnChassisReq, 0) }
 func (c *GetPOHCounterCommand) String() string           { return cmdToJSON(c) }
 func (c *GetPOHCounterCommand) Marshal() ([]byte, error) { return []byte{}, nil }
@@ -0,0 +1,233 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Get DCMI Capabilities Info Command (DCMI v1.5 Section 6.1.1)
+type GetDCMICapabilitiesInfoCommand struct {
+	// Request and Response Data, the selector is taken from the parameter
+	Parameter DCMICapabilityParameter
+
+	// Response Data
+	MajorVersion      uint8 // DCMI specification conformance
+	MinorVersion      uint8
+	ParameterRevision uint8
+}
+
+func (c *GetDCMICapabilitiesInfoCommand) Name() string    { return "Get DCMI Capabilities Info" }
+func (c *GetDCMICapabilitiesInfoCommand) Code() uint8     { return 0x01 }
+func (c *GetDCMICapabilitiesInfoCommand) Retryable() bool { return true }
+
+func (c *GetDCMICapabilitiesInfoCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnGroupExtensionReq, 0)
+}
+
+func (c *GetDCMICapabilitiesInfoCommand) GroupID() GroupID { return GroupDCMI }
+
+func (c *GetDCMICapabilitiesInfoCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetDCMICapabilitiesInfoCommand) Marshal() ([]byte, error) {
+	if c.Parameter == nil {
+		return nil, &ArgumentError{Value: c.Parameter, Message: "DCMI capability parameter is required"}
+	}
+	return []byte{c.Parameter.Selector()}, nil
+}
+
+func (c *GetDCMICapabilitiesInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 3); err != nil {
+		return nil, err
+	}
+	c.MajorVersion = buf[0]
+	c.MinorVersion = buf[1]
+	c.ParameterRevision = buf[2]
+	return c.Parameter.Unmarshal(buf[3:])
+}
+
+// DCMI power reading modes
+const (
+	DCMIPowerReadingSystem   uint8 = 0x01 // System power statistics
+	DCMIPowerReadingEnhanced uint8 = 0x02 // Enhanced system power statistics (DCMI v1.5)
+)
+
+// Get Power Reading Command (DCMI v1.5 Section 6.6.1)
+type GetDCMIPowerReadingCommand struct {
+	// Request Data
+	Mode   uint8 // Default is DCMIPowerReadingSystem
+	Period uint8 // Rolling average time period of the enhanced mode (DCMIPowerStatistics)
+
+	// Response Data
+	CurrentPower   uint16 // Watts
+	MinimumPower   uint16 // Watts over the sampling period
+	MaximumPower   uint16 // Watts over the sampling period
+	AveragePower   uint16 // Watts over the sampling period
+	Timestamp      time.Time
+	SamplingPeriod time.Duration
+	Active         bool // Power measurement is active
+}
+
+func (c *GetDCMIPowerReadingCommand) Name() string    { return "Get DCMI Power Reading" }
+func (c *GetDCMIPowerReadingCommand) Code() uint8     { return 0x02 }
+func (c *GetDCMIPowerReadingCommand) Retryable() bool { return true }
+
+func (c *GetDCMIPowerReadingCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnGroupExtensionReq, 0)
+}
+
+func (c *GetDCMIPowerReadingCommand) GroupID() GroupID { return GroupDCMI }
+
+func (c *GetDCMIPowerReadingCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetDCMIPowerReadingCommand) Marshal() ([]byte, error) {
+	mode, period := c.Mode, c.Period
+	if mode == 0 {
+		mode = DCMIPowerReadingSystem
+	}
+	if mode != DCMIPowerReadingEnhanced {
+		period = 0
+	}
+	return []byte{mode, period, 0}, nil
+}
+
+func (c *GetDCMIPowerReadingCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 17); err != nil {
+		return nil, err
+	}
+	c.CurrentPower = binary.LittleEndian.Uint16(buf[0:])
+	c.MinimumPower = binary.LittleEndian.Uint16(buf[2:])
+	c.MaximumPower = binary.LittleEndian.Uint16(buf[4:])
+	c.AveragePower = binary.LittleEndian.Uint16(buf[6:])
+	c.Timestamp = time.Unix(int64(binary.LittleEndian.Uint32(buf[8:])), 0)
+	c.SamplingPeriod = time.Duration(binary.LittleEndian.Uint32(buf[12:])) * time.Millisecond
+	c.Active = buf[16]&0x40 != 0
+	return buf[17:], nil
+}
+
+// Get Management Controller Identifier String Command (DCMI v1.5 Section 6.4.6.1)
+type GetDCMIMCIDStringCommand struct {
+	// Request Data
+	Offset    uint8
+	ReadCount uint8 // Count to read in bytes (up to 16)
+
+	// Response Data
+	Length uint8 // Total length of the identifier string
+	Data   []byte
+}
+
+func (c *GetDCMIMCIDStringCommand) Name() string {
+	return "Get DCMI Management Controller Identifier String"
+}
+
+func (c *GetDCMIMCIDStringCommand) Code() uint8     { return 0x09 }
+func (c *GetDCMIMCIDStringCommand) Retryable() bool { return true }
+
+func (c *GetDCMIMCIDStringCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnGroupExtensionReq, 0)
+}
+
+func (c *GetDCMIMCIDStringCommand) GroupID() GroupID { return GroupDCMI }
+
+func (c *GetDCMIMCIDStringCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetDCMIMCIDStringCommand) Marshal() ([]byte, error) {
+	return []byte{c.Offset, c.ReadCount}, nil
+}
+
+func (c *GetDCMIMCIDStringCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Length = buf[0]
+	c.Data = make([]byte, len(buf)-1)
+	copy(c.Data, buf[1:])
+	return nil, nil
+}
+
+// Set Management Controller Identifier String Command (DCMI v1.5 Section 6.4.6.2)
+type SetDCMIMCIDStringCommand struct {
+	// Request Data
+	Offset uint8
+	Data   []byte // Up to 16 bytes
+
+	// Response Data
+	Length uint8 // Total length written
+}
+
+func (c *SetDCMIMCIDStringCommand) Name() string {
+	return "Set DCMI Management Controller Identifier String"
+}
+
+func (c *SetDCMIMCIDStringCommand) Code() uint8 { return 0x0a }
+
+func (c *SetDCMIMCIDStringCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnGroupExtensionReq, 0)
+}
+
+func (c *SetDCMIMCIDStringCommand) GroupID() GroupID { return GroupDCMI }
+
+func (c *SetDCMIMCIDStringCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetDCMIMCIDStringCommand) Marshal() ([]byte, error) {
+	if l := len(c.Data); l > dcmiMCIDStringBlock {
+		return nil, &ArgumentError{
+			Value:   l,
+			Message: fmt.Sprintf("Identifier string data must be up to %d bytes", dcmiMCIDStringBlock),
+		}
+	}
+	return append([]byte{c.Offset, uint8(len(c.Data))}, c.Data...), nil
+}
+
+func (c *SetDCMIMCIDStringCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Length = buf[0]
+	return buf[1:], nil
+}
+
+// Get DCMI Sensor Info Command (DCMI v1.5 Section 6.5.2)
+type GetDCMISensorInfoCommand struct {
+	// Request Data
+	SensorType     SensorType // Temperature(0x01) is supported
+	EntityID       EntityID
+	EntityInstance uint8 // 0 means all instances
+	StartInstance  uint8 // Entity instance to start with when all instances are requested
+
+	// Response Data
+	TotalInstances uint8    // Number of the instances of the entity
+	RecordIDs      []uint16 // SDR record IDs (up to 8)
+}
+
+func (c *GetDCMISensorInfoCommand) Name() string    { return "Get DCMI Sensor Info" }
+func (c *GetDCMISensorInfoCommand) Code() uint8     { return 0x07 }
+func (c *GetDCMISensorInfoCommand) Retryable() bool { return true }
+
+func (c *GetDCMISensorInfoCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnGroupExtensionReq, 0)
+}
+
+func (c *GetDCMISensorInfoCommand) GroupID() GroupID { return GroupDCMI }
+
+func (c *GetDCMISensorInfoCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetDCMISensorInfoCommand) Marshal() ([]byte, error) {
+	return []byte{uint8(c.SensorType), uint8(c.EntityID), c.EntityInstance, c.StartInstance}, nil
+}
+
+func (c *GetDCMISensorInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.TotalInstances = buf[0]
+	n := int(buf[1])
+	if err := cmdValidateLength(c, buf, 2+n*2); err != nil {
+		return nil, err
+	}
+	c.RecordIDs = make([]uint16, n)
+	for i := range c.RecordIDs {
+		c.RecordIDs[i] = binary.LittleEndian.Uint16(buf[2+i*2:])
+	}
+	return buf[2+n*2:], nil
+}
@@ -0,0 +1,119 @@
+package ipmigo
+
+// Get Command Support Command (Section 21.3)
+type GetCommandSupportCommand struct {
+	// Request Data
+	Target    FirewallTarget
+	UpperHalf bool // Commands 0x80 - 0xff instead of 0x00 - 0x7f
+
+	// Response Data
+	Supported CommandMask // Supported commands in the requested half
+}
+
+func (c *GetCommandSupportCommand) Name() string           { return "Get Command Support" }
+func (c *GetCommandSupportCommand) Code() uint8            { return 0x0a }
+func (c *GetCommandSupportCommand) Retryable() bool        { return true }
+func (c *GetCommandSupportCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *GetCommandSupportCommand) String() string         { return cmdToJSON(c) }
+
+func (c *GetCommandSupportCommand) Marshal() ([]byte, error) {
+	return c.Target.marshal(c.UpperHalf)
+}
+
+func (c *GetCommandSupportCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, commandMaskHalfSize); err != nil {
+		return nil, err
+	}
+	// 0b means the command is supported
+	c.Supported.unmarshalHalf(c.UpperHalf, buf, true)
+	return buf[commandMaskHalfSize:], nil
+}
+
+// Get Configurable Commands Command (Section 21.5)
+type GetConfigurableCommandsCommand struct {
+	// Request Data
+	Target    FirewallTarget
+	UpperHalf bool // Commands 0x80 - 0xff instead of 0x00 - 0x7f
+
+	// Response Data
+	Configurable CommandMask // Configurable commands in the requested half
+}
+
+func (c *GetConfigurableCommandsCommand) Name() string    { return "Get Configurable Commands" }
+func (c *GetConfigurableCommandsCommand) Code() uint8     { return 0x0c }
+func (c *GetConfigurableCommandsCommand) Retryable() bool { return true }
+
+func (c *GetConfigurableCommandsCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnAppReq, 0)
+}
+
+func (c *GetConfigurableCommandsCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetConfigurableCommandsCommand) Marshal() ([]byte, error) {
+	return c.Target.marshal(c.UpperHalf)
+}
+
+func (c *GetConfigurableCommandsCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, commandMaskHalfSize); err != nil {
+		return nil, err
+	}
+	c.Configurable.unmarshalHalf(c.UpperHalf, buf, false)
+	return buf[commandMaskHalfSize:], nil
+}
+
+// Set Command Enables Command (Section 21.7)
+type SetCommandEnablesCommand struct {
+	// Request Data
+	Target    FirewallTarget
+	UpperHalf bool        // Commands 0x80 - 0xff instead of 0x00 - 0x7f
+	Enabled   CommandMask // Enabled commands, only the requested half is sent
+}
+
+func (c *SetCommandEnablesCommand) Name() string           { return "Set Command Enables" }
+func (c *SetCommandEnablesCommand) Code() uint8            { return 0x60 }
+func (c *SetCommandEnablesCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *SetCommandEnablesCommand) String() string         { return cmdToJSON(c) }
+
+func (c *SetCommandEnablesCommand) Marshal() ([]byte, error) {
+	buf, err := c.Target.marshal(c.UpperHalf)
+	if err != nil {
+		return nil, err
+	}
+
+	// The mask is placed between the LUN and the defining body code / IANA
+	req := append([]byte{}, buf[:3]...)
+	req = append(req, c.Enabled.marshalHalf(c.UpperHalf)...)
+	return append(req, buf[3:]...), nil
+}
+
+func (c *SetCommandEnablesCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get Command Enables Command (Section 21.8)
+type GetCommandEnablesCommand struct {
+	// Request Data
+	Target    FirewallTarget
+	UpperHalf bool // Commands 0x80 - 0xff instead of 0x00 - 0x7f
+
+	// Response Data
+	Enabled CommandMask // Enabled commands in the requested half
+}
+
+func (c *GetCommandEnablesCommand) Name() string           { return "Get Command Enables" }
+func (c *GetCommandEnablesCommand) Code() uint8            { return 0x61 }
+func (c *GetCommandEnablesCommand) Retryable() bool        { return true }
+func (c *GetCommandEnablesCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *GetCommandEnablesCommand) String() string         { return cmdToJSON(c) }
+
+func (c *GetCommandEnablesCommand) Marshal() ([]byte, error) {
+	return c.Target.marshal(c.UpperHalf)
+}
+
+func (c *GetCommandEnablesCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, commandMaskHalfSize); err != nil {
+		return nil, err
+	}
+	c.Enabled.unmarshalHalf(c.UpperHalf, buf, false)
+	return buf[commandMaskHalfSize:], nil
+}
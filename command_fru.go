@@ -0,0 +1,103 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+)
+
+// Get FRU Inventory Area Info Command (Section 34.1)
+type GetFRUInventoryAreaInfoCommand struct {
+	// Request Data
+	DeviceID uint8
+
+	// Response Data
+	AreaSize     uint16 // FRU inventory area size in bytes
+	AccessByWord bool   // Device is accessed by words (false: by bytes)
+}
+
+func (c *GetFRUInventoryAreaInfoCommand) Name() string    { return "Get FRU Inventory Area Info" }
+func (c *GetFRUInventoryAreaInfoCommand) Code() uint8     { return 0x10 }
+func (c *GetFRUInventoryAreaInfoCommand) Retryable() bool { return true }
+
+func (c *GetFRUInventoryAreaInfoCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnStorageReq, 0)
+}
+
+func (c *GetFRUInventoryAreaInfoCommand) String() string           { return cmdToJSON(c) }
+func (c *GetFRUInventoryAreaInfoCommand) Marshal() ([]byte, error) { return []byte{c.DeviceID}, nil }
+
+func (c *GetFRUInventoryAreaInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 3); err != nil {
+		return nil, err
+	}
+	c.AreaSize = binary.LittleEndian.Uint16(buf[0:2])
+	c.AccessByWord = buf[2]&0x01 != 0
+	return buf[3:], nil
+}
+
+// Read FRU Data Command (Section 34.2)
+type ReadFRUDataCommand struct {
+	// Request Data
+	DeviceID  uint8
+	Offset    uint16 // Offset in bytes or words
+	ReadCount uint8  // Count to read in bytes or words
+
+	// Response Data
+	Count uint8 // Count returned in bytes or words
+	Data  []byte
+}
+
+func (c *ReadFRUDataCommand) Name() string    { return "Read FRU Data" }
+func (c *ReadFRUDataCommand) Code() uint8     { return 0x11 }
+func (c *ReadFRUDataCommand) Retryable() bool { return true }
+
+func (c *ReadFRUDataCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnStorageReq, 0)
+}
+
+func (c *ReadFRUDataCommand) String() string { return cmdToJSON(c) }
+
+func (c *ReadFRUDataCommand) Marshal() ([]byte, error) {
+	return []byte{c.DeviceID, byte(c.Offset), byte(c.Offset >> 8), c.ReadCount}, nil
+}
+
+func (c *ReadFRUDataCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Count = buf[0]
+	c.Data = make([]byte, len(buf)-1)
+	copy(c.Data, buf[1:])
+	return nil, nil
+}
+
+// Write FRU Data Command (Section 34.3)
+type WriteFRUDataCommand struct {
+	// Request Data
+	DeviceID uint8
+	Offset   uint16 // Offset in bytes or words
+	Data     []byte
+
+	// Response Data
+	Count uint8 // Count written in bytes or words
+}
+
+func (c *WriteFRUDataCommand) Name() string { return "Write FRU Data" }
+func (c *WriteFRUDataCommand) Code() uint8  { return 0x12 }
+
+func (c *WriteFRUDataCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnStorageReq, 0)
+}
+
+func (c *WriteFRUDataCommand) String() string { return cmdToJSON(c) }
+
+func (c *WriteFRUDataCommand) Marshal() ([]byte, error) {
+	return append([]byte{c.DeviceID, byte(c.Offset), byte(c.Offset >> 8)}, c.Data...), nil
+}
+
+func (c *WriteFRUDataCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Count = buf[0]
+	return buf[1:], nil
+}
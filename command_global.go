@@ -1,25 +1,38 @@
 package ipmigo
 
+import (
+	"encoding/binary"
+	"fmt"
+)
+
 // Get Device ID Command (Section 20.1)
 type GetDeviceIDCommand struct {
 	// Response Data
-	DeviceID              uint8
-	DeviceRevision        uint8
-	DeviceProvidesSDRs    bool
-	DeviceAvailable       bool
-	FirmwareMajorRevision uint8
-	FirmwareMinorRevision uint8
-	IPMIVersion           uint8
-	SupportDeviceSensor   bool
-	SupportDeviceSDRRepo  bool
-	SupportDeviceSEL      bool
-	SupportDeviceFRU      bool
-	SupportDeviceChassis  bool
-	// Other fields are omitted because it is not used
+	DeviceID                  uint8
+	DeviceRevision            uint8
+	DeviceProvidesSDRs        bool
+	DeviceAvailable           bool
+	FirmwareMajorRevision     uint8
+	FirmwareMinorRevision     uint8 // Decoded from BCD
+	IPMIVersion               uint8 // (e.g. 0x51: IPMI v1.5, 0x02: IPMI v2.0)
+	IPMIMajorVersion          uint8
+	IPMIMinorVersion          uint8
+	SupportDeviceSensor       bool
+	SupportDeviceSDRRepo      bool
+	SupportDeviceSEL          bool
+	SupportDeviceFRU          bool
+	SupportIPMBEventReceiver  bool
+	SupportIPMBEventGenerator bool
+	SupportBridge             bool
+	SupportDeviceChassis      bool
+	ManufacturerID            uint32 // IANA enterprise number
+	ProductID                 uint16
+	AuxiliaryFirmwareRevision []byte // Optional 4 bytes, the format is vendor specific (nil if absent)
 }
 
 func (c *GetDeviceIDCommand) Name() string             { return "Get Device ID" }
 func (c *GetDeviceIDCommand) Code() uint8              { return 0x01 }
+func (c *GetDeviceIDCommand) Retryable() bool          { return true }
 func (c *GetDeviceIDCommand) NetFnRsLUN() NetFnRsLUN   { return NewNetFnRsLUN(NetFnAppReq, 0) }
 func (c *GetDeviceIDCommand) String() string           { return cmdToJSON(c) }
 func (c *GetDeviceIDCommand) Marshal() ([]byte, error) { return []byte{}, nil }
@@ -33,18 +46,35 @@ func (c *GetDeviceIDCommand) Unmarshal(buf []byte) ([]byte, error) {
 	c.DeviceProvidesSDRs = buf[1]&0x80 != 0
 	c.DeviceAvailable = buf[2]&0x80 == 0
 	c.FirmwareMajorRevision = buf[2] & 0x7f
-	c.FirmwareMinorRevision = buf[3]
+	c.FirmwareMinorRevision = (buf[3]>>4)*10 + buf[3]&0x0f
 	c.IPMIVersion = buf[4]
+	c.IPMIMajorVersion = buf[4] & 0x0f
+	c.IPMIMinorVersion = buf[4] >> 4
 	c.SupportDeviceSensor = buf[5]&0x01 != 0
 	c.SupportDeviceSDRRepo = buf[5]&0x02 != 0
 	c.SupportDeviceSEL = buf[5]&0x04 != 0
 	c.SupportDeviceFRU = buf[5]&0x08 != 0
+	c.SupportIPMBEventReceiver = buf[5]&0x10 != 0
+	c.SupportIPMBEventGenerator = buf[5]&0x20 != 0
+	c.SupportBridge = buf[5]&0x40 != 0
 	c.SupportDeviceChassis = buf[5]&0x80 != 0
+	c.ManufacturerID = uint32(buf[6]) | uint32(buf[7])<<8 | uint32(buf[8]&0x0f)<<16
+	c.ProductID = binary.LittleEndian.Uint16(buf[9:11])
 
-	if l := len(buf); l < 15 {
+	if len(buf) < 15 {
+		c.AuxiliaryFirmwareRevision = nil
 		return buf[11:], nil
-	} else {
-		return buf[15:], nil
 	}
-	return nil, nil
+	c.AuxiliaryFirmwareRevision = buf[11:15]
+	return buf[15:], nil
+}
+
+// Returns the firmware revision as "major.minor" (e.g. "1.02")
+func (c *GetDeviceIDCommand) FirmwareRevision() string {
+	return fmt.Sprintf("%d.%02d", c.FirmwareMajorRevision, c.FirmwareMinorRevision)
+}
+
+// Returns the IPMI version as "major.minor" (e.g. "2.0")
+func (c *GetDeviceIDCommand) IPMIVersionString() string {
+	return fmt.Sprintf("%d.%d", c.IPMIMajorVersion, c.IPMIMinorVersion)
 }
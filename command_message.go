@@ -7,6 +7,200 @@ import (
 	"net"
 )
 
+// Message Flags (Section 22.3, 22.4)
+type MessageFlags uint8
+
+const (
+	MessageFlagReceiveMessage     MessageFlags = 0x01 // Receive message available / Clear receive message queue
+	MessageFlagEventMessageBuffer MessageFlags = 0x02 // Event message buffer full / Clear event message buffer
+	MessageFlagWatchdogPreTimeout MessageFlags = 0x08 // Watchdog pre-timeout interrupt
+	MessageFlagOEM0               MessageFlags = 0x20
+	MessageFlagOEM1               MessageFlags = 0x40
+	MessageFlagOEM2               MessageFlags = 0x80
+)
+
+// Clear Message Flags Command (Section 22.3)
+type ClearMessageFlagsCommand struct {
+	// Request Data
+	Flags MessageFlags // Flags to clear
+}
+
+func (c *ClearMessageFlagsCommand) Name() string           { return "Clear Message Flags" }
+func (c *ClearMessageFlagsCommand) Code() uint8            { return 0x30 }
+func (c *ClearMessageFlagsCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *ClearMessageFlagsCommand) String() string         { return cmdToJSON(c) }
+
+func (c *ClearMessageFlagsCommand) Marshal() ([]byte, error) {
+	return []byte{byte(c.Flags) & 0xeb}, nil
+}
+
+func (c *ClearMessageFlagsCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get Message Flags Command (Section 22.4)
+type GetMessageFlagsCommand struct {
+	// Response Data
+	Flags MessageFlags
+}
+
+func (c *GetMessageFlagsCommand) Name() string             { return "Get Message Flags" }
+func (c *GetMessageFlagsCommand) Code() uint8              { return 0x31 }
+func (c *GetMessageFlagsCommand) Retryable() bool          { return true }
+func (c *GetMessageFlagsCommand) NetFnRsLUN() NetFnRsLUN   { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *GetMessageFlagsCommand) String() string           { return cmdToJSON(c) }
+func (c *GetMessageFlagsCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetMessageFlagsCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Flags = MessageFlags(buf[0])
+	return buf[1:], nil
+}
+
+// Returns `true` if a message is available in the receive message queue
+func (c *GetMessageFlagsCommand) ReceiveMessageAvailable() bool {
+	return c.Flags&MessageFlagReceiveMessage != 0
+}
+
+// Returns `true` if the event message buffer is full
+func (c *GetMessageFlagsCommand) EventMessageBufferFull() bool {
+	return c.Flags&MessageFlagEventMessageBuffer != 0
+}
+
+// Get Message Command (Section 22.6)
+type GetMessageCommand struct {
+	// Response Data
+	Channel        uint8          // Channel number that the message was received on
+	PrivilegeLevel PrivilegeLevel // Inferred privilege level of the message (0: Unspecified)
+	Data           []byte         // Message data (Table 22-6)
+}
+
+func (c *GetMessageCommand) Name() string             { return "Get Message" }
+func (c *GetMessageCommand) Code() uint8              { return 0x33 }
+func (c *GetMessageCommand) NetFnRsLUN() NetFnRsLUN   { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *GetMessageCommand) String() string           { return cmdToJSON(c) }
+func (c *GetMessageCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetMessageCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Channel = buf[0] & 0x0f
+	c.PrivilegeLevel = PrivilegeLevel(buf[0] >> 4)
+	c.Data = buf[1:]
+	return nil, nil
+}
+
+// Send Message Command (Section 22.7)
+type SendMessageCommand struct {
+	// Request Data
+	Channel  uint8   // Channel number to send the message
+	Tracking bool    // Request tracking
+	RsAddr   uint8   // Responder's slave address of the encapsulated message
+	RqAddr   uint8   // Requester's slave address of the encapsulated message
+	Command  Command // Encapsulated command
+
+	rqSeq uint8
+}
+
+func (c *SendMessageCommand) Name() string           { return "Send Message" }
+func (c *SendMessageCommand) Code() uint8            { return 0x34 }
+func (c *SendMessageCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *SendMessageCommand) String() string         { return cmdToJSON(c) }
+func (c *SendMessageCommand) Retryable() bool        { return cmdRetryable(c.Command) }
+
+func (c *SendMessageCommand) Marshal() ([]byte, error) {
+	msg := &ipmiRequestMessage{
+		RsAddr:  c.RsAddr,
+		RqAddr:  c.RqAddr,
+		RqSeq:   c.rqSeq,
+		Command: c.Command,
+	}
+	buf, err := msg.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	n := c.Channel & 0x0f
+	if c.Tracking {
+		n |= 0x40
+	}
+	return append([]byte{n}, buf...), nil
+}
+
+func (c *SendMessageCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		// No response data when the message is tracked
+		return nil, nil
+	}
+
+	// Response of the encapsulated message
+	rsm := &ipmiResponseMessage{}
+	if _, err := rsm.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	if rsm.Code != c.Command.Code() {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Mismatch command code in %s Response : 0x%02x - 0x%02x",
+				c.Name(), c.Command.Code(), rsm.Code),
+			Detail: hex.EncodeToString(buf),
+		}
+	}
+	if rsm.CompletionCode != CompletionOK {
+		return nil, &CommandError{
+			CompletionCode: rsm.CompletionCode,
+			Command:        c.Command,
+			Response:       append([]byte(nil), rsm.Data...),
+		}
+	}
+	return cmdUnmarshal(c.Command, rsm.Data)
+}
+
+// Master Write-Read Command (Section 22.11)
+type MasterWriteReadCommand struct {
+	// Request Data
+	Channel      uint8  // Channel number (0x00: Primary IPMB)
+	BusID        uint8  // Bus ID (0 - 7)
+	PrivateBus   bool   // Private bus instead of the public bus
+	SlaveAddress uint8  // 8-bit slave address of the device
+	ReadCount    uint8  // Number of bytes to read (0 for a write only)
+	WriteData    []byte // Data to write
+
+	// Response Data
+	ReadData []byte
+}
+
+func (c *MasterWriteReadCommand) Name() string           { return "Master Write-Read" }
+func (c *MasterWriteReadCommand) Code() uint8            { return 0x52 }
+func (c *MasterWriteReadCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *MasterWriteReadCommand) String() string         { return cmdToJSON(c) }
+
+func (c *MasterWriteReadCommand) Marshal() ([]byte, error) {
+	if c.BusID > 0x07 {
+		return nil, &ArgumentError{
+			Value:   c.BusID,
+			Message: "Bus ID must be in the range 0 to 7",
+		}
+	}
+
+	n := c.Channel<<4 | c.BusID<<1
+	if c.PrivateBus {
+		n |= 0x01
+	}
+	buf := []byte{n, c.SlaveAddress &^ 0x01, c.ReadCount}
+	return append(buf, c.WriteData...), nil
+}
+
+func (c *MasterWriteReadCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, int(c.ReadCount)); err != nil {
+		return nil, err
+	}
+	c.ReadData = buf[:c.ReadCount]
+	return buf[c.ReadCount:], nil
+}
+
 // Get Channel Authentication Capabilities Command (Section 22.13)
 type channelAuthCapCommand struct {
 	// Request Data
@@ -39,8 +233,8 @@ func (c *channelAuthCapCommand) Unmarshal(buf []byte) ([]byte, error) {
 	return buf[8:], nil
 }
 
-func (c *channelAuthCapCommand) IsSupportedAuthType(t authType) bool {
-	if t == authTypeRMCPPlus {
+func (c *channelAuthCapCommand) IsSupportedAuthType(t AuthType) bool {
+	if t == AuthTypeRMCPPlus {
 		return (c.AuthTypeSupport & 0x80) != 0
 	} else {
 		return c.AuthTypeSupport&(1<<t) != 0
@@ -59,25 +253,170 @@ func newChannelAuthCapCommand(v Version, l PrivilegeLevel) *channelAuthCapComman
 	}
 }
 
-// Set Session Privilege Level Command(Section 22.18)
-type setSessionPrivilegeCommand struct {
+// Get Channel Cipher Suites Command (Section 22.15)
+type GetChannelCipherSuitesCommand struct {
+	// Request Data
+	ReqChannelNumber uint8 // (0x0e: Retrieve information for channel this request was issued on)
+	PayloadType      uint8 // (0x00: IPMI)
+	ListIndex        uint8 // Index of 16-byte block of the cipher suite records (0x00 - 0x3f)
+
+	// Response Data
+	ResChannelNumber uint8
+	RecordData       []byte // Cipher suite records (Table 22-18)
+}
+
+func (c *GetChannelCipherSuitesCommand) Name() string    { return "Get Channel Cipher Suites" }
+func (c *GetChannelCipherSuitesCommand) Code() uint8     { return 0x54 }
+func (c *GetChannelCipherSuitesCommand) Retryable() bool { return true }
+
+func (c *GetChannelCipherSuitesCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnAppReq, 0)
+}
+
+func (c *GetChannelCipherSuitesCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetChannelCipherSuitesCommand) Marshal() ([]byte, error) {
+	// List algorithms by cipher suite
+	return []byte{c.ReqChannelNumber, c.PayloadType, 0x80 | (c.ListIndex & 0x3f)}, nil
+}
+
+func (c *GetChannelCipherSuitesCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.ResChannelNumber = buf[0]
+	c.RecordData = make([]byte, len(buf)-1)
+	copy(c.RecordData, buf[1:])
+	return nil, nil
+}
+
+// Returns IDs of the standard cipher suites which are supported by the channel
+func getCipherSuiteIDs(execute func(Command) error) ([]uint, error) {
+	var data []byte
+	for i := uint8(0); i <= 0x3f; i++ {
+		c := &GetChannelCipherSuitesCommand{
+			ReqChannelNumber: 0x0e,
+			ListIndex:        i,
+		}
+		if err := execute(c); err != nil {
+			return nil, err
+		}
+		data = append(data, c.RecordData...)
+
+		// The last block has less than 16 bytes
+		if len(c.RecordData) < 16 {
+			break
+		}
+	}
+
+	var ids []uint
+	for i := 0; i < len(data); {
+		switch data[i] {
+		case 0xc0: // Standard Cipher Suite
+			if i+1 < len(data) {
+				ids = append(ids, uint(data[i+1]))
+			}
+			i += 2
+		case 0xc1: // OEM Cipher Suite
+			i += 5
+		default: // Algorithm number
+			i++
+		}
+	}
+	return ids, nil
+}
+
+// Get Session Challenge Command (Section 22.16)
+type getSessionChallengeCommand struct {
+	// Request Data
+	AuthType AuthType
+	Username string
+
+	// Response Data
+	TemporaryID uint32
+	Challenge   [16]byte
+}
+
+func (c *getSessionChallengeCommand) Name() string           { return "Get Session Challenge" }
+func (c *getSessionChallengeCommand) Code() uint8            { return 0x39 }
+func (c *getSessionChallengeCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *getSessionChallengeCommand) String() string         { return cmdToJSON(c) }
+
+func (c *getSessionChallengeCommand) Marshal() ([]byte, error) {
+	buf := make([]byte, 1+userNameMaxLength)
+	buf[0] = byte(c.AuthType)
+	copy(buf[1:], c.Username)
+	return buf, nil
+}
+
+func (c *getSessionChallengeCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 20); err != nil {
+		return nil, err
+	}
+	c.TemporaryID = binary.LittleEndian.Uint32(buf)
+	copy(c.Challenge[:], buf[4:20])
+	return buf[20:], nil
+}
+
+// Activate Session Command (Section 22.17)
+type activateSessionCommand struct {
+	// Request Data
+	AuthType         AuthType
+	PrivilegeLevel   PrivilegeLevel
+	Challenge        [16]byte
+	OutboundSequence uint32 // Initial sequence number of the messages from BMC
+
+	// Response Data
+	ResAuthType       AuthType
+	SessionID         uint32
+	InboundSequence   uint32 // Initial sequence number of the messages to BMC
+	MaxPrivilegeLevel PrivilegeLevel
+}
+
+func (c *activateSessionCommand) Name() string           { return "Activate Session" }
+func (c *activateSessionCommand) Code() uint8            { return 0x3a }
+func (c *activateSessionCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *activateSessionCommand) String() string         { return cmdToJSON(c) }
+
+func (c *activateSessionCommand) Marshal() ([]byte, error) {
+	buf := make([]byte, 22)
+	buf[0] = byte(c.AuthType)
+	buf[1] = byte(c.PrivilegeLevel)
+	copy(buf[2:], c.Challenge[:])
+	binary.LittleEndian.PutUint32(buf[18:], c.OutboundSequence)
+	return buf, nil
+}
+
+func (c *activateSessionCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 10); err != nil {
+		return nil, err
+	}
+	c.ResAuthType = AuthType(buf[0] & 0x0f)
+	c.SessionID = binary.LittleEndian.Uint32(buf[1:])
+	c.InboundSequence = binary.LittleEndian.Uint32(buf[5:])
+	c.MaxPrivilegeLevel = PrivilegeLevel(buf[9] & 0x0f)
+	return buf[10:], nil
+}
+
+// Set Session Privilege Level Command (Section 22.18)
+type SetSessionPrivilegeCommand struct {
 	// Request Data
-	RequestedLevel PrivilegeLevel
+	RequestedLevel PrivilegeLevel // (0: Present level)
 
 	// Response Data
 	NewLevel PrivilegeLevel
 }
 
-func (c *setSessionPrivilegeCommand) Name() string           { return "Set Session Privilege Level" }
-func (c *setSessionPrivilegeCommand) Code() uint8            { return 0x3b }
-func (c *setSessionPrivilegeCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
-func (c *setSessionPrivilegeCommand) String() string         { return cmdToJSON(c) }
+func (c *SetSessionPrivilegeCommand) Name() string           { return "Set Session Privilege Level" }
+func (c *SetSessionPrivilegeCommand) Code() uint8            { return 0x3b }
+func (c *SetSessionPrivilegeCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *SetSessionPrivilegeCommand) String() string         { return cmdToJSON(c) }
 
-func (c *setSessionPrivilegeCommand) Marshal() ([]byte, error) {
+func (c *SetSessionPrivilegeCommand) Marshal() ([]byte, error) {
 	return []byte{byte(c.RequestedLevel)}, nil
 }
 
-func (c *setSessionPrivilegeCommand) Unmarshal(buf []byte) ([]byte, error) {
+func (c *SetSessionPrivilegeCommand) Unmarshal(buf []byte) ([]byte, error) {
 	if err := cmdValidateLength(c, buf, 1); err != nil {
 		return nil, err
 	}
@@ -85,8 +424,8 @@ func (c *setSessionPrivilegeCommand) Unmarshal(buf []byte) ([]byte, error) {
 	return buf[1:], nil
 }
 
-func newSetSessionPrivilegeCommand(l PrivilegeLevel) *setSessionPrivilegeCommand {
-	return &setSessionPrivilegeCommand{RequestedLevel: l}
+func newSetSessionPrivilegeCommand(l PrivilegeLevel) *SetSessionPrivilegeCommand {
+	return &SetSessionPrivilegeCommand{RequestedLevel: l}
 }
 
 // Close Session Command (Section 22.19)
@@ -132,8 +471,9 @@ type GetSessionInfoCommand struct {
 	ConsolePort        uint16
 }
 
-func (c *GetSessionInfoCommand) Name() string { return "Get Session Info" }
-func (c *GetSessionInfoCommand) Code() uint8  { return 0x3d }
+func (c *GetSessionInfoCommand) Name() string    { return "Get Session Info" }
+func (c *GetSessionInfoCommand) Code() uint8     { return 0x3d }
+func (c *GetSessionInfoCommand) Retryable() bool { return true }
 
 func (c *GetSessionInfoCommand) NetFnRsLUN() NetFnRsLUN {
 	return NewNetFnRsLUN(NetFnAppReq, 0)
@@ -184,3 +524,39 @@ func (c *GetSessionInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
 
 	return buf[18:], nil
 }
+
+// Get AuthCode Command (Section 22.21)
+type GetAuthCodeCommand struct {
+	// Request Data
+	AuthType  AuthType           // IPMI v1.5 authentication type (Ignored if Integrity is set)
+	Integrity IntegrityAlgorithm // IPMI v2.0 integrity algorithm
+	Channel   uint8              // Channel number (0x0e: Channel this request was issued on)
+	UserID    uint8
+	Data      [16]byte // Data to hash
+
+	// Response Data
+	AuthCode []byte // 16 bytes for IPMI v1.5, or the length of the integrity algorithm
+}
+
+func (c *GetAuthCodeCommand) Name() string           { return "Get AuthCode" }
+func (c *GetAuthCodeCommand) Code() uint8            { return 0x3f }
+func (c *GetAuthCodeCommand) Retryable() bool        { return true }
+func (c *GetAuthCodeCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *GetAuthCodeCommand) String() string         { return cmdToJSON(c) }
+
+func (c *GetAuthCodeCommand) Marshal() ([]byte, error) {
+	t := byte(c.AuthType) & 0x0f
+	if c.Integrity != IntegrityNone {
+		t = 0x80 | byte(c.Integrity)&0x3f
+	}
+	buf := []byte{t, c.Channel & 0x0f, c.UserID & 0x3f}
+	return append(buf, c.Data[:]...), nil
+}
+
+func (c *GetAuthCodeCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.AuthCode = buf
+	return nil, nil
+}
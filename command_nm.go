@@ -0,0 +1,174 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// Set NM Policy Command (Intel Node Manager)
+type SetNMPolicyCommand struct {
+	OEMCommandBase // IANA is Intel if not specified
+
+	// Request Data
+	Policy NMPolicy
+	Remove bool // Removes the policy instead of adding or modifying
+}
+
+func (c *SetNMPolicyCommand) Name() string           { return "Set NM Policy" }
+func (c *SetNMPolicyCommand) Code() uint8            { return 0xc1 }
+func (c *SetNMPolicyCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnOEMGroupReq, 0) }
+func (c *SetNMPolicyCommand) String() string         { return cmdToJSON(c) }
+
+func (c *SetNMPolicyCommand) Marshal() ([]byte, error) {
+	domain := c.Policy.DomainID & 0x0f
+	if c.Policy.Enabled {
+		domain |= 0x10
+	}
+	c.defaultIANA(nmIANA)
+	return c.MarshalIANA(append([]byte{domain, c.Policy.PolicyID}, c.Policy.marshal(!c.Remove)...)...), nil
+}
+
+func (c *SetNMPolicyCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return c.UnmarshalIANA(c, buf, 0)
+}
+
+// Get NM Policy Command (Intel Node Manager)
+type GetNMPolicyCommand struct {
+	OEMCommandBase // IANA is Intel if not specified
+
+	// Request Data
+	DomainID uint8
+	PolicyID uint8
+
+	// Response Data
+	Policy            NMPolicy
+	DomainEnabled     bool // Per-domain policy control is enabled
+	GlobalEnabled     bool // Global policy control is enabled
+	ExternallyCreated bool // Policy is created by the other management client
+}
+
+func (c *GetNMPolicyCommand) Name() string           { return "Get NM Policy" }
+func (c *GetNMPolicyCommand) Code() uint8            { return 0xc2 }
+func (c *GetNMPolicyCommand) Retryable() bool        { return true }
+func (c *GetNMPolicyCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnOEMGroupReq, 0) }
+func (c *GetNMPolicyCommand) String() string         { return cmdToJSON(c) }
+
+func (c *GetNMPolicyCommand) Marshal() ([]byte, error) {
+	c.defaultIANA(nmIANA)
+	return c.MarshalIANA(c.DomainID&0x0f, c.PolicyID), nil
+}
+
+func (c *GetNMPolicyCommand) Unmarshal(buf []byte) ([]byte, error) {
+	buf, err := c.UnmarshalIANA(c, buf, 1+nmPolicySize)
+	if err != nil {
+		return nil, err
+	}
+	b := buf[0]
+	c.Policy.DomainID = b & 0x0f
+	c.Policy.PolicyID = c.PolicyID
+	c.Policy.Enabled = b&0x10 != 0
+	c.DomainEnabled = b&0x20 != 0
+	c.GlobalEnabled = b&0x40 != 0
+	c.ExternallyCreated = b&0x80 != 0
+	return c.Policy.unmarshal(buf[1:]), nil
+}
+
+// Get NM Statistics Command (Intel Node Manager)
+type GetNMStatisticsCommand struct {
+	OEMCommandBase // IANA is Intel if not specified
+
+	// Request Data
+	Mode     NMStatisticsMode
+	DomainID uint8
+	PolicyID uint8 // Used only in the per policy modes
+
+	// Response Data
+	Current          uint16 // Unit depends on the mode
+	Minimum          uint16
+	Maximum          uint16
+	Average          uint16
+	Timestamp        time.Time
+	StatisticsPeriod time.Duration
+	Enabled          bool // Policy/Global administrative state
+	Operational      bool // Policy/Global operational state
+	Measuring        bool // Measurements are in progress
+	Activated        bool // Policy is triggered and actively limiting
+}
+
+func (c *GetNMStatisticsCommand) Name() string           { return "Get NM Statistics" }
+func (c *GetNMStatisticsCommand) Code() uint8            { return 0xc8 }
+func (c *GetNMStatisticsCommand) Retryable() bool        { return true }
+func (c *GetNMStatisticsCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnOEMGroupReq, 0) }
+func (c *GetNMStatisticsCommand) String() string         { return cmdToJSON(c) }
+
+func (c *GetNMStatisticsCommand) Marshal() ([]byte, error) {
+	c.defaultIANA(nmIANA)
+	return c.MarshalIANA(uint8(c.Mode), c.DomainID&0x0f, c.PolicyID), nil
+}
+
+func (c *GetNMStatisticsCommand) Unmarshal(buf []byte) ([]byte, error) {
+	buf, err := c.UnmarshalIANA(c, buf, 17)
+	if err != nil {
+		return nil, err
+	}
+	c.Current = binary.LittleEndian.Uint16(buf[0:])
+	c.Minimum = binary.LittleEndian.Uint16(buf[2:])
+	c.Maximum = binary.LittleEndian.Uint16(buf[4:])
+	c.Average = binary.LittleEndian.Uint16(buf[6:])
+	c.Timestamp = time.Unix(int64(binary.LittleEndian.Uint32(buf[8:])), 0)
+	c.StatisticsPeriod = time.Duration(binary.LittleEndian.Uint32(buf[12:])) * time.Second
+	c.DomainID = buf[16] & 0x0f
+	c.Enabled = buf[16]&0x10 != 0
+	c.Operational = buf[16]&0x20 != 0
+	c.Measuring = buf[16]&0x40 != 0
+	c.Activated = buf[16]&0x80 != 0
+	return buf[17:], nil
+}
+
+// Get NM Capabilities Command (Intel Node Manager)
+type GetNMCapabilitiesCommand struct {
+	OEMCommandBase // IANA is Intel if not specified
+
+	// Request Data
+	DomainID uint8
+	Trigger  NMTriggerType
+
+	// Response Data
+	MaxPolicies          uint8  // Max number of the policies of the domain and the trigger type
+	MaxLimit             uint16 // Max power/thermal/time after reset limit
+	MinLimit             uint16 // Min power/thermal/time after reset limit
+	MinCorrectionTime    time.Duration
+	MaxCorrectionTime    time.Duration
+	MinStatisticsPeriod  time.Duration
+	MaxStatisticsPeriod  time.Duration
+	SecondaryPowerDomain bool // Limiting is applied to the secondary power domain (false: primary)
+}
+
+func (c *GetNMCapabilitiesCommand) Name() string           { return "Get NM Capabilities" }
+func (c *GetNMCapabilitiesCommand) Code() uint8            { return 0xc9 }
+func (c *GetNMCapabilitiesCommand) Retryable() bool        { return true }
+func (c *GetNMCapabilitiesCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnOEMGroupReq, 0) }
+func (c *GetNMCapabilitiesCommand) String() string         { return cmdToJSON(c) }
+
+func (c *GetNMCapabilitiesCommand) Marshal() ([]byte, error) {
+	// Policy type is the power control policy
+	c.defaultIANA(nmIANA)
+	return c.MarshalIANA(c.DomainID&0x0f, uint8(c.Trigger)&0x0f, 0x10), nil
+}
+
+func (c *GetNMCapabilitiesCommand) Unmarshal(buf []byte) ([]byte, error) {
+	buf, err := c.UnmarshalIANA(c, buf, 18)
+	if err != nil {
+		return nil, err
+	}
+	c.MaxPolicies = buf[0]
+	c.MaxLimit = binary.LittleEndian.Uint16(buf[1:])
+	c.MinLimit = binary.LittleEndian.Uint16(buf[3:])
+	c.MinCorrectionTime = time.Duration(binary.LittleEndian.Uint32(buf[5:])) * time.Millisecond
+	c.MaxCorrectionTime = time.Duration(binary.LittleEndian.Uint32(buf[9:])) * time.Millisecond
+	c.MinStatisticsPeriod = time.Duration(binary.LittleEndian.Uint16(buf[13:])) * time.Second
+	c.MaxStatisticsPeriod = time.Duration(binary.LittleEndian.Uint16(buf[15:])) * time.Second
+	c.DomainID = buf[17] & 0x0f
+	c.SecondaryPowerDomain = buf[17]&0x80 != 0
+	return buf[18:], nil
+}
@@ -0,0 +1,157 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+)
+
+// Get Payload Activation Status Command (Section 24.4)
+type GetPayloadActivationStatusCommand struct {
+	// Request Data
+	PayloadType uint8 // (0x01: SOL, 0x02: OEM Explicit, 0x20-0x27: OEM)
+
+	// Response Data
+	InstanceCapacity uint8  // Number of instances which can be activated simultaneously
+	ActiveInstances  uint16 // Bitmap of the active instances (bit 0: instance 1, ... bit 15: instance 16)
+}
+
+func (c *GetPayloadActivationStatusCommand) Name() string    { return "Get Payload Activation Status" }
+func (c *GetPayloadActivationStatusCommand) Code() uint8     { return 0x4a }
+func (c *GetPayloadActivationStatusCommand) Retryable() bool { return true }
+
+func (c *GetPayloadActivationStatusCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnAppReq, 0)
+}
+
+func (c *GetPayloadActivationStatusCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetPayloadActivationStatusCommand) Marshal() ([]byte, error) {
+	return []byte{c.PayloadType}, nil
+}
+
+func (c *GetPayloadActivationStatusCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 3); err != nil {
+		return nil, err
+	}
+	c.InstanceCapacity = buf[0] & 0x0f
+	c.ActiveInstances = binary.LittleEndian.Uint16(buf[1:])
+	return buf[3:], nil
+}
+
+// Returns true if the instance (1-based) is active
+func (c *GetPayloadActivationStatusCommand) IsActive(instance uint8) bool {
+	return instance >= 1 && instance <= 16 && c.ActiveInstances&(1<<(instance-1)) != 0
+}
+
+// Returns the number of instances which can be activated
+func (c *GetPayloadActivationStatusCommand) FreeInstances() int {
+	n := int(c.InstanceCapacity)
+	for i := uint8(1); i <= c.InstanceCapacity; i++ {
+		if c.IsActive(i) {
+			n--
+		}
+	}
+	return n
+}
+
+// Get Channel Payload Support Command (Section 24.8)
+type GetChannelPayloadSupportCommand struct {
+	// Request Data
+	ChannelNumber uint8 // (0x0e: Retrieve information for channel this request was issued on)
+
+	// Response Data
+	StandardPayloads     uint16 // Bitmap of the standard payload types (bit 0: IPMI, bit 1: SOL, bit 2: OEM Explicit)
+	SessionSetupPayloads uint16 // Bitmap of the session setup payload types (bit 0: Open Session Request, ...)
+	OEMPayloads          uint16 // Bitmap of the OEM payload types (bit 0: 0x20, ... bit 7: 0x27)
+}
+
+func (c *GetChannelPayloadSupportCommand) Name() string    { return "Get Channel Payload Support" }
+func (c *GetChannelPayloadSupportCommand) Code() uint8     { return 0x4e }
+func (c *GetChannelPayloadSupportCommand) Retryable() bool { return true }
+
+func (c *GetChannelPayloadSupportCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnAppReq, 0)
+}
+
+func (c *GetChannelPayloadSupportCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetChannelPayloadSupportCommand) Marshal() ([]byte, error) {
+	return []byte{c.ChannelNumber}, nil
+}
+
+func (c *GetChannelPayloadSupportCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 8); err != nil {
+		return nil, err
+	}
+	c.StandardPayloads = binary.LittleEndian.Uint16(buf)
+	c.SessionSetupPayloads = binary.LittleEndian.Uint16(buf[2:])
+	c.OEMPayloads = binary.LittleEndian.Uint16(buf[4:])
+	return buf[8:], nil
+}
+
+func (c *GetChannelPayloadSupportCommand) SupportedSOL() bool { return c.StandardPayloads&0x02 != 0 }
+func (c *GetChannelPayloadSupportCommand) SupportedOEM() bool { return c.StandardPayloads&0x04 != 0 }
+
+// Activate Payload Command (Section 24.1)
+type ActivatePayloadCommand struct {
+	// Request Data
+	PayloadType     uint8 // (0x01: SOL, 0x02: OEM Explicit, 0x20-0x27: OEM)
+	PayloadInstance uint8 // (1-based)
+	AuxData         [4]byte
+
+	// Response Data
+	ResponseAuxData [4]byte
+	InboundSize     uint16 // Maximum size of the payload sent to BMC
+	OutboundSize    uint16 // Maximum size of the payload sent from BMC
+	Port            uint16 // UDP port number of the payload
+	VLAN            uint16 // (0xffff: VLAN addressing is not used)
+}
+
+func (c *ActivatePayloadCommand) Name() string { return "Activate Payload" }
+func (c *ActivatePayloadCommand) Code() uint8  { return 0x48 }
+
+func (c *ActivatePayloadCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnAppReq, 0)
+}
+
+func (c *ActivatePayloadCommand) String() string { return cmdToJSON(c) }
+
+func (c *ActivatePayloadCommand) Marshal() ([]byte, error) {
+	return append([]byte{c.PayloadType & 0x3f, c.PayloadInstance & 0x0f}, c.AuxData[:]...), nil
+}
+
+func (c *ActivatePayloadCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 12); err != nil {
+		return nil, err
+	}
+	copy(c.ResponseAuxData[:], buf[0:4])
+	c.InboundSize = binary.LittleEndian.Uint16(buf[4:])
+	c.OutboundSize = binary.LittleEndian.Uint16(buf[6:])
+	c.Port = binary.LittleEndian.Uint16(buf[8:])
+	c.VLAN = binary.LittleEndian.Uint16(buf[10:])
+	return buf[12:], nil
+}
+
+// Deactivate Payload Command (Section 24.2)
+type DeactivatePayloadCommand struct {
+	// Request Data
+	PayloadType     uint8
+	PayloadInstance uint8
+	AuxData         [4]byte
+}
+
+func (c *DeactivatePayloadCommand) Name() string { return "Deactivate Payload" }
+func (c *DeactivatePayloadCommand) Code() uint8  { return 0x49 }
+
+func (c *DeactivatePayloadCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnAppReq, 0)
+}
+
+func (c *DeactivatePayloadCommand) String() string { return cmdToJSON(c) }
+
+func (c *DeactivatePayloadCommand) Marshal() ([]byte, error) {
+	return append([]byte{c.PayloadType & 0x3f, c.PayloadInstance & 0x0f}, c.AuxData[:]...), nil
+}
+
+func (c *DeactivatePayloadCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
@@ -0,0 +1,164 @@
+package ipmigo
+
+import (
+	"strings"
+)
+
+// PEF Actions (Table 30-3)
+type PEFAction uint8
+
+const (
+	PEFActionAlert PEFAction = 1 << iota
+	PEFActionPowerDown
+	PEFActionReset
+	PEFActionPowerCycle
+	PEFActionOEM
+	PEFActionDiagnosticInterrupt
+)
+
+func (a PEFAction) String() string {
+	names := []string{"Alert", "Power Down", "Reset", "Power Cycle", "OEM", "Diagnostic Interrupt"}
+
+	var s []string
+	for i, n := range names {
+		if a&(1<<uint(i)) != 0 {
+			s = append(s, n)
+		}
+	}
+	return strings.Join(s, ", ")
+}
+
+// Get PEF Capabilities Command (Section 30.1)
+type GetPEFCapabilitiesCommand struct {
+	// Response Data
+	MajorVersion       uint8
+	MinorVersion       uint8
+	OEMEventFilter     bool      // OEM event record filtering is supported
+	Actions            PEFAction // Supported actions
+	EventFilterEntries uint8     // Number of event filter table entries
+}
+
+func (c *GetPEFCapabilitiesCommand) Name() string    { return "Get PEF Capabilities" }
+func (c *GetPEFCapabilitiesCommand) Code() uint8     { return 0x10 }
+func (c *GetPEFCapabilitiesCommand) Retryable() bool { return true }
+
+func (c *GetPEFCapabilitiesCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, 0)
+}
+
+func (c *GetPEFCapabilitiesCommand) String() string           { return cmdToJSON(c) }
+func (c *GetPEFCapabilitiesCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetPEFCapabilitiesCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 3); err != nil {
+		return nil, err
+	}
+	c.MajorVersion = buf[0] & 0x0f
+	c.MinorVersion = buf[0] >> 4
+	c.OEMEventFilter = buf[1]&0x80 != 0
+	c.Actions = PEFAction(buf[1] & 0x3f)
+	c.EventFilterEntries = buf[2]
+	return buf[3:], nil
+}
+
+// Arm PEF Postpone Timer values (Section 30.2)
+const (
+	PEFPostponeDisable          uint8 = 0x00
+	PEFPostponeTemporaryDisable uint8 = 0xfe // Disable PEF until the timer is set again
+	PEFPostponeGetCountdown     uint8 = 0xff // Get the present countdown value
+)
+
+// Arm PEF Postpone Timer Command (Section 30.2)
+type ArmPEFPostponeTimerCommand struct {
+	// Request Data
+	Timeout uint8 // Postpone timeout in seconds (0x01-0xfd), or PEFPostpone* value
+
+	// Response Data
+	Countdown uint8 // Present timer countdown value
+}
+
+func (c *ArmPEFPostponeTimerCommand) Name() string { return "Arm PEF Postpone Timer" }
+func (c *ArmPEFPostponeTimerCommand) Code() uint8  { return 0x11 }
+
+func (c *ArmPEFPostponeTimerCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, 0)
+}
+
+func (c *ArmPEFPostponeTimerCommand) String() string           { return cmdToJSON(c) }
+func (c *ArmPEFPostponeTimerCommand) Marshal() ([]byte, error) { return []byte{c.Timeout}, nil }
+
+func (c *ArmPEFPostponeTimerCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Countdown = buf[0]
+	return buf[1:], nil
+}
+
+// Set PEF Configuration Parameters Command (Section 30.3)
+type SetPEFConfigParametersCommand struct {
+	// Request Data
+	Parameter PEFConfigParameter
+}
+
+func (c *SetPEFConfigParametersCommand) Name() string { return "Set PEF Configuration Parameters" }
+func (c *SetPEFConfigParametersCommand) Code() uint8  { return 0x12 }
+
+func (c *SetPEFConfigParametersCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, 0)
+}
+
+func (c *SetPEFConfigParametersCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetPEFConfigParametersCommand) Marshal() ([]byte, error) {
+	if c.Parameter == nil {
+		return nil, &ArgumentError{Value: c.Parameter, Message: "PEF configuration parameter is required"}
+	}
+	data, err := c.Parameter.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{c.Parameter.Selector() & 0x7f}, data...), nil
+}
+
+func (c *SetPEFConfigParametersCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get PEF Configuration Parameters Command (Section 30.4)
+type GetPEFConfigParametersCommand struct {
+	// Request Data
+	SetSelector   uint8
+	BlockSelector uint8
+
+	// Request and Response Data, the selector is taken from the parameter
+	Parameter PEFConfigParameter
+
+	// Response Data
+	ParameterRevision uint8
+}
+
+func (c *GetPEFConfigParametersCommand) Name() string    { return "Get PEF Configuration Parameters" }
+func (c *GetPEFConfigParametersCommand) Code() uint8     { return 0x13 }
+func (c *GetPEFConfigParametersCommand) Retryable() bool { return true }
+
+func (c *GetPEFConfigParametersCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, 0)
+}
+
+func (c *GetPEFConfigParametersCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetPEFConfigParametersCommand) Marshal() ([]byte, error) {
+	if c.Parameter == nil {
+		return nil, &ArgumentError{Value: c.Parameter, Message: "PEF configuration parameter is required"}
+	}
+	return []byte{c.Parameter.Selector() & 0x7f, c.SetSelector, c.BlockSelector}, nil
+}
+
+func (c *GetPEFConfigParametersCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.ParameterRevision = buf[0]
+	return c.Parameter.Unmarshal(buf[1:])
+}
@@ -12,8 +12,9 @@ type GetSDRRepositoryInfoCommand struct {
 	// Other fields are omitted because it is not used
 }
 
-func (c *GetSDRRepositoryInfoCommand) Name() string { return "Get SDR Repository Info" }
-func (c *GetSDRRepositoryInfoCommand) Code() uint8  { return 0x20 }
+func (c *GetSDRRepositoryInfoCommand) Name() string    { return "Get SDR Repository Info" }
+func (c *GetSDRRepositoryInfoCommand) Code() uint8     { return 0x20 }
+func (c *GetSDRRepositoryInfoCommand) Retryable() bool { return true }
 
 func (c *GetSDRRepositoryInfoCommand) NetFnRsLUN() NetFnRsLUN {
 	return NewNetFnRsLUN(NetFnStorageReq, 0)
@@ -70,6 +71,7 @@ type GetSDRCommand struct {
 
 func (c *GetSDRCommand) Name() string           { return "Get SDR" }
 func (c *GetSDRCommand) Code() uint8            { return 0x23 }
+func (c *GetSDRCommand) Retryable() bool        { return true }
 func (c *GetSDRCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnStorageReq, 0) }
 func (c *GetSDRCommand) String() string         { return cmdToJSON(c) }
 
@@ -95,3 +97,127 @@ func (c *GetSDRCommand) Unmarshal(buf []byte) ([]byte, error) {
 		return buf[c.ReadBytes:], nil
 	}
 }
+
+// Add SDR Command (Section 33.13)
+type AddSDRCommand struct {
+	// Request Data
+	RecordData []byte // Whole record including the header
+
+	// Response Data
+	RecordID uint16
+}
+
+func (c *AddSDRCommand) Name() string           { return "Add SDR" }
+func (c *AddSDRCommand) Code() uint8            { return 0x24 }
+func (c *AddSDRCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnStorageReq, 0) }
+func (c *AddSDRCommand) String() string         { return cmdToJSON(c) }
+
+func (c *AddSDRCommand) Marshal() ([]byte, error) {
+	return c.RecordData, nil
+}
+
+func (c *AddSDRCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.RecordID = binary.LittleEndian.Uint16(buf)
+	return buf[2:], nil
+}
+
+// Partial Add SDR Command (Section 33.14)
+type PartialAddSDRCommand struct {
+	// Request Data
+	ReservationID uint16
+	RecordID      uint16 // (0x0000: First part of the record)
+	RecordOffset  uint8
+	Last          bool // Last part of the record
+	RecordData    []byte
+
+	// Response Data
+	ResRecordID uint16 // Record ID to use for the following parts
+}
+
+func (c *PartialAddSDRCommand) Name() string           { return "Partial Add SDR" }
+func (c *PartialAddSDRCommand) Code() uint8            { return 0x25 }
+func (c *PartialAddSDRCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnStorageReq, 0) }
+func (c *PartialAddSDRCommand) String() string         { return cmdToJSON(c) }
+
+func (c *PartialAddSDRCommand) Marshal() ([]byte, error) {
+	var progress byte
+	if c.Last {
+		progress = 0x01
+	}
+	buf := []byte{byte(c.ReservationID), byte(c.ReservationID >> 8), byte(c.RecordID), byte(c.RecordID >> 8),
+		c.RecordOffset, progress}
+	return append(buf, c.RecordData...), nil
+}
+
+func (c *PartialAddSDRCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.ResRecordID = binary.LittleEndian.Uint16(buf)
+	return buf[2:], nil
+}
+
+// Delete SDR Command (Section 33.15)
+type DeleteSDRCommand struct {
+	// Request Data
+	ReservationID uint16
+	RecordID      uint16
+
+	// Response Data
+	DeletedRecordID uint16
+}
+
+func (c *DeleteSDRCommand) Name() string           { return "Delete SDR" }
+func (c *DeleteSDRCommand) Code() uint8            { return 0x26 }
+func (c *DeleteSDRCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnStorageReq, 0) }
+func (c *DeleteSDRCommand) String() string         { return cmdToJSON(c) }
+
+func (c *DeleteSDRCommand) Marshal() ([]byte, error) {
+	return []byte{byte(c.ReservationID), byte(c.ReservationID >> 8), byte(c.RecordID), byte(c.RecordID >> 8)}, nil
+}
+
+func (c *DeleteSDRCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.DeletedRecordID = binary.LittleEndian.Uint16(buf)
+	return buf[2:], nil
+}
+
+// Clear SDR Repository Command (Section 33.16)
+type ClearSDRRepositoryCommand struct {
+	// Request Data
+	ReservationID uint16
+	GetStatus     bool // Get the erasure status instead of initiating the erase
+
+	// Response Data
+	Completed bool // Erasure is completed
+}
+
+func (c *ClearSDRRepositoryCommand) Name() string { return "Clear SDR Repository" }
+func (c *ClearSDRRepositoryCommand) Code() uint8  { return 0x27 }
+
+func (c *ClearSDRRepositoryCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnStorageReq, 0)
+}
+
+func (c *ClearSDRRepositoryCommand) String() string { return cmdToJSON(c) }
+
+func (c *ClearSDRRepositoryCommand) Marshal() ([]byte, error) {
+	var op byte = 0xaa // Initiate erase
+	if c.GetStatus {
+		op = 0x00
+	}
+	return []byte{byte(c.ReservationID), byte(c.ReservationID >> 8), 'C', 'L', 'R', op}, nil
+}
+
+func (c *ClearSDRRepositoryCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Completed = buf[0]&0x0f == 0x01
+	return buf[1:], nil
+}
@@ -19,8 +19,9 @@ type GetSELInfoCommand struct {
 	Overflow          bool
 }
 
-func (c *GetSELInfoCommand) Name() string { return "Get SEL Info" }
-func (c *GetSELInfoCommand) Code() uint8  { return 0x40 }
+func (c *GetSELInfoCommand) Name() string    { return "Get SEL Info" }
+func (c *GetSELInfoCommand) Code() uint8     { return 0x40 }
+func (c *GetSELInfoCommand) Retryable() bool { return true }
 
 func (c *GetSELInfoCommand) NetFnRsLUN() NetFnRsLUN {
 	return NewNetFnRsLUN(NetFnStorageReq, 0)
@@ -48,6 +49,39 @@ func (c *GetSELInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
 	return buf[14:], nil
 }
 
+// Get SEL Allocation Info Command (Section 31.3)
+type GetSELAllocationInfoCommand struct {
+	// Response Data
+	AllocationUnits  uint16 // Number of possible allocation units (0: Unspecified)
+	UnitSize         uint16 // Allocation unit size in bytes (0: Unspecified)
+	FreeUnits        uint16
+	LargestFreeBlock uint16 // Largest free block in allocation units
+	MaxRecordSize    uint8  // Maximum record size in allocation units
+}
+
+func (c *GetSELAllocationInfoCommand) Name() string    { return "Get SEL Allocation Info" }
+func (c *GetSELAllocationInfoCommand) Code() uint8     { return 0x41 }
+func (c *GetSELAllocationInfoCommand) Retryable() bool { return true }
+
+func (c *GetSELAllocationInfoCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnStorageReq, 0)
+}
+
+func (c *GetSELAllocationInfoCommand) String() string           { return cmdToJSON(c) }
+func (c *GetSELAllocationInfoCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetSELAllocationInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 9); err != nil {
+		return nil, err
+	}
+	c.AllocationUnits = binary.LittleEndian.Uint16(buf[0:2])
+	c.UnitSize = binary.LittleEndian.Uint16(buf[2:4])
+	c.FreeUnits = binary.LittleEndian.Uint16(buf[4:6])
+	c.LargestFreeBlock = binary.LittleEndian.Uint16(buf[6:8])
+	c.MaxRecordSize = buf[8]
+	return buf[9:], nil
+}
+
 // Reserve SEL Command (Section 31.4)
 type ReserveSELCommand struct {
 	// Response Data
@@ -87,6 +121,7 @@ type GetSELEntryCommand struct {
 
 func (c *GetSELEntryCommand) Name() string           { return "Get SDR" }
 func (c *GetSELEntryCommand) Code() uint8            { return 0x43 }
+func (c *GetSELEntryCommand) Retryable() bool        { return true }
 func (c *GetSELEntryCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnStorageReq, 0) }
 func (c *GetSELEntryCommand) String() string         { return cmdToJSON(c) }
 
@@ -112,3 +147,38 @@ func (c *GetSELEntryCommand) Unmarshal(buf []byte) ([]byte, error) {
 		return buf[c.ReadBytes:], nil
 	}
 }
+
+// Clear SEL Command (Section 31.9)
+type ClearSELCommand struct {
+	// Request Data
+	ReservationID uint16
+	GetStatus     bool // Get the erasure status instead of initiating the erase
+
+	// Response Data
+	Completed bool // Erasure is completed
+}
+
+func (c *ClearSELCommand) Name() string { return "Clear SEL" }
+func (c *ClearSELCommand) Code() uint8  { return 0x47 }
+
+func (c *ClearSELCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnStorageReq, 0)
+}
+
+func (c *ClearSELCommand) String() string { return cmdToJSON(c) }
+
+func (c *ClearSELCommand) Marshal() ([]byte, error) {
+	var op byte = 0xaa // Initiate erase
+	if c.GetStatus {
+		op = 0x00
+	}
+	return []byte{byte(c.ReservationID), byte(c.ReservationID >> 8), 'C', 'L', 'R', op}, nil
+}
+
+func (c *ClearSELCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Completed = buf[0]&0x0f == 0x01
+	return buf[1:], nil
+}
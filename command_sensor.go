@@ -1,5 +1,246 @@
 package ipmigo
 
+import (
+	"encoding/binary"
+)
+
+// Event receiver address which disables the event message generation
+const EventReceiverDisabled uint8 = 0xff
+
+// Set Event Receiver Command (Section 29.1)
+type SetEventReceiverCommand struct {
+	// Request Data
+	Address uint8 // 8-bit slave address of the event receiver (EventReceiverDisabled: Disable)
+	LUN     uint8
+}
+
+func (c *SetEventReceiverCommand) Name() string { return "Set Event Receiver" }
+func (c *SetEventReceiverCommand) Code() uint8  { return 0x00 }
+
+func (c *SetEventReceiverCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, 0)
+}
+
+func (c *SetEventReceiverCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetEventReceiverCommand) Marshal() ([]byte, error) {
+	return []byte{c.Address, c.LUN & 0x03}, nil
+}
+
+func (c *SetEventReceiverCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get Event Receiver Command (Section 29.2)
+type GetEventReceiverCommand struct {
+	// Response Data
+	Address uint8 // 8-bit slave address of the event receiver (EventReceiverDisabled: Disabled)
+	LUN     uint8
+}
+
+func (c *GetEventReceiverCommand) Name() string    { return "Get Event Receiver" }
+func (c *GetEventReceiverCommand) Code() uint8     { return 0x01 }
+func (c *GetEventReceiverCommand) Retryable() bool { return true }
+
+func (c *GetEventReceiverCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, 0)
+}
+
+func (c *GetEventReceiverCommand) String() string           { return cmdToJSON(c) }
+func (c *GetEventReceiverCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetEventReceiverCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.Address = buf[0]
+	c.LUN = buf[1] & 0x03
+	return buf[2:], nil
+}
+
+// Get Device SDR Info Command (Section 35.2)
+type GetDeviceSDRInfoCommand struct {
+	// Request Data
+	RsLUN    uint8
+	SDRCount bool // Get the number of SDRs instead of the number of sensors
+
+	// Response Data
+	Count             uint8 // Number of sensors or SDRs in the LUN
+	DynamicPopulation bool
+	LUNs              uint8  // Bitmap of LUNs that have sensors
+	PopulationChange  uint32 // Sensor population change indicator (Only for the dynamic population)
+}
+
+func (c *GetDeviceSDRInfoCommand) Name() string    { return "Get Device SDR Info" }
+func (c *GetDeviceSDRInfoCommand) Code() uint8     { return 0x20 }
+func (c *GetDeviceSDRInfoCommand) Retryable() bool { return true }
+
+func (c *GetDeviceSDRInfoCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, c.RsLUN)
+}
+
+func (c *GetDeviceSDRInfoCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetDeviceSDRInfoCommand) Marshal() ([]byte, error) {
+	if c.SDRCount {
+		return []byte{0x01}, nil
+	}
+	return []byte{}, nil
+}
+
+func (c *GetDeviceSDRInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.Count = buf[0]
+	c.DynamicPopulation = buf[1]&0x80 != 0
+	c.LUNs = buf[1] & 0x0f
+
+	if len(buf) < 6 {
+		c.PopulationChange = 0
+		return buf[2:], nil
+	}
+	c.PopulationChange = binary.LittleEndian.Uint32(buf[2:6])
+	return buf[6:], nil
+}
+
+// Get Device SDR Command (Section 35.3)
+type GetDeviceSDRCommand struct {
+	GetSDRCommand
+
+	// Request Data
+	RsLUN uint8
+}
+
+func (c *GetDeviceSDRCommand) Name() string    { return "Get Device SDR" }
+func (c *GetDeviceSDRCommand) Code() uint8     { return 0x21 }
+func (c *GetDeviceSDRCommand) Retryable() bool { return true }
+
+func (c *GetDeviceSDRCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, c.RsLUN)
+}
+
+func (c *GetDeviceSDRCommand) String() string { return cmdToJSON(c) }
+
+// Reserve Device SDR Repository Command (Section 35.4)
+type ReserveDeviceSDRRepositoryCommand struct {
+	// Request Data
+	RsLUN uint8
+
+	// Response Data
+	ReservationID uint16
+}
+
+func (c *ReserveDeviceSDRRepositoryCommand) Name() string { return "Reserve Device SDR Repository" }
+func (c *ReserveDeviceSDRRepositoryCommand) Code() uint8  { return 0x22 }
+
+func (c *ReserveDeviceSDRRepositoryCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, c.RsLUN)
+}
+
+func (c *ReserveDeviceSDRRepositoryCommand) String() string           { return cmdToJSON(c) }
+func (c *ReserveDeviceSDRRepositoryCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *ReserveDeviceSDRRepositoryCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 2); err != nil {
+		return nil, err
+	}
+	c.ReservationID = binary.LittleEndian.Uint16(buf)
+	return buf[2:], nil
+}
+
+// Re-arm Sensor Events Command (Section 35.12)
+type RearmSensorEventsCommand struct {
+	// Request Data
+	RsLUN           uint8
+	SensorNumber    uint8
+	Selected        bool   // Re-arm only the selected events instead of all events
+	AssertionMask   uint16 // Bitmap of the assertion event offsets (0 - 14) to re-arm
+	DeassertionMask uint16 // Bitmap of the deassertion event offsets (0 - 14) to re-arm
+}
+
+func (c *RearmSensorEventsCommand) Name() string { return "Re-arm Sensor Events" }
+func (c *RearmSensorEventsCommand) Code() uint8  { return 0x2a }
+
+func (c *RearmSensorEventsCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, c.RsLUN)
+}
+
+func (c *RearmSensorEventsCommand) String() string { return cmdToJSON(c) }
+
+func (c *RearmSensorEventsCommand) Marshal() ([]byte, error) {
+	if !c.Selected {
+		return []byte{c.SensorNumber, 0x00}, nil
+	}
+	a, d := c.AssertionMask&0x7fff, c.DeassertionMask&0x7fff
+	return []byte{c.SensorNumber, 0x80, byte(a), byte(a >> 8), byte(d), byte(d >> 8)}, nil
+}
+
+func (c *RearmSensorEventsCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get Sensor Event Status Command (Section 35.13)
+type GetSensorEventStatusCommand struct {
+	// Request Data
+	RsLUN        uint8
+	SensorNumber uint8
+
+	// Response Data
+	EventDisabled      bool
+	ScanningDisabled   bool
+	ReadingUnavailable bool
+	AssertionEvents    uint16 // Bitmap of the asserted event offsets (0 - 14)
+	DeassertionEvents  uint16 // Bitmap of the deasserted event offsets (0 - 14)
+}
+
+func (c *GetSensorEventStatusCommand) Name() string    { return "Get Sensor Event Status" }
+func (c *GetSensorEventStatusCommand) Code() uint8     { return 0x2b }
+func (c *GetSensorEventStatusCommand) Retryable() bool { return true }
+
+func (c *GetSensorEventStatusCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnSensorReq, c.RsLUN)
+}
+
+func (c *GetSensorEventStatusCommand) String() string           { return cmdToJSON(c) }
+func (c *GetSensorEventStatusCommand) Marshal() ([]byte, error) { return []byte{c.SensorNumber}, nil }
+
+func (c *GetSensorEventStatusCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.EventDisabled = buf[0]&0x80 == 0
+	c.ScanningDisabled = buf[0]&0x40 == 0
+	c.ReadingUnavailable = buf[0]&0x20 != 0
+
+	// Event status bytes are optional
+	var status [4]byte
+	n := copy(status[:], buf[1:])
+	c.AssertionEvents = (uint16(status[0]) | uint16(status[1])<<8) & 0x7fff
+	c.DeassertionEvents = (uint16(status[2]) | uint16(status[3])<<8) & 0x7fff
+	return buf[1+n:], nil
+}
+
+// Returns the asserted event offsets in ascending order
+func (c *GetSensorEventStatusCommand) AssertedOffsets() []uint8 {
+	return eventOffsets(c.AssertionEvents)
+}
+
+// Returns the deasserted event offsets in ascending order
+func (c *GetSensorEventStatusCommand) DeassertedOffsets() []uint8 {
+	return eventOffsets(c.DeassertionEvents)
+}
+
+func eventOffsets(mask uint16) []uint8 {
+	offsets := []uint8{}
+	for i := uint8(0); i < 15; i++ {
+		if mask&(1<<i) != 0 {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
 // Get Sensor Reading Command (Section 35.14)
 type GetSensorReadingCommand struct {
 	// Request Data
@@ -15,8 +256,9 @@ type GetSensorReadingCommand struct {
 	SensorData3        uint8
 }
 
-func (c *GetSensorReadingCommand) Name() string { return "Get Sensor Reading" }
-func (c *GetSensorReadingCommand) Code() uint8  { return 0x2d }
+func (c *GetSensorReadingCommand) Name() string    { return "Get Sensor Reading" }
+func (c *GetSensorReadingCommand) Code() uint8     { return 0x2d }
+func (c *GetSensorReadingCommand) Retryable() bool { return true }
 
 func (c *GetSensorReadingCommand) NetFnRsLUN() NetFnRsLUN {
 	return NewNetFnRsLUN(NetFnSensorReq, c.RsLUN)
@@ -54,3 +296,13 @@ func (c *GetSensorReadingCommand) IsValid() bool {
 func (c *GetSensorReadingCommand) ThresholdStatus() ThresholdStatus {
 	return NewThresholdStatus(c.SensorData2)
 }
+
+// Returns all threshold comparisons if sensor is threshold-base.
+func (c *GetSensorReadingCommand) ThresholdComparison() ThresholdComparison {
+	return NewThresholdComparison(c.SensorData2)
+}
+
+// Returns the asserted states if sensor is discrete.
+func (c *GetSensorReadingCommand) DiscreteStates() DiscreteStates {
+	return NewDiscreteStates(c.SensorData2, c.SensorData3)
+}
@@ -0,0 +1,71 @@
+package ipmigo
+
+// Set SOL Configuration Parameters Command (Section 26.2)
+type SetSOLConfigParametersCommand struct {
+	// Request Data
+	Channel   uint8 // Channel number (0x0e: Channel this request was issued on)
+	Parameter SOLConfigParameter
+}
+
+func (c *SetSOLConfigParametersCommand) Name() string { return "Set SOL Configuration Parameters" }
+func (c *SetSOLConfigParametersCommand) Code() uint8  { return 0x21 }
+
+func (c *SetSOLConfigParametersCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnTransportReq, 0)
+}
+
+func (c *SetSOLConfigParametersCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetSOLConfigParametersCommand) Marshal() ([]byte, error) {
+	if c.Parameter == nil {
+		return nil, &ArgumentError{Value: c.Parameter, Message: "SOL configuration parameter is required"}
+	}
+	data, err := c.Parameter.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{c.Channel & 0x0f, c.Parameter.Selector()}, data...), nil
+}
+
+func (c *SetSOLConfigParametersCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get SOL Configuration Parameters Command (Section 26.3)
+type GetSOLConfigParametersCommand struct {
+	// Request Data
+	Channel       uint8 // Channel number (0x0e: Channel this request was issued on)
+	SetSelector   uint8
+	BlockSelector uint8
+
+	// Request and Response Data, the selector is taken from the parameter
+	Parameter SOLConfigParameter
+
+	// Response Data
+	ParameterRevision uint8
+}
+
+func (c *GetSOLConfigParametersCommand) Name() string    { return "Get SOL Configuration Parameters" }
+func (c *GetSOLConfigParametersCommand) Code() uint8     { return 0x22 }
+func (c *GetSOLConfigParametersCommand) Retryable() bool { return true }
+
+func (c *GetSOLConfigParametersCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(NetFnTransportReq, 0)
+}
+
+func (c *GetSOLConfigParametersCommand) String() string { return cmdToJSON(c) }
+
+func (c *GetSOLConfigParametersCommand) Marshal() ([]byte, error) {
+	if c.Parameter == nil {
+		return nil, &ArgumentError{Value: c.Parameter, Message: "SOL configuration parameter is required"}
+	}
+	return []byte{c.Channel & 0x0f, c.Parameter.Selector(), c.SetSelector, c.BlockSelector}, nil
+}
+
+func (c *GetSOLConfigParametersCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.ParameterRevision = buf[0]
+	return c.Parameter.Unmarshal(buf[1:])
+}
@@ -0,0 +1,190 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const watchdogCountUnit = 100 * time.Millisecond
+
+// Timer Use of Watchdog Timer (Table 27-6)
+type WatchdogTimerUse uint8
+
+const (
+	WatchdogTimerUseBIOSFRB2 WatchdogTimerUse = iota + 1
+	WatchdogTimerUseBIOSPOST
+	WatchdogTimerUseOSLoad
+	WatchdogTimerUseSMSOS
+	WatchdogTimerUseOEM
+)
+
+func (w WatchdogTimerUse) String() string {
+	switch w {
+	case WatchdogTimerUseBIOSFRB2:
+		return "BIOS FRB2"
+	case WatchdogTimerUseBIOSPOST:
+		return "BIOS/POST"
+	case WatchdogTimerUseOSLoad:
+		return "OS Load"
+	case WatchdogTimerUseSMSOS:
+		return "SMS/OS"
+	case WatchdogTimerUseOEM:
+		return "OEM"
+	default:
+		return fmt.Sprintf("Reserved(%d)", w)
+	}
+}
+
+// Pre-timeout Interrupt of Watchdog Timer (Table 27-6)
+type WatchdogPreTimeoutInterrupt uint8
+
+const (
+	WatchdogPreTimeoutNone WatchdogPreTimeoutInterrupt = iota
+	WatchdogPreTimeoutSMI
+	WatchdogPreTimeoutNMI
+	WatchdogPreTimeoutMessaging
+)
+
+func (w WatchdogPreTimeoutInterrupt) String() string {
+	switch w {
+	case WatchdogPreTimeoutNone:
+		return "None"
+	case WatchdogPreTimeoutSMI:
+		return "SMI"
+	case WatchdogPreTimeoutNMI:
+		return "NMI / Diagnostic Interrupt"
+	case WatchdogPreTimeoutMessaging:
+		return "Messaging Interrupt"
+	default:
+		return fmt.Sprintf("Reserved(%d)", w)
+	}
+}
+
+// Timeout Action of Watchdog Timer (Table 27-6)
+type WatchdogTimeoutAction uint8
+
+const (
+	WatchdogActionNone WatchdogTimeoutAction = iota
+	WatchdogActionHardReset
+	WatchdogActionPowerDown
+	WatchdogActionPowerCycle
+)
+
+func (w WatchdogTimeoutAction) String() string {
+	switch w {
+	case WatchdogActionNone:
+		return "No action"
+	case WatchdogActionHardReset:
+		return "Hard Reset"
+	case WatchdogActionPowerDown:
+		return "Power Down"
+	case WatchdogActionPowerCycle:
+		return "Power Cycle"
+	default:
+		return fmt.Sprintf("Reserved(%d)", w)
+	}
+}
+
+// Reset Watchdog Timer Command (Section 27.5)
+type ResetWatchdogTimerCommand struct{}
+
+func (c *ResetWatchdogTimerCommand) Name() string             { return "Reset Watchdog Timer" }
+func (c *ResetWatchdogTimerCommand) Code() uint8              { return 0x22 }
+func (c *ResetWatchdogTimerCommand) NetFnRsLUN() NetFnRsLUN   { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *ResetWatchdogTimerCommand) String() string           { return cmdToJSON(c) }
+func (c *ResetWatchdogTimerCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *ResetWatchdogTimerCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Set Watchdog Timer Command (Section 27.6)
+type SetWatchdogTimerCommand struct {
+	// Request Data
+	TimerUse             WatchdogTimerUse
+	DontStopTimer        bool // Don't stop the timer if it is running
+	DontLog              bool
+	PreTimeoutInterrupt  WatchdogPreTimeoutInterrupt
+	TimeoutAction        WatchdogTimeoutAction
+	PreTimeoutInterval   time.Duration // In seconds
+	ExpirationFlagsClear uint8         // Bitmap of the timer use expiration flags to clear
+	InitialCountdown     time.Duration // In 100 milliseconds
+}
+
+func (c *SetWatchdogTimerCommand) Name() string           { return "Set Watchdog Timer" }
+func (c *SetWatchdogTimerCommand) Code() uint8            { return 0x24 }
+func (c *SetWatchdogTimerCommand) NetFnRsLUN() NetFnRsLUN { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *SetWatchdogTimerCommand) String() string         { return cmdToJSON(c) }
+
+func (c *SetWatchdogTimerCommand) Marshal() ([]byte, error) {
+	count := c.InitialCountdown / watchdogCountUnit
+	if count < 0 || count > 0xffff {
+		return nil, &ArgumentError{
+			Value:   c.InitialCountdown,
+			Message: "Initial countdown is out of range",
+		}
+	}
+	interval := c.PreTimeoutInterval / time.Second
+	if interval < 0 || interval > 0xff {
+		return nil, &ArgumentError{
+			Value:   c.PreTimeoutInterval,
+			Message: "Pre-timeout interval is out of range",
+		}
+	}
+
+	buf := make([]byte, 6)
+	buf[0] = byte(c.TimerUse) & 0x07
+	if c.DontStopTimer {
+		buf[0] |= 0x40
+	}
+	if c.DontLog {
+		buf[0] |= 0x80
+	}
+	buf[1] = byte(c.PreTimeoutInterrupt)&0x07<<4 | byte(c.TimeoutAction)&0x07
+	buf[2] = byte(interval)
+	buf[3] = c.ExpirationFlagsClear & 0x3e
+	binary.LittleEndian.PutUint16(buf[4:], uint16(count))
+	return buf, nil
+}
+
+func (c *SetWatchdogTimerCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get Watchdog Timer Command (Section 27.7)
+type GetWatchdogTimerCommand struct {
+	// Response Data
+	TimerUse            WatchdogTimerUse
+	TimerRunning        bool
+	DontLog             bool
+	PreTimeoutInterrupt WatchdogPreTimeoutInterrupt
+	TimeoutAction       WatchdogTimeoutAction
+	PreTimeoutInterval  time.Duration
+	ExpirationFlags     uint8 // Bitmap of the timer use expiration flags
+	InitialCountdown    time.Duration
+	PresentCountdown    time.Duration
+}
+
+func (c *GetWatchdogTimerCommand) Name() string             { return "Get Watchdog Timer" }
+func (c *GetWatchdogTimerCommand) Code() uint8              { return 0x25 }
+func (c *GetWatchdogTimerCommand) Retryable() bool          { return true }
+func (c *GetWatchdogTimerCommand) NetFnRsLUN() NetFnRsLUN   { return NewNetFnRsLUN(NetFnAppReq, 0) }
+func (c *GetWatchdogTimerCommand) String() string           { return cmdToJSON(c) }
+func (c *GetWatchdogTimerCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetWatchdogTimerCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 8); err != nil {
+		return nil, err
+	}
+	c.TimerUse = WatchdogTimerUse(buf[0] & 0x07)
+	c.TimerRunning = buf[0]&0x40 != 0
+	c.DontLog = buf[0]&0x80 != 0
+	c.PreTimeoutInterrupt = WatchdogPreTimeoutInterrupt(buf[1] >> 4 & 0x07)
+	c.TimeoutAction = WatchdogTimeoutAction(buf[1] & 0x07)
+	c.PreTimeoutInterval = time.Duration(buf[2]) * time.Second
+	c.ExpirationFlags = buf[3] & 0x3e
+	c.InitialCountdown = time.Duration(binary.LittleEndian.Uint16(buf[4:])) * watchdogCountUnit
+	c.PresentCountdown = time.Duration(binary.LittleEndian.Uint16(buf[6:])) * watchdogCountUnit
+	return buf[8:], nil
+}
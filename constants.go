@@ -2,7 +2,9 @@ package ipmigo
 
 const (
 	userNameMaxLength     = 16
+	passwordMaxLengthV1_5 = 16
 	passwordMaxLengthV2_0 = 20
 	bmcSlaveAddress       = 0x20
 	remoteSWID            = 0x81
+	defaultPort           = "623" // RMCP port (Section 13.1.1)
 )
@@ -0,0 +1,328 @@
+package ipmigo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const (
+	dcmiMCIDStringBlock = 16 // Max bytes of the identifier string per request
+	dcmiMCIDStringMax   = 64 // Max bytes of the identifier string including the null terminator
+
+	dcmiSensorTypeTemperature SensorType = 0x01
+)
+
+// DCMI entity IDs of the temperature sensors (DCMI v1.5 Section 6.5)
+const (
+	DCMIEntityInlet     EntityID = 0x40 // Inlet temperature
+	DCMIEntityCPU       EntityID = 0x41 // CPU temperature
+	DCMIEntityBaseboard EntityID = 0x42 // Baseboard temperature
+)
+
+// Entity IDs of DCMI v1.0, which some BMCs still use instead of the DCMI v1.5 entity IDs
+var dcmiLegacyEntityIDs = map[EntityID]EntityID{
+	DCMIEntityInlet:     0x37, // Air inlet
+	DCMIEntityCPU:       0x03, // Processor
+	DCMIEntityBaseboard: 0x07, // System board
+}
+
+// Parameter of the DCMI capabilities (DCMI v1.5 Table 6-3)
+type DCMICapabilityParameter interface {
+	Selector() uint8
+	Unmarshal(buf []byte) ([]byte, error)
+}
+
+// DCMI capability parameter selectors (DCMI v1.5 Table 6-3)
+const (
+	DCMICapSupportedCapabilities   uint8 = 1
+	DCMICapPlatformAttributes      uint8 = 2
+	DCMICapOptionalAttributes      uint8 = 3
+	DCMICapManageabilityAttributes uint8 = 4
+	DCMICapPowerStatistics         uint8 = 5
+)
+
+// Supported DCMI Capabilities parameter (Selector 1)
+type DCMISupportedCapabilities struct {
+	// Mandatory platform capabilities (DCMI v1.0 only)
+	Identification     bool
+	SELLogging         bool
+	ChassisPower       bool
+	TemperatureMonitor bool
+
+	// Optional platform capabilities
+	PowerManagement bool
+
+	// Manageability access capabilities
+	InBandKCS    bool // In-band system interface channel
+	SerialTMODE  bool // Serial TMODE
+	SecondaryLAN bool // Out-of-band secondary LAN channel
+	PrimaryLAN   bool // Out-of-band primary LAN channel
+	SOL          bool
+	VLAN         bool
+}
+
+func (p *DCMISupportedCapabilities) Selector() uint8 { return DCMICapSupportedCapabilities }
+
+func (p *DCMISupportedCapabilities) Unmarshal(buf []byte) ([]byte, error) {
+	if err := dcmiValidateParameter(p, buf, 3); err != nil {
+		return nil, err
+	}
+	p.Identification = buf[0]&0x01 != 0
+	p.SELLogging = buf[0]&0x02 != 0
+	p.ChassisPower = buf[0]&0x04 != 0
+	p.TemperatureMonitor = buf[0]&0x08 != 0
+	p.PowerManagement = buf[1]&0x01 != 0
+	p.InBandKCS = buf[2]&0x01 != 0
+	p.SerialTMODE = buf[2]&0x02 != 0
+	p.SecondaryLAN = buf[2]&0x04 != 0
+	p.PrimaryLAN = buf[2]&0x08 != 0
+	p.SOL = buf[2]&0x10 != 0
+	p.VLAN = buf[2]&0x20 != 0
+	return buf[3:], nil
+}
+
+// Mandatory Platform Attributes parameter (Selector 2)
+type DCMIPlatformAttributes struct {
+	SELEntries         uint16 // Number of SEL entries
+	SELFlushOnRollover bool   // Record level SEL flush upon rollover
+	SELAutoRollover    bool   // SEL automatic rollover is enabled
+
+	// Identification attributes (DCMI v1.0 only)
+	GUID         bool
+	DHCPHostName bool
+	AssetTag     bool
+
+	// Temperature monitoring attributes (DCMI v1.0 only)
+	InletTemperature     bool
+	ProcessorTemperature bool
+	BaseboardTemperature bool
+
+	TemperatureSampling time.Duration // Temperature sampling frequency
+}
+
+func (p *DCMIPlatformAttributes) Selector() uint8 { return DCMICapPlatformAttributes }
+
+func (p *DCMIPlatformAttributes) Unmarshal(buf []byte) ([]byte, error) {
+	if err := dcmiValidateParameter(p, buf, 5); err != nil {
+		return nil, err
+	}
+	sel := uint16(buf[0]) | uint16(buf[1])<<8
+	p.SELEntries = sel & 0x0fff
+	p.SELFlushOnRollover = sel&0x4000 != 0
+	p.SELAutoRollover = sel&0x8000 != 0
+	p.GUID = buf[2]&0x01 != 0
+	p.DHCPHostName = buf[2]&0x02 != 0
+	p.AssetTag = buf[2]&0x04 != 0
+	p.InletTemperature = buf[3]&0x01 != 0
+	p.ProcessorTemperature = buf[3]&0x02 != 0
+	p.BaseboardTemperature = buf[3]&0x04 != 0
+	p.TemperatureSampling = time.Duration(buf[4]) * time.Second
+	return buf[5:], nil
+}
+
+// Optional Platform Attributes parameter (Selector 3)
+type DCMIOptionalAttributes struct {
+	PowerManagementAddress  uint8 // Slave address of the power management device
+	PowerManagementChannel  uint8
+	PowerManagementRevision uint8
+}
+
+func (p *DCMIOptionalAttributes) Selector() uint8 { return DCMICapOptionalAttributes }
+
+func (p *DCMIOptionalAttributes) Unmarshal(buf []byte) ([]byte, error) {
+	if err := dcmiValidateParameter(p, buf, 2); err != nil {
+		return nil, err
+	}
+	p.PowerManagementAddress = buf[0] &^ 0x01
+	p.PowerManagementChannel = buf[1] >> 4
+	p.PowerManagementRevision = buf[1] & 0x0f
+	return buf[2:], nil
+}
+
+// Manageability Access Attributes parameter (Selector 4), the channel is 0xff if not supported
+type DCMIManageabilityAttributes struct {
+	PrimaryLANChannel   uint8
+	SecondaryLANChannel uint8
+	SerialChannel       uint8
+}
+
+func (p *DCMIManageabilityAttributes) Selector() uint8 { return DCMICapManageabilityAttributes }
+
+func (p *DCMIManageabilityAttributes) Unmarshal(buf []byte) ([]byte, error) {
+	if err := dcmiValidateParameter(p, buf, 3); err != nil {
+		return nil, err
+	}
+	p.PrimaryLANChannel = buf[0]
+	p.SecondaryLANChannel = buf[1]
+	p.SerialChannel = buf[2]
+	return buf[3:], nil
+}
+
+// Enhanced System Power Statistics Attributes parameter (Selector 5, DCMI v1.5)
+type DCMIPowerStatistics struct {
+	Periods []time.Duration // Supported rolling average time periods
+}
+
+func (p *DCMIPowerStatistics) Selector() uint8 { return DCMICapPowerStatistics }
+
+func (p *DCMIPowerStatistics) Unmarshal(buf []byte) ([]byte, error) {
+	if err := dcmiValidateParameter(p, buf, 1); err != nil {
+		return nil, err
+	}
+	n := int(buf[0])
+	if err := dcmiValidateParameter(p, buf, 1+n); err != nil {
+		return nil, err
+	}
+	p.Periods = make([]time.Duration, n)
+	for i, b := range buf[1 : 1+n] {
+		p.Periods[i] = dcmiTimePeriod(b)
+	}
+	return buf[1+n:], nil
+}
+
+// Decodes the time period, which consists of the unit (bit 7:6) and the duration (bit 5:0)
+func dcmiTimePeriod(b byte) time.Duration {
+	units := []time.Duration{time.Second, time.Minute, time.Hour, 24 * time.Hour}
+	return time.Duration(b&0x3f) * units[b>>6]
+}
+
+// DCMI capability parameter which is not decoded
+type DCMICapabilityRaw struct {
+	ID   uint8 // Parameter selector
+	Data []byte
+}
+
+func (p *DCMICapabilityRaw) Selector() uint8 { return p.ID }
+
+func (p *DCMICapabilityRaw) Unmarshal(buf []byte) ([]byte, error) {
+	p.Data = make([]byte, len(buf))
+	copy(p.Data, buf)
+	return nil, nil
+}
+
+func dcmiValidateParameter(p DCMICapabilityParameter, buf []byte, min int) error {
+	if l := len(buf); l < min {
+		return &MessageError{
+			Message: fmt.Sprintf("Invalid DCMI capability parameter %d size : %d/%d", p.Selector(), l, min),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	return nil
+}
+
+// Returns the management controller identifier string.
+func DCMIGetMCIDString(c *Client) (string, error) {
+	var buf []byte
+	for {
+		cmd := &GetDCMIMCIDStringCommand{Offset: uint8(len(buf)), ReadCount: dcmiMCIDStringBlock}
+		if err := c.Execute(cmd); err != nil {
+			return "", err
+		}
+		buf = append(buf, cmd.Data...)
+
+		if len(cmd.Data) == 0 || len(buf) >= int(cmd.Length) || len(buf) >= dcmiMCIDStringMax {
+			break
+		}
+	}
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[:i]
+	}
+	return string(buf), nil
+}
+
+// Sets the management controller identifier string, which is up to 63 bytes.
+func DCMISetMCIDString(c *Client, id string) error {
+	buf := append([]byte(id), 0)
+	if len(buf) > dcmiMCIDStringMax {
+		return &ArgumentError{
+			Value:   id,
+			Message: fmt.Sprintf("Identifier string must be up to %d bytes", dcmiMCIDStringMax-1),
+		}
+	}
+	if bytes.IndexByte([]byte(id), 0) >= 0 {
+		return &ArgumentError{Value: id, Message: "Identifier string must not contain null characters"}
+	}
+
+	for offset := 0; offset < len(buf); offset += dcmiMCIDStringBlock {
+		end := offset + dcmiMCIDStringBlock
+		if end > len(buf) {
+			end = len(buf)
+		}
+		cmd := &SetDCMIMCIDStringCommand{Offset: uint8(offset), Data: buf[offset:end]}
+		if err := c.Execute(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the SDR record IDs of the DCMI temperature sensors of the entity (e.g. DCMIEntityInlet).
+// The entity ID of DCMI v1.0 is tried if the BMC has no sensors of the entity.
+func DCMIGetSensorRecordIDs(c *Client, entity EntityID) ([]uint16, error) {
+	ids, err := dcmiGetSensorRecordIDs(c, entity)
+	if err != nil || len(ids) > 0 {
+		return ids, err
+	}
+	if legacy, ok := dcmiLegacyEntityIDs[entity]; ok {
+		return dcmiGetSensorRecordIDs(c, legacy)
+	}
+	return ids, nil
+}
+
+func dcmiGetSensorRecordIDs(c *Client, entity EntityID) ([]uint16, error) {
+	var ids []uint16
+	for {
+		cmd := &GetDCMISensorInfoCommand{
+			SensorType:    dcmiSensorTypeTemperature,
+			EntityID:      entity,
+			StartInstance: uint8(len(ids) + 1),
+		}
+		if err := c.Execute(cmd); err != nil {
+			return nil, err
+		}
+		ids = append(ids, cmd.RecordIDs...)
+
+		if len(cmd.RecordIDs) == 0 || len(ids) >= int(cmd.TotalInstances) {
+			return ids, nil
+		}
+	}
+}
+
+// Returns the sensor records of the DCMI temperature sensors from SDR repository, which are grouped by entities.
+// If no entities are specified, the inlet, CPU and baseboard temperature sensors are returned.
+func DCMIGetSensorRecords(c *Client, entities ...EntityID) (map[EntityID][]SDR, error) {
+	if len(entities) == 0 {
+		entities = []EntityID{DCMIEntityInlet, DCMIEntityCPU, DCMIEntityBaseboard}
+	}
+
+	groups := make(map[uint16]EntityID)
+	for _, e := range entities {
+		ids, err := DCMIGetSensorRecordIDs(c, e)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			groups[id] = e
+		}
+	}
+
+	sensors := make(map[EntityID][]SDR)
+	if len(groups) == 0 {
+		return sensors, nil
+	}
+
+	records, err := SDRGetRecordsRepo(c, func(id uint16, t SDRType) bool {
+		_, ok := groups[id]
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		e := groups[r.ID()]
+		sensors[e] = append(sensors[e], r)
+	}
+	return sensors, nil
+}
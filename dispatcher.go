@@ -0,0 +1,334 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	dispatchQueueSize = 16
+)
+
+var errDispatcherClosed = errors.New("ipmigo: dispatcher is closed")
+
+// A Dispatcher multiplexes the sessions of many BMCs over a small number of UDP sockets.
+//
+// Received datagrams are demultiplexed by the socket, the remote address and the session ID,
+// so a socket can be shared by the sessions of the same BMC. Each IPMI v2.0 session is assigned
+// the console session ID which is unique in the Dispatcher.
+type Dispatcher struct {
+	network string
+	sockets []*dispatchSocket
+	mu      sync.Mutex
+	done    chan struct{}
+	closed  bool
+	nextID  uint32 // Last assigned console session ID
+}
+
+type dispatchSocket struct {
+	conn   *net.UDPConn
+	routes map[string][]*dispatchConn // Remote address to the logical connections
+	count  int                        // Number of the logical connections
+}
+
+// Create a Dispatcher with the number of UDP sockets
+func NewDispatcher(network string, sockets int) (*Dispatcher, error) {
+	if network == "" {
+		network = "udp"
+	}
+	if sockets <= 0 {
+		return nil, &ArgumentError{
+			Value:   sockets,
+			Message: "Invalid number of sockets",
+		}
+	}
+
+	d := &Dispatcher{network: network, done: make(chan struct{})}
+	for i := 0; i < sockets; i++ {
+		conn, err := net.ListenUDP(network, nil)
+		if err != nil {
+			d.Close()
+			return nil, err
+		}
+		sock := &dispatchSocket{conn: conn, routes: make(map[string][]*dispatchConn)}
+		d.sockets = append(d.sockets, sock)
+		go d.serve(sock)
+	}
+	return d, nil
+}
+
+// Close all sockets of the Dispatcher
+func (d *Dispatcher) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	close(d.done)
+
+	var err error
+	for _, sock := range d.sockets {
+		if e := sock.conn.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (d *Dispatcher) serve(sock *dispatchSocket) {
+	buf := make([]byte, recvBufferSize)
+	for {
+		n, addr, err := sock.conn.ReadFromUDP(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return
+		}
+
+		msg := make([]byte, n)
+		copy(msg, buf)
+
+		d.mu.Lock()
+		conns := dispatchRoute(sock.routes[addr.String()], dispatchSessionID(msg))
+		d.mu.Unlock()
+
+		// The datagram from an unknown address or session is dropped
+		for _, c := range conns {
+			select {
+			case c.queue <- msg:
+			default:
+				// Drop the datagram if the session is not reading
+			}
+		}
+	}
+}
+
+// Returns the connections to deliver the datagram of the session ID.
+// The datagram without the session ID (e.g. ASF or pre-session messages) is delivered to all connections,
+// and the one with the unknown session ID is delivered to the connections whose session is not established.
+func dispatchRoute(conns []*dispatchConn, id uint32) []*dispatchConn {
+	if id == 0 {
+		return conns
+	}
+
+	var unbound []*dispatchConn
+	for _, c := range conns {
+		switch c.sessionID() {
+		case id:
+			return []*dispatchConn{c}
+		case 0:
+			unbound = append(unbound, c)
+		}
+	}
+	return unbound
+}
+
+// Returns the session ID of the console in the RMCP message, or 0 if it is not known
+func dispatchSessionID(msg []byte) uint32 {
+	if len(msg) <= rmcpHeaderSize || rmcpClass(msg[3]) != rmcpClassIPMI {
+		return 0
+	}
+	buf := msg[rmcpHeaderSize:]
+
+	if AuthType(buf[0]) != AuthTypeRMCPPlus {
+		// IPMI v1.5 session header (Table 13-4)
+		if len(buf) < 9 {
+			return 0
+		}
+		return binary.LittleEndian.Uint32(buf[5:])
+	}
+
+	hdr := &sessionHeaderV2_0{}
+	rest, err := hdr.Unmarshal(buf)
+	if err != nil {
+		return 0
+	}
+	if hdr.id != 0 {
+		return hdr.id
+	}
+	switch hdr.payloadType.Pure() {
+	case payloadTypeRMCPOpenRes, payloadTypeRAKP2, payloadTypeRAKP4:
+		// Session setup messages have the remote console session ID in the payload
+		if len(rest) >= 8 {
+			return binary.LittleEndian.Uint32(rest[4:])
+		}
+	}
+	return 0
+}
+
+// Returns a logical connection to the address, which is assigned to the least used socket.
+// If `v2` is true, the connection is bound to a new console session ID of IPMI v2.0.
+func (d *Dispatcher) dial(network, addr string, v2 bool) (net.Conn, error) {
+	switch network {
+	case "udp", "udp4", "udp6":
+	default:
+		return nil, &ArgumentError{
+			Value:   network,
+			Message: "Dispatcher supports UDP networks only",
+		}
+	}
+	raddr, err := net.ResolveUDPAddr(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	key := raddr.String()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil, errDispatcherClosed
+	}
+
+	var sock *dispatchSocket
+	for _, s := range d.sockets {
+		if sock == nil || s.count < sock.count {
+			sock = s
+		}
+	}
+
+	c := &dispatchConn{
+		dispatcher: d,
+		socket:     sock,
+		raddr:      raddr,
+		queue:      make(chan []byte, dispatchQueueSize),
+		done:       make(chan struct{}),
+	}
+	if v2 {
+		if d.nextID++; d.nextID == 0 {
+			d.nextID++
+		}
+		c.consoleID = d.nextID
+		c.id = c.consoleID
+	}
+	sock.routes[key] = append(sock.routes[key], c)
+	sock.count++
+	return c, nil
+}
+
+// A logical connection over the shared socket
+type dispatchConn struct {
+	dispatcher *Dispatcher
+	socket     *dispatchSocket
+	raddr      *net.UDPAddr
+	queue      chan []byte
+	done       chan struct{}
+	consoleID  uint32 // Console session ID of IPMI v2.0 (0: IPMI v1.5)
+
+	mu       sync.Mutex
+	deadline time.Time
+	closed   bool
+	id       uint32 // Session ID of the received datagrams (Guarded by the dispatcher)
+}
+
+// Returns the session ID of the datagrams routed to the connection (0: Not established)
+func (c *dispatchConn) sessionID() uint32 { return c.id }
+
+// Routes the datagrams of the session ID to the connection
+func (c *dispatchConn) setSessionID(id uint32) {
+	c.dispatcher.mu.Lock()
+	c.id = id
+	c.dispatcher.mu.Unlock()
+}
+
+func (c *dispatchConn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := deadline.Sub(time.Now())
+		if d <= 0 {
+			return 0, &timeoutError{Message: "i/o timeout"}
+		}
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case msg := <-c.queue:
+		return copy(b, msg), nil
+	case <-timeout:
+		return 0, &timeoutError{Message: "i/o timeout"}
+	case <-c.done:
+		return 0, errDispatcherClosed
+	case <-c.dispatcher.done:
+		return 0, errDispatcherClosed
+	}
+}
+
+func (c *dispatchConn) Write(b []byte) (int, error) {
+	return c.socket.conn.WriteToUDP(b, c.raddr)
+}
+
+func (c *dispatchConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+
+	c.dispatcher.mu.Lock()
+	defer c.dispatcher.mu.Unlock()
+
+	key := c.raddr.String()
+	conns := c.socket.routes[key]
+	for i, x := range conns {
+		if x == c {
+			conns = append(conns[:i:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(conns) == 0 {
+		delete(c.socket.routes, key)
+	} else {
+		c.socket.routes[key] = conns
+	}
+	c.socket.count--
+	return nil
+}
+
+func (c *dispatchConn) LocalAddr() net.Addr  { return c.socket.conn.LocalAddr() }
+func (c *dispatchConn) RemoteAddr() net.Addr { return c.raddr }
+
+func (c *dispatchConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *dispatchConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+// The write deadline is not supported because the socket is shared
+func (c *dispatchConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// Returns the console session ID of IPMI v2.0 for the connection
+func dispatchConsoleID(conn net.Conn) uint32 {
+	if c, ok := conn.(*dispatchConn); ok && c.consoleID != 0 {
+		return c.consoleID
+	}
+	return defaultConsoleID
+}
+
+// Routes the datagrams of the IPMI v1.5 session ID to the connection if it is dispatched
+func dispatchSetSessionID(conn net.Conn, id uint32) {
+	if c, ok := conn.(*dispatchConn); ok {
+		c.setSessionID(id)
+	}
+}
@@ -1,7 +1,10 @@
 package ipmigo
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"strings"
 )
 
 // An ArgumentError suggests that the arguments are wrong
@@ -14,6 +17,37 @@ func (e *ArgumentError) Error() string {
 	return fmt.Sprintf("%s, value `%v`", e.Message, e.Value)
 }
 
+// An ArgumentErrors is the list of the problems of the arguments
+type ArgumentErrors []*ArgumentError
+
+func (e ArgumentErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Returns the errors of the list for errors.Is/As
+func (e ArgumentErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Returns nil if the list is empty, and the *ArgumentError itself if the list has only one
+func (e ArgumentErrors) orNil() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	}
+	return e
+}
+
 // A MessageError suggests that the received message is wrong or is not obtained
 type MessageError struct {
 	Cause   error  // Cause of the error
@@ -29,14 +63,65 @@ func (e *MessageError) Error() string {
 	}
 }
 
+func (e *MessageError) Unwrap() error { return e.Cause }
+
 var ErrNotSupportedIPMI error = &MessageError{Message: "Not Supported IPMI"}
+var ErrMessageTruncated error = &MessageError{Message: "Received message is truncated"}
+
+// Classes of the errors, which are tested with errors.Is
+var (
+	ErrSessionExpired        error = &MessageError{Message: "Session is expired"}
+	ErrTimeout               error = &MessageError{Message: "Timed out"}
+	ErrInsufficientPrivilege error = &MessageError{Message: "Insufficient privilege level"}
+	ErrUnsupportedCommand    error = &MessageError{Message: "Unsupported command"}
+)
 
 // A CommandError suggests that command execution has failed
 type CommandError struct {
 	CompletionCode CompletionCode
 	Command        Command
+	Request        []byte // Request data (Only for Client.ExecuteCapture)
+	Response       []byte // Response data following the completion code
 }
 
 func (e *CommandError) Error() string {
 	return fmt.Sprintf("Command %s(0x%02x) failed - %s", e.Command.Name(), e.Command.Code(), e.CompletionCode)
 }
+
+// Returns the class of the completion code, or nil if it is not classified
+func (e *CommandError) Unwrap() error {
+	switch c := e.CompletionCode; {
+	case c == CompletionTimeout:
+		return ErrTimeout
+	case c.IsPrivilegeError():
+		return ErrInsufficientPrivilege
+	case c.IsUnsupported():
+		return ErrUnsupportedCommand
+	}
+	return nil
+}
+
+// A timeoutError satisfies net.Error to be retried like the network timeout
+type timeoutError struct {
+	Message string
+}
+
+func (e *timeoutError) Error() string   { return e.Message }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+func (e *timeoutError) Unwrap() error   { return ErrTimeout }
+
+// Returns true if the error is likely to be resolved by retrying the request later,
+// such as the timeout or the busy BMC.
+func IsTemporary(err error) bool {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	if errors.Is(err, ErrTimeout) {
+		return true
+	}
+
+	var ce *CommandError
+	return errors.As(err, &ce) && ce.CompletionCode.IsTemporary()
+}
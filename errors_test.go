@@ -0,0 +1,28 @@
+package ipmigo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArgumentErrorsAs(t *testing.T) {
+	// Only one problem
+	err := (&Arguments{Version: V2_0, Address: "192.0.2.1", PipelineWindow: pipelineWindowMax + 1}).validate()
+	if _, ok := err.(*ArgumentError); !ok {
+		t.Fatalf("err = %T, expected *ArgumentError", err)
+	}
+
+	// Multiple problems
+	err = (&Arguments{Version: V2_0, PipelineWindow: pipelineWindowMax + 1}).validate()
+	errs, ok := err.(ArgumentErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("err = %#v, expected ArgumentErrors of 2", err)
+	}
+	var ae *ArgumentError
+	if !errors.As(err, &ae) {
+		t.Fatalf("errors.As(%v) failed", err)
+	}
+	if ae != errs[0] {
+		t.Errorf("errors.As = %v, expected %v", ae, errs[0])
+	}
+}
@@ -1,5 +1,7 @@
 package ipmigo
 
+import "fmt"
+
 // Event/Reading Type (Table 42-2)
 type EventType uint8
 
@@ -9,6 +11,66 @@ func (e EventType) IsGeneric() bool        { return e >= 0x02 && e <= 0x0c }
 func (e EventType) IsSensorSpecific() bool { return e == 0x6f }
 func (e EventType) IsOEM() bool            { return e >= 0x70 && e <= 0x7f }
 
+// Returns the category of the event/reading type (Table 42-1)
+func (e EventType) String() string {
+	switch {
+	case e.IsUnspecified():
+		return "Unspecified"
+	case e.IsThreshold():
+		return "Threshold"
+	case e.IsGeneric():
+		return "Generic"
+	case e.IsSensorSpecific():
+		return "Sensor-specific"
+	case e.IsOEM():
+		return "OEM"
+	default:
+		return fmt.Sprintf("Reserved(0x%02x)", uint8(e))
+	}
+}
+
+var eventTypeDescriptions = []string{
+	"Unspecified",
+	"Threshold",
+	"DMI-based Usage State",
+	"Digital Discrete",
+	"Digital Discrete (Predictive Failure)",
+	"Digital Discrete (Limit)",
+	"Digital Discrete (Performance)",
+	"Severity",
+	"Device Presence",
+	"Device Enabled",
+	"Availability State",
+	"Redundancy",
+	"ACPI Device Power State",
+}
+
+// Returns the description of the event/reading type (e.g. "Device Presence")
+func (e EventType) Description() string {
+	switch {
+	case int(e) < len(eventTypeDescriptions):
+		return eventTypeDescriptions[e]
+	case e.IsSensorSpecific():
+		return "Sensor-specific"
+	case e.IsOEM():
+		return fmt.Sprintf("OEM(0x%02x)", uint8(e))
+	default:
+		return fmt.Sprintf("Reserved(0x%02x)", uint8(e))
+	}
+}
+
+// Returns the description of the generic event offset of the threshold or generic event/reading type.
+func (e EventType) OffsetDescription(offset uint8) (string, bool) {
+	desc, ok := sensorGenericEventDesc[uint32(e)<<8|uint32(offset&0x0f)]
+	return desc, ok
+}
+
+// Returns the description of the sensor-specific event offset of the sensor type.
+func (t SensorType) OffsetDescription(offset uint8) (string, bool) {
+	desc, ok := sensorSpecificEventDesc[uint32(t)<<24|uint32(offset&0x0f)<<16|0xffff]
+	return desc, ok
+}
+
 // Sensor generic event description (Table 42-2)
 var sensorGenericEventDesc = map[uint32]string{
 	// Event Type, Offset
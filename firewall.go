@@ -0,0 +1,137 @@
+package ipmigo
+
+import "fmt"
+
+const (
+	commandMaskHalfSize = 16
+)
+
+// Bitmask of the command codes (0x00 - 0xff) used by the firmware firewall
+type CommandMask [2 * commandMaskHalfSize]byte
+
+// Returns `true` if the command code is set
+func (m *CommandMask) IsSet(code uint8) bool {
+	return m[code/8]&(1<<(code%8)) != 0
+}
+
+func (m *CommandMask) Set(code uint8) {
+	m[code/8] |= 1 << (code % 8)
+}
+
+func (m *CommandMask) Clear(code uint8) {
+	m[code/8] &^= 1 << (code % 8)
+}
+
+// Returns the set command codes in ascending order
+func (m *CommandMask) Codes() []uint8 {
+	codes := []uint8{}
+	for i := 0; i < 8*len(m); i++ {
+		if m.IsSet(uint8(i)) {
+			codes = append(codes, uint8(i))
+		}
+	}
+	return codes
+}
+
+func (m *CommandMask) String() string {
+	return fmt.Sprintf("%02x", m.Codes())
+}
+
+// Sets the command codes of the other mask
+func (m *CommandMask) merge(o *CommandMask) {
+	for i := range m {
+		m[i] |= o[i]
+	}
+}
+
+func (m *CommandMask) marshalHalf(upper bool) []byte {
+	buf := make([]byte, commandMaskHalfSize)
+	if upper {
+		copy(buf, m[commandMaskHalfSize:])
+	} else {
+		copy(buf, m[:commandMaskHalfSize])
+	}
+	return buf
+}
+
+func (m *CommandMask) unmarshalHalf(upper bool, buf []byte, invert bool) {
+	half := m[:commandMaskHalfSize]
+	if upper {
+		half = m[commandMaskHalfSize:]
+	}
+	for i := range half {
+		half[i] = buf[i]
+		if invert {
+			half[i] = ^buf[i]
+		}
+	}
+}
+
+// Target of the firmware firewall commands (Section 21.3)
+type FirewallTarget struct {
+	Channel      uint8  // Channel number (0x0e: Channel this request was issued on)
+	NetFn        NetFn  // Request NetFn
+	LUN          uint8  // Logical unit number
+	DefiningBody uint8  // Defining body code (Only for the Group Extension NetFn)
+	IANA         uint32 // OEM enterprise number (Only for the OEM/Group NetFn)
+}
+
+func (t *FirewallTarget) marshal(upper bool) ([]byte, error) {
+	if t.NetFn > netFnOEMMax || t.NetFn&0x01 != 0 {
+		return nil, &ArgumentError{
+			Value:   t.NetFn,
+			Message: "NetFn must be an even number in the range 0x00 to 0x3e",
+		}
+	}
+
+	fn := byte(t.NetFn)
+	if upper {
+		fn |= 0x40
+	}
+	buf := []byte{t.Channel & 0x0f, fn, t.LUN & 0x03}
+
+	switch t.NetFn {
+	case NetFnGroupExtensionReq:
+		buf = append(buf, t.DefiningBody)
+	case NetFnOEMGroupReq:
+		buf = append(buf, byte(t.IANA), byte(t.IANA>>8), byte(t.IANA>>16))
+	}
+	return buf, nil
+}
+
+// Returns the commands supported by the target.
+func FirewallGetCommandSupport(c *Client, target FirewallTarget) (*CommandMask, error) {
+	mask := &CommandMask{}
+	for _, upper := range []bool{false, true} {
+		cmd := &GetCommandSupportCommand{Target: target, UpperHalf: upper}
+		if err := c.Execute(cmd); err != nil {
+			return nil, err
+		}
+		mask.merge(&cmd.Supported)
+	}
+	return mask, nil
+}
+
+// Returns the commands enabled on the target.
+func FirewallGetCommandEnables(c *Client, target FirewallTarget) (*CommandMask, error) {
+	mask := &CommandMask{}
+	for _, upper := range []bool{false, true} {
+		cmd := &GetCommandEnablesCommand{Target: target, UpperHalf: upper}
+		if err := c.Execute(cmd); err != nil {
+			return nil, err
+		}
+		mask.merge(&cmd.Enabled)
+	}
+	return mask, nil
+}
+
+// Enables the commands set in the mask, and disables the others.
+func FirewallSetCommandEnables(c *Client, target FirewallTarget, enabled *CommandMask) error {
+	for _, upper := range []bool{false, true} {
+		cmd := &SetCommandEnablesCommand{Target: target, UpperHalf: upper, Enabled: *enabled}
+		if err := c.Execute(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
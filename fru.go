@@ -0,0 +1,581 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	fruHeaderSize       = 8
+	fruFormatVersion    = 0x01
+	fruAreaUnit         = 8    // Offsets and lengths of the areas are in multiples of 8 bytes
+	fruEndOfFields      = 0xc1 // Type/length byte which terminates the fields
+	fruDefaultReadBytes = 16
+	fruReadBytesStep    = 4
+	fruReadGrowAfter    = 64 // Successful reads before trying the larger chunk
+	fruOffsetMax        = 0x10000
+
+	fruSEEPROMSize           = 256 // Size of 24C02 compatible SEEPROM
+	fruMultiRecordHeaderSize = 5
+	fruFieldMaxLength        = 0x3f
+	fruLanguageEnglish       = 25 // Language code of English (0 is also English)
+)
+
+// Start of the manufacturing date/time of the board info area
+var fruMfgDateEpoch = time.Date(1996, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FRU Common Header (Section 8 of Platform Management FRU Information Storage Definition)
+type FRUHeader struct {
+	FormatVersion     uint8
+	InternalUseOffset int // Offset in bytes (0: Not present)
+	ChassisOffset     int
+	BoardOffset       int
+	ProductOffset     int
+	MultiRecordOffset int
+}
+
+func (h *FRUHeader) Unmarshal(buf []byte) ([]byte, error) {
+	if err := fruValidate("Common Header", buf, fruHeaderSize); err != nil {
+		return nil, err
+	}
+	if v := buf[0] & 0x0f; v != fruFormatVersion {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Unknown FRU format version : %d", v),
+			Detail:  hex.EncodeToString(buf[:fruHeaderSize]),
+		}
+	}
+	if checksum(buf[:fruHeaderSize]) != 0 {
+		return nil, &MessageError{
+			Message: "Invalid FRU Common Header checksum",
+			Detail:  hex.EncodeToString(buf[:fruHeaderSize]),
+		}
+	}
+	h.FormatVersion = buf[0] & 0x0f
+	h.InternalUseOffset = int(buf[1]) * fruAreaUnit
+	h.ChassisOffset = int(buf[2]) * fruAreaUnit
+	h.BoardOffset = int(buf[3]) * fruAreaUnit
+	h.ProductOffset = int(buf[4]) * fruAreaUnit
+	h.MultiRecordOffset = int(buf[5]) * fruAreaUnit
+	return buf[fruHeaderSize:], nil
+}
+
+// Chassis Info Area (Section 10)
+type FRUChassisInfo struct {
+	Type         uint8 // Chassis type (SMBIOS)
+	PartNumber   string
+	SerialNumber string
+	Custom       []string
+}
+
+func (a *FRUChassisInfo) Unmarshal(buf []byte) ([]byte, error) {
+	if err := fruValidateArea("Chassis Info Area", buf, 3); err != nil {
+		return nil, err
+	}
+	a.Type = buf[2]
+
+	fields, err := fruDecodeFields("Chassis Info Area", buf[3:], 2, fruLanguageEnglish)
+	if err != nil {
+		return nil, err
+	}
+	a.PartNumber, a.SerialNumber = fields[0], fields[1]
+	a.Custom = fields[2:]
+	return nil, nil
+}
+
+func (a *FRUChassisInfo) Marshal() ([]byte, error) {
+	fields := append([]string{a.PartNumber, a.SerialNumber}, a.Custom...)
+	return fruEncodeArea("Chassis Info Area", []byte{fruFormatVersion, 0, a.Type}, fields, fruLanguageEnglish)
+}
+
+// Board Info Area (Section 11)
+type FRUBoardInfo struct {
+	LanguageCode uint8
+	MfgDateTime  time.Time // Manufacturing date/time (Zero: Unspecified)
+	Manufacturer string
+	ProductName  string
+	SerialNumber string
+	PartNumber   string
+	FileID       string
+	Custom       []string
+}
+
+func (a *FRUBoardInfo) Unmarshal(buf []byte) ([]byte, error) {
+	if err := fruValidateArea("Board Info Area", buf, 6); err != nil {
+		return nil, err
+	}
+	a.LanguageCode = buf[2]
+	if m := uint32(buf[3]) | uint32(buf[4])<<8 | uint32(buf[5])<<16; m != 0 {
+		a.MfgDateTime = fruMfgDateEpoch.Add(time.Duration(m) * time.Minute)
+	} else {
+		a.MfgDateTime = time.Time{}
+	}
+
+	fields, err := fruDecodeFields("Board Info Area", buf[6:], 5, a.LanguageCode)
+	if err != nil {
+		return nil, err
+	}
+	a.Manufacturer, a.ProductName, a.SerialNumber, a.PartNumber, a.FileID =
+		fields[0], fields[1], fields[2], fields[3], fields[4]
+	a.Custom = fields[5:]
+	return nil, nil
+}
+
+func (a *FRUBoardInfo) Marshal() ([]byte, error) {
+	var m int64
+	if !a.MfgDateTime.IsZero() {
+		m = int64(a.MfgDateTime.Sub(fruMfgDateEpoch) / time.Minute)
+		if m <= 0 || m >= 1<<24 {
+			return nil, &ArgumentError{
+				Value:   a.MfgDateTime,
+				Message: "Manufacturing date/time is out of range",
+			}
+		}
+	}
+	head := []byte{fruFormatVersion, 0, a.LanguageCode, byte(m), byte(m >> 8), byte(m >> 16)}
+	fields := append([]string{a.Manufacturer, a.ProductName, a.SerialNumber, a.PartNumber, a.FileID}, a.Custom...)
+	return fruEncodeArea("Board Info Area", head, fields, a.LanguageCode)
+}
+
+// Product Info Area (Section 12)
+type FRUProductInfo struct {
+	LanguageCode uint8
+	Manufacturer string
+	ProductName  string
+	PartNumber   string // Part/Model number
+	Version      string
+	SerialNumber string
+	AssetTag     string
+	FileID       string
+	Custom       []string
+}
+
+func (a *FRUProductInfo) Unmarshal(buf []byte) ([]byte, error) {
+	if err := fruValidateArea("Product Info Area", buf, 3); err != nil {
+		return nil, err
+	}
+	a.LanguageCode = buf[2]
+
+	fields, err := fruDecodeFields("Product Info Area", buf[3:], 7, a.LanguageCode)
+	if err != nil {
+		return nil, err
+	}
+	a.Manufacturer, a.ProductName, a.PartNumber, a.Version, a.SerialNumber, a.AssetTag, a.FileID =
+		fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6]
+	a.Custom = fields[7:]
+	return nil, nil
+}
+
+func (a *FRUProductInfo) Marshal() ([]byte, error) {
+	fields := append([]string{a.Manufacturer, a.ProductName, a.PartNumber, a.Version, a.SerialNumber,
+		a.AssetTag, a.FileID}, a.Custom...)
+	return fruEncodeArea("Product Info Area", []byte{fruFormatVersion, 0, a.LanguageCode}, fields,
+		a.LanguageCode)
+}
+
+// FRU Information of a FRU device
+type FRU struct {
+	DeviceID    uint8
+	Header      FRUHeader
+	InternalUse []byte
+	Chassis     *FRUChassisInfo // nil if not present
+	Board       *FRUBoardInfo   // nil if not present
+	Product     *FRUProductInfo // nil if not present
+	MultiRecord []byte          // Raw bytes of the MultiRecord area (nil if not present)
+
+	raw []byte // Bytes of the inventory area which are decoded
+}
+
+// Decodes the FRU information from the whole bytes of the inventory area
+func (f *FRU) Unmarshal(buf []byte) ([]byte, error) {
+	f.raw = buf
+	if _, err := f.Header.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	h := &f.Header
+
+	area := func(offset int) ([]byte, error) { return fruArea(buf, offset) }
+
+	if h.InternalUseOffset > 0 {
+		// The internal use area has no length, it ends at the next area
+		end := len(buf)
+		for _, o := range []int{h.ChassisOffset, h.BoardOffset, h.ProductOffset, h.MultiRecordOffset} {
+			if o > h.InternalUseOffset && o < end {
+				end = o
+			}
+		}
+		if h.InternalUseOffset < end {
+			f.InternalUse = buf[h.InternalUseOffset:end]
+		}
+	}
+	if h.ChassisOffset > 0 {
+		b, err := area(h.ChassisOffset)
+		if err != nil {
+			return nil, err
+		}
+		f.Chassis = &FRUChassisInfo{}
+		if _, err := f.Chassis.Unmarshal(b); err != nil {
+			return nil, err
+		}
+	}
+	if h.BoardOffset > 0 {
+		b, err := area(h.BoardOffset)
+		if err != nil {
+			return nil, err
+		}
+		f.Board = &FRUBoardInfo{}
+		if _, err := f.Board.Unmarshal(b); err != nil {
+			return nil, err
+		}
+	}
+	if h.ProductOffset > 0 {
+		b, err := area(h.ProductOffset)
+		if err != nil {
+			return nil, err
+		}
+		f.Product = &FRUProductInfo{}
+		if _, err := f.Product.Unmarshal(b); err != nil {
+			return nil, err
+		}
+	}
+	if h.MultiRecordOffset > 0 && h.MultiRecordOffset < len(buf) {
+		b := buf[h.MultiRecordOffset:]
+		f.MultiRecord = b[:fruMultiRecordLength(b)]
+	}
+	return nil, nil
+}
+
+// Returns the bytes of the info area at the offset
+func fruArea(buf []byte, offset int) ([]byte, error) {
+	if offset >= len(buf) {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("FRU area offset is out of range : %d/%d", offset, len(buf)),
+		}
+	}
+	b := buf[offset:]
+	if len(b) < 2 {
+		return b, nil
+	}
+	if n := int(b[1]) * fruAreaUnit; n > 0 && n <= len(b) {
+		b = b[:n]
+	}
+	return b, nil
+}
+
+// Returns the length of the MultiRecord area, which is walked until the end of list record
+func fruMultiRecordLength(buf []byte) int {
+	n := 0
+	for n+fruMultiRecordHeaderSize <= len(buf) {
+		h := buf[n : n+fruMultiRecordHeaderSize]
+		if checksum(h) != 0 {
+			break
+		}
+		n += fruMultiRecordHeaderSize + int(h[2])
+		if h[1]&0x80 != 0 {
+			break
+		}
+	}
+	if n > len(buf) {
+		n = len(buf)
+	}
+	return n
+}
+
+func fruValidate(name string, buf []byte, min int) error {
+	if l := len(buf); l < min {
+		return &MessageError{
+			Message: fmt.Sprintf("Invalid FRU %s size : %d/%d", name, l, min),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	return nil
+}
+
+// Validates the length and the checksum of the info area
+func fruValidateArea(name string, buf []byte, min int) error {
+	if err := fruValidate(name, buf, min); err != nil {
+		return err
+	}
+	if n := int(buf[1]) * fruAreaUnit; n > 0 && n <= len(buf) {
+		if checksum(buf[:n]) != 0 {
+			return &MessageError{
+				Message: fmt.Sprintf("Invalid FRU %s checksum", name),
+				Detail:  hex.EncodeToString(buf[:n]),
+			}
+		}
+	}
+	return nil
+}
+
+// Decodes the type/length fields until the end marker. At least `n` fields are returned.
+func fruDecodeFields(name string, buf []byte, n int, lang uint8) ([]string, error) {
+	var fields []string
+	for len(buf) > 0 && buf[0] != fruEndOfFields {
+		typeLen := buf[0]
+		l := int(typeLen & 0x3f)
+		if len(buf) < 1+l {
+			return nil, &MessageError{
+				Message: fmt.Sprintf("FRU %s field is truncated : %d/%d", name, len(buf)-1, l),
+				Detail:  hex.EncodeToString(buf),
+			}
+		}
+		fields = append(fields, fruDecodeField(typeLen, buf[1:1+l], lang))
+		buf = buf[1+l:]
+	}
+	for len(fields) < n {
+		fields = append(fields, "")
+	}
+	return fields, nil
+}
+
+// Decodes the field data by the type code of the type/length byte (Section 13)
+func fruDecodeField(typeLen byte, data []byte, lang uint8) string {
+	switch typeLen >> 6 {
+	case 0x01: // BCD plus
+		return decodeBCDPlus(data)
+	case 0x02: // 6-bit ASCII packed
+		return decode6bitASCII(data)
+	case 0x03:
+		if lang == 0 || lang == fruLanguageEnglish {
+			// 8-bit ASCII + Latin 1
+			return string(data)
+		}
+		// 2-byte UNICODE, least significant byte first
+		u := make([]uint16, len(data)/2)
+		for i := range u {
+			u[i] = binary.LittleEndian.Uint16(data[i*2:])
+		}
+		return string(utf16.Decode(u))
+	default: // Binary or unspecified
+		return hex.EncodeToString(data)
+	}
+}
+
+// Encodes the info area which consists of the header bytes and the fields.
+// The fields are encoded as 8-bit ASCII, or 2-byte UNICODE if the language is not English.
+// The area length and the checksum are filled.
+func fruEncodeArea(name string, head []byte, fields []string, lang uint8) ([]byte, error) {
+	buf := append([]byte{}, head...)
+	for _, f := range fields {
+		data, typeLen := []byte(f), byte(0xc0)
+		if lang != 0 && lang != fruLanguageEnglish {
+			data = data[:0]
+			for _, u := range utf16.Encode([]rune(f)) {
+				data = append(data, byte(u), byte(u>>8))
+			}
+		} else if len(data) == 1 {
+			// 0xc1 is reserved as the end marker, so a single character is encoded as 6-bit ASCII
+			if data[0] < 0x20 || data[0] > 0x5f {
+				return nil, &ArgumentError{
+					Value:   f,
+					Message: fmt.Sprintf("FRU %s field of a single character must be 6-bit ASCII", name),
+				}
+			}
+			data, typeLen = []byte{data[0] - 0x20}, 0x80
+		}
+
+		if len(data) > fruFieldMaxLength {
+			return nil, &ArgumentError{
+				Value:   f,
+				Message: fmt.Sprintf("FRU %s field is longer than %d bytes", name, fruFieldMaxLength),
+			}
+		}
+		buf = append(buf, typeLen|byte(len(data)))
+		buf = append(buf, data...)
+	}
+	buf = append(buf, fruEndOfFields)
+
+	// Pad to a multiple of 8 bytes including the checksum
+	for (len(buf)+1)%fruAreaUnit != 0 {
+		buf = append(buf, 0)
+	}
+	n := (len(buf) + 1) / fruAreaUnit
+	if n > 0xff {
+		return nil, &ArgumentError{
+			Value:   fields,
+			Message: fmt.Sprintf("FRU %s is too large : %d bytes", name, n*fruAreaUnit),
+		}
+	}
+	buf[1] = byte(n)
+	return append(buf, byte(checksum(buf))), nil
+}
+
+// Reads and decodes the FRU information of the FRU device (0: the FRU of BMC).
+func FRUGet(c *Client, deviceID uint8) (*FRU, error) {
+	buf, err := fruReadAll(c, c.Execute, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FRU{DeviceID: deviceID}
+	if _, err := f.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Reads the FRU information of all FRU devices, which are the FRU of BMC and the devices of
+// the FRU device locator records in the SDR repository. The result is keyed by the device name.
+// Devices which do not respond with the FRU information (e.g. Not present) are skipped.
+func FRUGetAll(c *Client) (map[string]*FRU, error) {
+	records, err := SDRGetRecordsRepo(c, func(id uint16, t SDRType) bool {
+		return t == SDRTypeFRUDeviceLocator
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	frus := make(map[string]*FRU)
+	add := func(name string, f *FRU, err error) error {
+		if err != nil {
+			if _, ok := err.(*CommandError); ok {
+				return nil
+			}
+			if _, ok := err.(*MessageError); ok {
+				return nil
+			}
+			return err
+		}
+		if _, ok := frus[name]; ok {
+			name = fmt.Sprintf("%s (ID %d)", name, f.DeviceID)
+		}
+		frus[name] = f
+		return nil
+	}
+
+	builtin := false
+	for _, r := range records {
+		loc, ok := r.(*SDRFRUDeviceLocator)
+		if !ok {
+			continue
+		}
+		if loc.Logical && loc.DeviceID == 0 && loc.SlaveAddress<<1 == bmcSlaveAddress {
+			builtin = true
+		}
+		f, err := fruGetByLocator(c, loc)
+		if err := add(loc.SensorID(), f, err); err != nil {
+			return nil, err
+		}
+	}
+	if !builtin {
+		f, err := FRUGet(c, 0)
+		if err := add("Builtin FRU Device (ID 0)", f, err); err != nil {
+			return nil, err
+		}
+	}
+	return frus, nil
+}
+
+// Reads and decodes the FRU information of the FRU device locator record.
+// Logical FRU devices are accessed by the FRU commands of the controller, and the others are
+// read through the private bus of the controller as 24C02 compatible SEEPROM.
+func fruGetByLocator(c *Client, loc *SDRFRUDeviceLocator) (*FRU, error) {
+	execute := c.Execute
+	if addr := loc.SlaveAddress << 1; addr != bmcSlaveAddress {
+		target := BridgeTarget{Channel: loc.ChannelNumber, Address: addr}
+		execute = func(cmd Command) error { return c.Execute(NewBridgedCommand(cmd, target)) }
+	}
+
+	var buf []byte
+	var err error
+	if loc.Logical {
+		buf, err = fruReadAll(c, execute, loc.DeviceID)
+	} else {
+		buf, err = fruReadSEEPROM(execute, loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FRU{DeviceID: loc.DeviceID}
+	if _, err := f.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Reads the non-intelligent FRU device on the private bus by Master Write-Read
+func fruReadSEEPROM(execute func(Command) error, loc *SDRFRUDeviceLocator) ([]byte, error) {
+	buf := make([]byte, 0, fruSEEPROMSize)
+	for len(buf) < fruSEEPROMSize {
+		cmd := &MasterWriteReadCommand{
+			Channel:      loc.ChannelNumber,
+			BusID:        loc.BusID,
+			PrivateBus:   true,
+			SlaveAddress: loc.DeviceID,
+			ReadCount:    fruDefaultReadBytes,
+			WriteData:    []byte{byte(len(buf))},
+		}
+		if err := execute(cmd); err != nil {
+			return nil, err
+		}
+		if len(cmd.ReadData) == 0 {
+			return nil, &MessageError{Message: fmt.Sprintf("No FRU data at offset %d", len(buf))}
+		}
+		buf = append(buf, cmd.ReadData...)
+	}
+	return buf, nil
+}
+
+// Reads the whole inventory area of the FRU device
+func fruReadAll(c *Client, execute func(Command) error, deviceID uint8) ([]byte, error) {
+	info := &GetFRUInventoryAreaInfoCommand{DeviceID: deviceID}
+	if err := execute(info); err != nil {
+		return nil, err
+	}
+	return fruRead(c, execute, deviceID, info.AccessByWord, 0, int(info.AreaSize))
+}
+
+// Reads `size` bytes from the offset of the inventory area.
+// The bytes to read of each request are adjusted to the upper limit that BMC can respond.
+func fruRead(c *Client, execute func(Command) error, deviceID uint8, byWord bool, offset, size int) ([]byte, error) {
+	if end := offset + size; end > fruOffsetMax {
+		return nil, &ArgumentError{
+			Value:   end,
+			Message: "FRU offset is out of the 16-bit address range",
+		}
+	}
+
+	rs := newFRUTransferSize(c.args.FRUReadBytes, byWord)
+	buf := make([]byte, 0, size)
+	for len(buf) < size {
+		n := size - len(buf)
+		if r := int(rs.bytes); n > r {
+			n = r
+		}
+
+		// The offset is addressed by the 16-bit, in words for the word access device
+		o := offset + len(buf)
+		cmd := &ReadFRUDataCommand{DeviceID: deviceID, Offset: uint16(o), ReadCount: uint8(n)}
+		if byWord {
+			cmd.Offset = uint16(o / 2)
+			cmd.ReadCount = uint8((n + 1) / 2)
+		}
+		if err := execute(cmd); err != nil {
+			if e, ok := err.(*CommandError); ok && (e.CompletionCode == CompletionRequestDataFieldExceedEd ||
+				e.CompletionCode == CompletionCantReturnDataBytes) {
+				if rs.shrink() {
+					continue
+				}
+			}
+			return nil, err
+		}
+		if len(cmd.Data) == 0 {
+			return nil, &MessageError{Message: fmt.Sprintf("No FRU data at offset %d", o)}
+		}
+		rs.grow()
+		buf = append(buf, cmd.Data...)
+	}
+	return buf[:size], nil
+}
+
+// Returns the bytes of each FRU access from `max`, which are decreased to a word for the word access device
+func newFRUTransferSize(max uint8, byWord bool) *transferSize {
+	min := uint8(1)
+	if byWord {
+		min = 2
+	}
+	return newTransferSize(max, min, fruReadBytesStep, fruReadGrowAfter)
+}
@@ -0,0 +1,184 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Chassis types of SMBIOS
+var fruChassisTypes = []string{
+	"Unspecified", "Other", "Unknown", "Desktop", "Low Profile Desktop", "Pizza Box", "Mini Tower", "Tower",
+	"Portable", "LapTop", "Notebook", "Hand Held", "Docking Station", "All in One", "Sub Notebook",
+	"Space-saving", "Lunch Box", "Main Server Chassis", "Expansion Chassis", "SubChassis",
+	"Bus Expansion Chassis", "Peripheral Chassis", "RAID Chassis", "Rack Mount Chassis", "Sealed-case PC",
+	"Multi-system Chassis", "Compact PCI", "Advanced TCA", "Blade", "Blade Enclosure", "Tablet",
+	"Convertible", "Detachable", "IoT Gateway", "Embedded PC", "Mini PC", "Stick PC",
+}
+
+// Returns the description of the chassis type (e.g. "Rack Mount Chassis")
+func (a *FRUChassisInfo) TypeDescription() string {
+	if int(a.Type) < len(fruChassisTypes) {
+		return fruChassisTypes[a.Type]
+	}
+	return fmt.Sprintf("Unknown(0x%02x)", a.Type)
+}
+
+// FRU information rendered for the export
+type fruEntry struct {
+	DeviceID    uint8       `json:"device_id"`
+	InternalUse string      `json:"internal_use,omitempty"`
+	Chassis     *fruChassis `json:"chassis,omitempty"`
+	Board       *fruBoard   `json:"board,omitempty"`
+	Product     *fruProduct `json:"product,omitempty"`
+	MultiRecord string      `json:"multi_record,omitempty"`
+}
+
+type fruChassis struct {
+	Type         string   `json:"type"`
+	PartNumber   string   `json:"part_number"`
+	SerialNumber string   `json:"serial_number"`
+	Custom       []string `json:"custom,omitempty"`
+}
+
+type fruBoard struct {
+	LanguageCode uint8    `json:"language_code"`
+	MfgDateTime  string   `json:"mfg_date_time,omitempty"` // RFC3339
+	Manufacturer string   `json:"manufacturer"`
+	ProductName  string   `json:"product_name"`
+	SerialNumber string   `json:"serial_number"`
+	PartNumber   string   `json:"part_number"`
+	FileID       string   `json:"file_id"`
+	Custom       []string `json:"custom,omitempty"`
+}
+
+type fruProduct struct {
+	LanguageCode uint8    `json:"language_code"`
+	Manufacturer string   `json:"manufacturer"`
+	ProductName  string   `json:"product_name"`
+	PartNumber   string   `json:"part_number"`
+	Version      string   `json:"version"`
+	SerialNumber string   `json:"serial_number"`
+	AssetTag     string   `json:"asset_tag"`
+	FileID       string   `json:"file_id"`
+	Custom       []string `json:"custom,omitempty"`
+}
+
+func (f *FRU) MarshalJSON() ([]byte, error) {
+	e := &fruEntry{
+		DeviceID:    f.DeviceID,
+		InternalUse: hex.EncodeToString(f.InternalUse),
+		MultiRecord: hex.EncodeToString(f.MultiRecord),
+	}
+	if a := f.Chassis; a != nil {
+		e.Chassis = &fruChassis{
+			Type:         a.TypeDescription(),
+			PartNumber:   a.PartNumber,
+			SerialNumber: a.SerialNumber,
+			Custom:       a.Custom,
+		}
+	}
+	if a := f.Board; a != nil {
+		e.Board = &fruBoard{
+			LanguageCode: a.LanguageCode,
+			Manufacturer: a.Manufacturer,
+			ProductName:  a.ProductName,
+			SerialNumber: a.SerialNumber,
+			PartNumber:   a.PartNumber,
+			FileID:       a.FileID,
+			Custom:       a.Custom,
+		}
+		if !a.MfgDateTime.IsZero() {
+			e.Board.MfgDateTime = a.MfgDateTime.Format(time.RFC3339)
+		}
+	}
+	if a := f.Product; a != nil {
+		e.Product = &fruProduct{
+			LanguageCode: a.LanguageCode,
+			Manufacturer: a.Manufacturer,
+			ProductName:  a.ProductName,
+			PartNumber:   a.PartNumber,
+			Version:      a.Version,
+			SerialNumber: a.SerialNumber,
+			AssetTag:     a.AssetTag,
+			FileID:       a.FileID,
+			Custom:       a.Custom,
+		}
+	}
+	return json.Marshal(e)
+}
+
+// Writes the FRU information in the text format of `ipmitool fru print`.
+// The name is printed as the FRU device description.
+func FRUWriteText(w io.Writer, name string, f *FRU) error {
+	var err error
+	line := func(label, value string) {
+		if err == nil && value != "" {
+			_, err = fmt.Fprintf(w, " %-22s: %s\n", label, value)
+		}
+	}
+	extra := func(label string, values []string) {
+		for _, v := range values {
+			line(label, v)
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "%-23s: %s\n", "FRU Device Description", name); err != nil {
+		return err
+	}
+	if a := f.Chassis; a != nil {
+		line("Chassis Type", a.TypeDescription())
+		line("Chassis Part Number", a.PartNumber)
+		line("Chassis Serial", a.SerialNumber)
+		extra("Chassis Extra", a.Custom)
+	}
+	if a := f.Board; a != nil {
+		if a.MfgDateTime.IsZero() {
+			line("Board Mfg Date", "Unspecified")
+		} else {
+			line("Board Mfg Date", a.MfgDateTime.Format(time.ANSIC))
+		}
+		line("Board Mfg", a.Manufacturer)
+		line("Board Product", a.ProductName)
+		line("Board Serial", a.SerialNumber)
+		line("Board Part Number", a.PartNumber)
+		line("Board FRU ID", a.FileID)
+		extra("Board Extra", a.Custom)
+	}
+	if a := f.Product; a != nil {
+		line("Product Manufacturer", a.Manufacturer)
+		line("Product Name", a.ProductName)
+		line("Product Part Number", a.PartNumber)
+		line("Product Version", a.Version)
+		line("Product Serial", a.SerialNumber)
+		line("Product Asset Tag", a.AssetTag)
+		line("Product FRU ID", a.FileID)
+		extra("Product Extra", a.Custom)
+	}
+	return err
+}
+
+// Writes the FRU information of the devices (e.g. the result of FRUGetAll) in the text format of
+// `ipmitool fru print`. The devices are sorted by the name.
+func FRUWriteTextAll(w io.Writer, frus map[string]*FRU) error {
+	names := make([]string, 0, len(frus))
+	for n := range frus {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for i, n := range names {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := FRUWriteText(w, n, frus[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
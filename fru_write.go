@@ -0,0 +1,253 @@
+package ipmigo
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+const fruDefaultWriteBytes = 16
+
+// Info area which is re-encoded by the FRU update
+type fruInfoArea interface {
+	Marshal() ([]byte, error)
+}
+
+// Encodes the FRU information into the bytes of the inventory area.
+// The areas are laid out in the order of the common header, the internal use, chassis, board, product
+// and MultiRecord area. The info areas are re-encoded as the text of their language codes.
+func (f *FRU) Marshal() ([]byte, error) {
+	return fruMarshal(f, nil)
+}
+
+// Encodes the FRU information. The info areas which are not changed from `orig` keep the original bytes,
+// so that the fields of the other encodings are preserved.
+func fruMarshal(f, orig *FRU) ([]byte, error) {
+	type area struct {
+		data []byte
+		slot int // Offset index of the common header
+	}
+	var areas []area
+	if len(f.InternalUse) > 0 {
+		areas = append(areas, area{data: f.InternalUse, slot: 1})
+	}
+
+	infos := []struct {
+		cur, prev fruInfoArea
+		slot      int
+	}{
+		{slot: 2}, {slot: 3}, {slot: 4},
+	}
+	if f.Chassis != nil {
+		infos[0].cur = f.Chassis
+	}
+	if f.Board != nil {
+		infos[1].cur = f.Board
+	}
+	if f.Product != nil {
+		infos[2].cur = f.Product
+	}
+	if orig != nil {
+		infos[0].prev, infos[1].prev, infos[2].prev = orig.Chassis, orig.Board, orig.Product
+	}
+	for _, i := range infos {
+		if i.cur == nil {
+			continue
+		}
+		var b []byte
+		if o := orig.offset(i.slot); o > 0 && reflect.DeepEqual(i.cur, i.prev) {
+			b, _ = fruArea(orig.raw, o)
+		}
+		if b == nil {
+			var err error
+			if b, err = i.cur.Marshal(); err != nil {
+				return nil, err
+			}
+		}
+		areas = append(areas, area{data: b, slot: i.slot})
+	}
+
+	if len(f.MultiRecord) > 0 {
+		areas = append(areas, area{data: f.MultiRecord, slot: 5})
+	}
+
+	buf := make([]byte, fruHeaderSize)
+	buf[0] = fruFormatVersion
+	for _, a := range areas {
+		n := len(buf) / fruAreaUnit
+		if n > 0xff {
+			return nil, &ArgumentError{
+				Value:   len(buf),
+				Message: "FRU area offset is out of range",
+			}
+		}
+		buf[a.slot] = byte(n)
+		buf = append(buf, a.data...)
+		for len(buf)%fruAreaUnit != 0 {
+			buf = append(buf, 0)
+		}
+	}
+	buf[fruHeaderSize-1] = checksum(buf[:fruHeaderSize-1])
+	return buf, nil
+}
+
+// Returns the area offset of the common header slot (0: Not present)
+func (f *FRU) offset(slot int) int {
+	if f == nil {
+		return 0
+	}
+	switch slot {
+	case 1:
+		return f.Header.InternalUseOffset
+	case 2:
+		return f.Header.ChassisOffset
+	case 3:
+		return f.Header.BoardOffset
+	case 4:
+		return f.Header.ProductOffset
+	case 5:
+		return f.Header.MultiRecordOffset
+	}
+	return 0
+}
+
+// Reads the FRU information of the FRU device, applies `edit` to it and writes it back.
+// Only the changed bytes are written, then they are verified by reading back.
+func FRUUpdate(c *Client, deviceID uint8, edit func(f *FRU) error) error {
+	info := &GetFRUInventoryAreaInfoCommand{DeviceID: deviceID}
+	if err := c.Execute(info); err != nil {
+		return err
+	}
+	old, err := fruRead(c, c.Execute, deviceID, info.AccessByWord, 0, int(info.AreaSize))
+	if err != nil {
+		return err
+	}
+
+	orig, f := &FRU{DeviceID: deviceID}, &FRU{DeviceID: deviceID}
+	if _, err := orig.Unmarshal(old); err != nil {
+		return err
+	}
+	if _, err := f.Unmarshal(old); err != nil {
+		return err
+	}
+	if err := edit(f); err != nil {
+		return err
+	}
+
+	b, err := fruMarshal(f, orig)
+	if err != nil {
+		return err
+	}
+	if len(b) > len(old) {
+		return &ArgumentError{
+			Value:   len(b),
+			Message: fmt.Sprintf("FRU information exceeds the inventory area size %d", len(old)),
+		}
+	}
+
+	// Bytes beyond the new areas are kept
+	image := make([]byte, len(old))
+	copy(image, old)
+	copy(image, b)
+
+	return fruWriteDiff(c, c.Execute, deviceID, info.AccessByWord, old, image)
+}
+
+// Writes the bytes of `image` which differ from `old` and verifies them by reading back
+func fruWriteDiff(c *Client, execute func(Command) error, deviceID uint8, byWord bool, old, image []byte) error {
+	size := newFRUTransferSize(fruDefaultWriteBytes, byWord)
+	first, last := -1, -1
+	for i := 0; i < len(image); {
+		if image[i] == old[i] {
+			i++
+			continue
+		}
+
+		start := i
+		if byWord {
+			start &^= 1
+		}
+		end := start + int(size.bytes)
+		if end > len(image) {
+			end = len(image)
+		}
+		if err := fruWrite(execute, deviceID, byWord, start, image[start:end], size); err != nil {
+			return err
+		}
+		if first < 0 {
+			first = start
+		}
+		last, i = end, end
+	}
+	if first < 0 {
+		return nil
+	}
+
+	b, err := fruRead(c, execute, deviceID, byWord, first, last-first)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(b, image[first:last]) {
+		return &MessageError{Message: fmt.Sprintf("FRU data verification failed at offset %d-%d", first, last)}
+	}
+	return nil
+}
+
+// Writes the data to the offset of the inventory area.
+// The bytes to write of each request are decreased by `size` to the upper limit that BMC can accept.
+func fruWrite(execute func(Command) error, deviceID uint8, byWord bool, offset int, data []byte, size *transferSize) error {
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > int(size.bytes) {
+			chunk = chunk[:size.bytes]
+		}
+		cmd := &WriteFRUDataCommand{DeviceID: deviceID, Offset: uint16(offset), Data: chunk}
+		if byWord {
+			cmd.Offset /= 2
+		}
+		if err := execute(cmd); err != nil {
+			if e, ok := err.(*CommandError); ok && (e.CompletionCode == CompletionRequestDataFieldExceedEd ||
+				e.CompletionCode == CompletionCantReturnDataBytes) {
+				if size.shrink() {
+					continue
+				}
+			}
+			return err
+		}
+
+		n := int(cmd.Count)
+		if byWord {
+			n *= 2
+		}
+		if n == 0 {
+			return &MessageError{Message: fmt.Sprintf("No FRU data written at offset %d", offset)}
+		}
+		if n > len(chunk) {
+			n = len(chunk)
+		}
+		offset, data = offset+n, data[n:]
+	}
+	return nil
+}
+
+// Sets the asset tag of the product info area.
+func FRUSetProductAssetTag(c *Client, deviceID uint8, tag string) error {
+	return FRUUpdate(c, deviceID, func(f *FRU) error {
+		if f.Product == nil {
+			return &MessageError{Message: "FRU has no Product Info Area"}
+		}
+		f.Product.AssetTag = tag
+		return nil
+	})
+}
+
+// Sets the serial number of the product info area.
+func FRUSetProductSerial(c *Client, deviceID uint8, serial string) error {
+	return FRUUpdate(c, deviceID, func(f *FRU) error {
+		if f.Product == nil {
+			return &MessageError{Message: "FRU has no Product Info Area"}
+		}
+		f.Product.SerialNumber = serial
+		return nil
+	})
+}
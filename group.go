@@ -0,0 +1,74 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Defining body code of the Group Extension NetFn (Section 5.1)
+type GroupID uint8
+
+const (
+	GroupPICMG GroupID = 0x00 // PCI Industrial Computer Manufacturers Group
+	GroupHPM1  GroupID = 0x00 // PICMG Hardware Platform Management (HPM.1), which is defined by PICMG
+	GroupDMTF  GroupID = 0x01 // DMTF Pre-OS Working Group ASF Specification
+	GroupSSI   GroupID = 0x02 // Server System Infrastructure Forum
+	GroupVITA  GroupID = 0x03 // VITA Standards Organization
+	GroupDCMI  GroupID = 0xdc // Data Center Manageability Interface
+)
+
+func (g GroupID) String() string {
+	switch g {
+	case GroupPICMG:
+		return "PICMG"
+	case GroupDMTF:
+		return "DMTF"
+	case GroupSSI:
+		return "SSI"
+	case GroupVITA:
+		return "VITA"
+	case GroupDCMI:
+		return "DCMI"
+	default:
+		return fmt.Sprintf("Unknown(0x%02x)", uint8(g))
+	}
+}
+
+// A GroupExtensionCommand is a command of the Group Extension NetFn.
+// The group ID is inserted before the request data, and the group ID echoed at the beginning of the
+// response data is validated and removed before the response is unmarshaled.
+type GroupExtensionCommand interface {
+	Command
+	GroupID() GroupID
+}
+
+// Returns the request data of the command
+func cmdMarshal(c Command) ([]byte, error) {
+	data, err := c.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if g, ok := c.(GroupExtensionCommand); ok {
+		data = append([]byte{uint8(g.GroupID())}, data...)
+	}
+	return data, nil
+}
+
+// Unmarshals the response data of the command
+func cmdUnmarshal(c Command, buf []byte) ([]byte, error) {
+	if g, ok := c.(GroupExtensionCommand); ok {
+		if len(buf) < 1 {
+			return nil, &MessageError{
+				Message: fmt.Sprintf("No group ID in %s Response", c.Name()),
+			}
+		}
+		if id := GroupID(buf[0]); id != g.GroupID() {
+			return nil, &MessageError{
+				Message: fmt.Sprintf("Mismatch group ID in %s Response : %s - %s", c.Name(), g.GroupID(), id),
+				Detail:  hex.EncodeToString(buf),
+			}
+		}
+		buf = buf[1:]
+	}
+	return c.Unmarshal(buf)
+}
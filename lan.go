@@ -1,12 +1,14 @@
 package ipmigo
 
 import (
+	"crypto/md5"
 	"encoding/binary"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"net"
+	"time"
 )
 
 const (
@@ -15,15 +17,17 @@ const (
 )
 
 type sessionHeaderV1_5 struct {
-	authType      authType
+	authType      AuthType
 	sequence      uint32
 	id            uint32
 	payloadLength uint8
 	authCode      [16]byte // Present when authentication type is not none
+	reveal        bool     // Shows the auth code in String()
 }
 
 func (s *sessionHeaderV1_5) ID() uint32               { return s.id }
-func (s *sessionHeaderV1_5) AuthType() authType       { return s.authType }
+func (s *sessionHeaderV1_5) Sequence() uint32         { return s.sequence }
+func (s *sessionHeaderV1_5) AuthType() AuthType       { return s.authType }
 func (s *sessionHeaderV1_5) PayloadType() payloadType { return payloadTypeIPMI }
 func (s *sessionHeaderV1_5) SetEncrypted(b bool)      { /* noop */ }
 func (s *sessionHeaderV1_5) SetAuthenticated(b bool)  { /* noop */ }
@@ -32,7 +36,7 @@ func (s *sessionHeaderV1_5) SetPayloadLength(n int)   { s.payloadLength = uint8(
 
 func (s *sessionHeaderV1_5) Marshal() ([]byte, error) {
 	var buf []byte
-	if s.authType == authTypeNone {
+	if s.authType == AuthTypeNone {
 		buf = make([]byte, sessionHeaderV1_5Size)
 	} else {
 		buf = make([]byte, sessionHeaderV1_5SizeWithAuth)
@@ -49,11 +53,11 @@ func (s *sessionHeaderV1_5) Unmarshal(buf []byte) ([]byte, error) {
 	if len(buf) < sessionHeaderV1_5Size {
 		goto ERROR
 	}
-	s.authType = authType(buf[0])
+	s.authType = AuthType(buf[0])
 	s.sequence = binary.LittleEndian.Uint32(buf[1:])
 	s.id = binary.LittleEndian.Uint32(buf[5:])
 
-	if s.authType == authTypeNone {
+	if s.authType == AuthTypeNone {
 		s.payloadLength = buf[sessionHeaderV1_5Size-1]
 		return buf[sessionHeaderV1_5Size:], nil
 	}
@@ -72,16 +76,22 @@ ERROR:
 
 func (s *sessionHeaderV1_5) String() string {
 	return fmt.Sprintf(`{"AuthType":"%s","Sequence":%d,"ID":%d,"PayloadLength":%d,"AuthCode":"%s"}`,
-		s.authType, s.sequence, s.id, s.payloadLength, hex.EncodeToString(s.authCode[:]))
+		s.authType, s.sequence, s.id, s.payloadLength, secretString(s.authCode[:], s.reveal))
 }
 
 type sessionV1_5 struct {
 	conn     net.Conn
 	args     *Arguments
-	authType authType
+	address  string // Last working address
+	authType AuthType
 	id       uint32 // Session ID
 	sequence uint32 // Session Sequence Number
 	rqSeq    uint8  // Command Sequence Number
+
+	tempID         uint32         // Temporary Session ID for activating
+	privilegeLevel PrivilegeLevel // Privilege level granted to the session
+
+	recvBuf []byte // Receive buffer reused by the session
 }
 
 func (s *sessionV1_5) ActiveSession() bool {
@@ -93,22 +103,29 @@ func (s *sessionV1_5) Header() sessionHeader {
 		authType: s.authType,
 		sequence: s.NextSequence(),
 		id:       s.id,
+		reveal:   s.args.RevealSecrets,
 	}
-	if s.authType != authTypeNone {
-		copy(hdr.authCode[:], []byte(s.args.Password))
+	if !s.ActiveSession() && s.tempID != 0 {
+		// Activate Session request uses the temporary session ID
+		hdr.id = s.tempID
 	}
 
 	return hdr
 }
 
-func (s *sessionV1_5) Ping() error {
-	conn, err := net.DialTimeout(s.args.Network, s.args.Address, s.args.Timeout)
+func (s *sessionV1_5) Ping() (*Pong, error) {
+	// Uses the connection of the session if it is open
+	if s.conn != nil {
+		return ping(s.conn, s.args, nil)
+	}
+
+	conn, err := dial(s.args, currentAddress(s.args, s.address))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer conn.Close()
 
-	return ping(conn, s.args.Timeout)
+	return ping(conn, s.args, nil)
 }
 
 func (s *sessionV1_5) Open() error {
@@ -116,28 +133,31 @@ func (s *sessionV1_5) Open() error {
 		return nil
 	}
 
-	err := retry(int(s.args.Retries), func() error {
-		conn, e := net.DialTimeout(s.args.Network, s.args.Address, s.args.Timeout)
-		if e == nil {
-			s.conn = conn
+	return openFailover(s.args, &s.address, func(addr string) error {
+		err := retry(int(s.args.Retries), &s.args.Backoff, func() error {
+			conn, e := dial(s.args, addr)
+			if e == nil {
+				s.conn = conn
+			}
+			return e
+		})
+		if err != nil {
+			return err
 		}
-		return e
-	})
-	if err != nil {
-		return err
-	}
 
-	err = s.openSession()
-	if err != nil {
-		defer s.Close()
-	}
-	return err
+		err = s.openSession()
+		if err != nil {
+			defer s.Close()
+		}
+		return err
+	})
 }
 
 func (s *sessionV1_5) openSession() error {
 	// 1. RMCP Presence Ping
-	err := retry(int(s.args.Retries), func() error {
-		return ping(s.conn, s.args.Timeout)
+	err := retry(int(s.args.Retries), &s.args.Backoff, func() error {
+		_, e := ping(s.conn, s.args, nil)
+		return e
 	})
 	if err != nil {
 		return err
@@ -145,16 +165,23 @@ func (s *sessionV1_5) openSession() error {
 
 	// 2. Get Channel Authentication Capabilities
 	cac := newChannelAuthCapCommand(V1_5, s.args.PrivilegeLevel)
-	if _, err := s.execute(cac); err != nil {
+	if _, err := s.execute(cac, s.args.options()); err != nil {
 		return err
 	}
 
-	for _, t := range []authType{authTypeMD5, authTypePassword, authTypeNone} {
+	authType := AuthTypeNone
+	for i, t := range s.args.AuthTypes {
 		if cac.IsSupportedAuthType(t) {
-			s.authType = t
+			authType = t
 			break
 		}
-		if t == authTypeNone {
+		if len(s.args.AuthTypes) == 1 {
+			return &MessageError{
+				Message: fmt.Sprintf("Authentication type %s is not supported by BMC", t),
+				Detail:  cac.String(),
+			}
+		}
+		if i == len(s.args.AuthTypes)-1 {
 			return &MessageError{
 				Message: "No supported authentication types found",
 				Detail:  cac.String(),
@@ -163,82 +190,156 @@ func (s *sessionV1_5) openSession() error {
 	}
 
 	// 3. Get Session Challenge
+	gsc := &getSessionChallengeCommand{AuthType: authType, Username: s.args.Username}
+	if _, err := s.execute(gsc, s.args.options()); err != nil {
+		return err
+	}
 
 	// 4. Activate Session
+	s.authType = authType
+	s.tempID = gsc.TemporaryID
+	as := &activateSessionCommand{
+		AuthType:         authType,
+		PrivilegeLevel:   s.args.PrivilegeLevel,
+		Challenge:        gsc.Challenge,
+		OutboundSequence: rand.Uint32() | 1,
+	}
+	_, err = s.execute(as, s.args.options())
+	s.tempID = 0
+	if err != nil {
+		return err
+	}
+
+	s.id = as.SessionID
+	dispatchSetSessionID(s.conn, s.id)
+	s.sequence = as.InboundSequence - 1 // NextSequence returns the initial one
+	s.authType = as.ResAuthType
+	s.privilegeLevel = PrivilegeUser
 
-	// TODO
-	return errors.New("Not implemented yet")
+	// 5. Set session privilege level
+	if l := s.args.PrivilegeLevel; l > PrivilegeUser {
+		cmd := newSetSessionPrivilegeCommand(l)
+		if _, err := s.execute(cmd, s.args.options()); err != nil {
+			return &MessageError{
+				Cause:   err,
+				Message: fmt.Sprintf("Unable to set session privilege level to %s", l),
+			}
+		}
+		s.privilegeLevel = cmd.NewLevel
+	}
+
+	return nil
 }
 
-func (s *sessionV1_5) Close() error {
+// Closes the session and the connection. The state is reset even if BMC fails to close the session
+// (e.g. it has been expired), and the error is returned after that.
+func (s *sessionV1_5) Close() (err error) {
 	if s.ActiveSession() {
+		err = s.Execute(newCloseSessionCommand(s.id), s.args.options())
+
 		s.id = 0
+		s.tempID = 0
 		s.sequence = 0
 		s.rqSeq = 0
-		s.authType = authTypeNone
+		s.authType = AuthTypeNone
+		s.privilegeLevel = 0
 	}
 
 	if c := s.conn; c != nil {
-		if err := c.Close(); err != nil {
-			return err
+		if e := c.Close(); e != nil && err == nil {
+			err = e
 		}
 		s.conn = nil
 	}
-	return nil
+	return err
 }
 
-func (s *sessionV1_5) Execute(cmd Command) error {
+func (s *sessionV1_5) Execute(cmd Command, opts Options) error {
 	if err := s.Open(); err != nil {
 		return err
 	}
 
-	if _, err := s.execute(cmd); err != nil {
+	if _, err := s.execute(cmd, opts); err != nil {
 		return err
 	}
+	if c, ok := cmd.(*SetSessionPrivilegeCommand); ok {
+		s.privilegeLevel = c.NewLevel
+	}
 	return nil
 }
 
-func (s *sessionV1_5) execute(cmd Command) (response, error) {
+func (s *sessionV1_5) execute(cmd Command, opts Options) (response, error) {
 	var res *ipmiPacket
-	err := retry(int(s.args.Retries), func() (e error) {
+	var rqm *ipmiRequestMessage
+	err := retry(opts.Retries, &s.args.Backoff, func() (e error) {
+		rqm = &ipmiRequestMessage{
+			RsAddr:  bmcSlaveAddress,
+			RqAddr:  remoteSWID,
+			RqSeq:   s.NextRqSeq(),
+			Command: cmd,
+		}
 		req := &ipmiPacket{
 			RMCPHeader:    newRMCPHeaderForIPMI(),
 			SessionHeader: s.Header(),
-			Request: &ipmiRequestMessage{
-				RsAddr:  bmcSlaveAddress,
-				RqAddr:  remoteSWID,
-				RqSeq:   s.NextRqSeq(),
-				Command: cmd,
-			},
+			Request:       rqm,
+		}
+		deadline := time.Now().Add(opts.Timeout)
+		if res, e = s.SendPacket(req, opts.Timeout); e == nil {
+			res, e = awaitResponse(rqm, res, deadline, s.RecvPacket)
 		}
-		res, e = s.SendPacket(req)
 		return
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	rsm, ok := res.Response.(*ipmiResponseMessage)
-	if !ok {
-		return nil, &MessageError{
-			Message: "Received an unexpected message (Command)",
-			Detail:  res.String(),
-		}
+	rsm, err := commandResponse(res, cmd)
+	if err != nil {
+		return nil, err
 	}
 
-	if rsm.CompletionCode != CompletionOK {
-		return nil, &CommandError{
-			CompletionCode: rsm.CompletionCode,
-			Command:        cmd,
+	deadline := time.Now().Add(opts.Timeout)
+	for isBridgeAcknowledge(cmd, rsm) {
+		// Wait for the response of the bridged request
+		if res, err = awaitResponse(rqm, nil, deadline, s.RecvPacket); err != nil {
+			return nil, err
+		}
+		if rsm, err = commandResponse(res, cmd); err != nil {
+			return nil, err
 		}
 	}
-	if _, err = cmd.Unmarshal(rsm.Data); err != nil {
+
+	if _, err = cmdUnmarshal(cmd, rsm.Data); err != nil {
 		return nil, err
 	}
 
 	return res, nil
 }
 
+func (s *sessionV1_5) executePipelined(cmds []Command, opts Options) []error {
+	if err := s.Open(); err != nil {
+		errs := make([]error, len(cmds))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	newRequest := func(cmd Command) *ipmiPacket {
+		return &ipmiPacket{
+			RMCPHeader:    newRMCPHeaderForIPMI(),
+			SessionHeader: s.Header(),
+			Request: &ipmiRequestMessage{
+				RsAddr:  bmcSlaveAddress,
+				RqAddr:  remoteSWID,
+				RqSeq:   s.NextRqSeq(),
+				Command: cmd,
+			},
+		}
+	}
+	return executePipelined(cmds, opts, newRequest, s.writePacket, s.RecvPacket)
+}
+
 func (s *sessionV1_5) NextSequence() uint32 {
 	if s.ActiveSession() {
 		switch s.sequence {
@@ -261,18 +362,38 @@ func (s *sessionV1_5) NextRqSeq() uint8 {
 	return n << 2
 }
 
-func (s *sessionV1_5) SendPacket(req *ipmiPacket) (*ipmiPacket, error) {
+func (s *sessionV1_5) SendPacket(req *ipmiPacket, timeout time.Duration) (*ipmiPacket, error) {
+	if err := s.writePacket(req, timeout); err != nil {
+		return nil, err
+	}
+	return s.RecvPacket(timeout)
+}
+
+func (s *sessionV1_5) writePacket(req *ipmiPacket, timeout time.Duration) error {
 	if buf, err := req.Request.Marshal(); err == nil {
 		req.PayloadBytes = buf
 		req.SessionHeader.SetPayloadLength(len(buf))
+		s.args.Trace.trace(TraceSendPayload, buf, req.Request)
 	} else {
-		return nil, err
+		return err
+	}
+
+	if hdr, ok := req.SessionHeader.(*sessionHeaderV1_5); ok && hdr.authType != AuthTypeNone {
+		hdr.authCode = authCodeV1_5(hdr.authType, s.args.Password, hdr.id, hdr.sequence, req.PayloadBytes)
 	}
 
-	res, _, err := sendMessage(s.conn, req, s.args.Timeout)
+	return writeMessage(s.conn, req, timeout, s.args.Trace)
+}
+
+func (s *sessionV1_5) RecvPacket(timeout time.Duration) (*ipmiPacket, error) {
+	res, _, err := recvMessage(s.conn, s.recvBuf, timeout, s.args)
 	if err != nil {
 		return nil, err
 	}
+	return s.readPacket(res)
+}
+
+func (s *sessionV1_5) readPacket(res response) (*ipmiPacket, error) {
 	pkt, ok := res.(*ipmiPacket)
 	if !ok {
 		return nil, &MessageError{
@@ -285,10 +406,21 @@ func (s *sessionV1_5) SendPacket(req *ipmiPacket) (*ipmiPacket, error) {
 	if _, err := pkt.Response.Unmarshal(pkt.PayloadBytes); err != nil {
 		return nil, err
 	}
+	s.args.Trace.trace(TraceRecvPayload, pkt.PayloadBytes, pkt.Response)
 
 	return pkt, nil
 }
 
+func (s *sessionV1_5) Info() SessionInfo {
+	return SessionInfo{
+		Open:           s.ActiveSession(),
+		ID:             s.id,
+		Address:        currentAddress(s.args, s.address),
+		PrivilegeLevel: s.privilegeLevel,
+		AuthType:       s.authType,
+	}
+}
+
 func (s *sessionV1_5) String() string {
 	return fmt.Sprintf(`{ID:%d,"Sequence":%d,"RqSeq":%d,"AuthType":"%s"}`,
 		s.id, s.sequence, s.rqSeq, s.authType)
@@ -296,6 +428,32 @@ func (s *sessionV1_5) String() string {
 
 func newSessionV1_5(args *Arguments) session {
 	return &sessionV1_5{
-		args: args,
+		args:    args,
+		recvBuf: make([]byte, recvBufferSize),
+	}
+}
+
+// Section 22.17.1
+func authCodeV1_5(t AuthType, password string, id, seq uint32, data []byte) (code [16]byte) {
+	key := make([]byte, passwordMaxLengthV1_5)
+	copy(key, password)
+
+	switch t {
+	case AuthTypePassword:
+		copy(code[:], key)
+	case AuthTypeMD2, AuthTypeMD5:
+		// H(password + session ID + IPMI message data + session sequence number + password)
+		buf := make([]byte, 0, len(key)*2+len(data)+8)
+		buf = append(buf, key...)
+		buf = append(buf, byte(id), byte(id>>8), byte(id>>16), byte(id>>24))
+		buf = append(buf, data...)
+		buf = append(buf, byte(seq), byte(seq>>8), byte(seq>>16), byte(seq>>24))
+		buf = append(buf, key...)
+		if t == AuthTypeMD2 {
+			code = md2Sum(buf)
+		} else {
+			code = md5.Sum(buf)
+		}
 	}
+	return
 }
@@ -1,35 +1,41 @@
 package ipmigo
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/hmac"
+	"crypto/md5"
 	"crypto/rand"
-	"crypto/sha1"
+	"crypto/rc4"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math"
 	"net"
+	"time"
 )
 
 const (
-	consoleID uint32 = 0x49504d49 // 'IPMI'
+	defaultConsoleID uint32 = 0x49504d49 // 'IPMI'
 
-	sessionHeaderV2_0Size = 12 // When payload type is not OEM
+	sessionHeaderV2_0Size    = 12 // When payload type is not OEM
+	sessionHeaderV2_0OEMSize = 18 // When payload type is OEM explicit
+	sequenceWindowSize       = 32 // Sliding window of inbound session sequence number (Section 6.12.13)
 )
 
 type sessionHeaderV2_0 struct {
-	authType      authType
+	authType      AuthType
 	payloadType   payloadType
 	id            uint32
 	sequence      uint32
 	payloadLength uint16
+	oemIANA       uint32 // Present when payload type is OEM explicit
+	oemPayloadID  uint16 // Present when payload type is OEM explicit
 }
 
 func (s *sessionHeaderV2_0) ID() uint32               { return s.id }
-func (s *sessionHeaderV2_0) AuthType() authType       { return s.authType }
+func (s *sessionHeaderV2_0) Sequence() uint32         { return s.sequence }
+func (s *sessionHeaderV2_0) AuthType() AuthType       { return s.authType }
 func (s *sessionHeaderV2_0) PayloadType() payloadType { return s.payloadType }
 func (s *sessionHeaderV2_0) SetEncrypted(b bool)      { s.payloadType.SetEncrypted(b) }
 func (s *sessionHeaderV2_0) SetAuthenticated(b bool)  { s.payloadType.SetAuthenticated(b) }
@@ -37,24 +43,46 @@ func (s *sessionHeaderV2_0) PayloadLength() int       { return int(s.payloadLeng
 func (s *sessionHeaderV2_0) SetPayloadLength(n int)   { s.payloadLength = uint16(n) }
 
 func (s *sessionHeaderV2_0) Marshal() ([]byte, error) {
-	buf := make([]byte, sessionHeaderV2_0Size)
+	if s.payloadType.Pure() != payloadTypeOEM {
+		buf := make([]byte, sessionHeaderV2_0Size)
+		buf[0] = byte(s.authType)
+		buf[1] = byte(s.payloadType)
+		binary.LittleEndian.PutUint32(buf[2:], s.id)
+		binary.LittleEndian.PutUint32(buf[6:], s.sequence)
+		binary.LittleEndian.PutUint16(buf[10:], s.payloadLength)
+		return buf, nil
+	}
+
+	buf := make([]byte, sessionHeaderV2_0OEMSize)
 	buf[0] = byte(s.authType)
 	buf[1] = byte(s.payloadType)
-	binary.LittleEndian.PutUint32(buf[2:], s.id)
-	binary.LittleEndian.PutUint32(buf[6:], s.sequence)
-	binary.LittleEndian.PutUint16(buf[10:], s.payloadLength)
+	binary.LittleEndian.PutUint32(buf[2:], s.oemIANA&0xffffff)
+	binary.LittleEndian.PutUint16(buf[6:], s.oemPayloadID)
+	binary.LittleEndian.PutUint32(buf[8:], s.id)
+	binary.LittleEndian.PutUint32(buf[12:], s.sequence)
+	binary.LittleEndian.PutUint16(buf[16:], s.payloadLength)
 	return buf, nil
 }
 
 func (s *sessionHeaderV2_0) Unmarshal(buf []byte) ([]byte, error) {
-	if len(buf) < sessionHeaderV2_0Size {
+	size := sessionHeaderV2_0Size
+	if len(buf) > 1 && payloadType(buf[1]).Pure() == payloadTypeOEM {
+		size = sessionHeaderV2_0OEMSize
+	}
+	if len(buf) < size {
 		return nil, &MessageError{
 			Message: fmt.Sprintf("Invalid IPMI 2.0 session header size : %d", len(buf)),
 			Detail:  hex.EncodeToString(buf),
 		}
 	}
-	s.authType = authType(buf[0])
+
+	s.authType = AuthType(buf[0])
 	s.payloadType = payloadType(buf[1])
+	if size == sessionHeaderV2_0OEMSize {
+		s.oemIANA = binary.LittleEndian.Uint32(buf[2:]) & 0xffffff
+		s.oemPayloadID = binary.LittleEndian.Uint16(buf[6:])
+		buf = buf[6:]
+	}
 	s.id = binary.LittleEndian.Uint32(buf[2:])
 	s.sequence = binary.LittleEndian.Uint32(buf[6:])
 	s.payloadLength = binary.LittleEndian.Uint16(buf[10:])
@@ -69,11 +97,30 @@ func (s *sessionHeaderV2_0) String() string {
 type sessionV2_0 struct {
 	conn     net.Conn
 	args     *Arguments
+	address  string // Last working address
 	id       uint32 // Session ID
 	sequence uint32 // Session Sequence Number
 	rqSeq    uint8  // Command Sequence Number
 	k1       []byte // Integrity Key
 	k2       []byte // Cipher Key
+
+	cipherSuiteID  uint           // ID of negotiated cipher suite
+	privilegeLevel PrivilegeLevel // Privilege level granted to the session
+
+	rc4In       *rc4.Cipher // Inbound xRC4 key stream
+	rc4InOffset uint32      // Data offset of the inbound key stream
+	messageTag  uint8       // Message Tag of RMCP+ session setup
+
+	inSequence uint32 // Highest inbound Session Sequence Number
+	inReceived uint32 // Bitmap of received sequence numbers in the window
+
+	console *SOLConsole // Activated SOL console which receives the SOL payload read by any reader
+	recvBuf []byte      // Receive buffer reused by the session
+}
+
+// Returns the remote console session ID
+func (s *sessionV2_0) consoleID() uint32 {
+	return dispatchConsoleID(s.conn)
 }
 
 func (s *sessionV2_0) ActiveSession() bool {
@@ -82,21 +129,31 @@ func (s *sessionV2_0) ActiveSession() bool {
 
 func (s *sessionV2_0) Header(p payloadType) sessionHeader {
 	return &sessionHeaderV2_0{
-		authType:    authTypeRMCPPlus,
+		authType:    AuthTypeRMCPPlus,
 		id:          s.id,
 		sequence:    s.NextSequence(),
 		payloadType: p,
 	}
 }
 
-func (s *sessionV2_0) Ping() error {
-	conn, err := net.DialTimeout(s.args.Network, s.args.Address, s.args.Timeout)
+func (s *sessionV2_0) Ping() (*Pong, error) {
+	// Uses the connection of the session if it is open
+	if s.conn != nil {
+		return ping(s.conn, s.args, func(res response, msg []byte) {
+			if s.console != nil {
+				// Hands the SOL payload to the console
+				s.readPacket(res, msg)
+			}
+		})
+	}
+
+	conn, err := dial(s.args, currentAddress(s.args, s.address))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer conn.Close()
 
-	return ping(conn, s.args.Timeout)
+	return ping(conn, s.args, nil)
 }
 
 func (s *sessionV2_0) Open() error {
@@ -104,22 +161,24 @@ func (s *sessionV2_0) Open() error {
 		return nil
 	}
 
-	err := retry(int(s.args.Retries), func() error {
-		conn, e := net.DialTimeout(s.args.Network, s.args.Address, s.args.Timeout)
-		if e == nil {
-			s.conn = conn
+	return openFailover(s.args, &s.address, func(addr string) error {
+		err := retry(int(s.args.Retries), &s.args.Backoff, func() error {
+			conn, e := dial(s.args, addr)
+			if e == nil {
+				s.conn = conn
+			}
+			return e
+		})
+		if err != nil {
+			return err
 		}
-		return e
-	})
-	if err != nil {
-		return err
-	}
 
-	err = s.openSession()
-	if err != nil {
-		defer s.Close()
-	}
-	return err
+		err = s.openSession()
+		if err != nil {
+			defer s.Close()
+		}
+		return err
+	})
 }
 
 func (s *sessionV2_0) openSession() error {
@@ -128,21 +187,40 @@ func (s *sessionV2_0) openSession() error {
 	// Send in 1.5 packet format to query any server
 	s1 := &sessionV1_5{args: s.args, conn: s.conn}
 	cac := newChannelAuthCapCommand(V2_0, s.args.PrivilegeLevel)
-	if _, err := s1.execute(cac); err != nil {
+	if _, err := s1.execute(cac, s.args.options()); err != nil {
 		// Retry, without requesting IPMI V2
 		cac = newChannelAuthCapCommand(V1_5, s.args.PrivilegeLevel)
-		if _, err := s1.execute(cac); err != nil {
+		if _, err := s1.execute(cac, s.args.options()); err != nil {
 			return err
 		}
 	}
 
-	if !cac.IsSupportedAuthType(authTypeRMCPPlus) {
+	if !cac.IsSupportedAuthType(AuthTypeRMCPPlus) {
 		return &MessageError{
 			Message: "Not Support RMCP+",
 			Detail:  cac.String(),
 		}
 	}
 
+	// Select the cipher suite
+	s.cipherSuiteID = s.args.CipherSuiteID
+	if s.cipherSuiteID == CipherSuiteIDAuto {
+		ids, err := getCipherSuiteIDs(func(c Command) error {
+			_, e := s1.execute(c, s.args.options())
+			return e
+		})
+		if err != nil {
+			return err
+		}
+		if id, ok := selectCipherSuiteID(ids); ok {
+			s.cipherSuiteID = id
+		} else {
+			return &MessageError{
+				Message: fmt.Sprintf("No supported cipher suites found : %v", ids),
+			}
+		}
+	}
+
 	// 2. Open Session Request
 	priv := s.args.PrivilegeLevel
 	if priv == PrivilegeAdministrator {
@@ -151,17 +229,14 @@ func (s *sessionV2_0) openSession() error {
 	}
 
 	var pkt *ipmiPacket
-	err := retry(int(s.args.Retries), func() (e error) {
-		req := &ipmiPacket{
-			RMCPHeader:    newRMCPHeaderForIPMI(),
-			SessionHeader: s.Header(payloadTypeRMCPOpenReq),
-			Request: &openSessionRequest{
-				ConsoleID:      consoleID,
-				PrivilegeLevel: priv,
-				CipherSuiteID:  s.args.CipherSuiteID,
-			},
-		}
-		pkt, e = s.SendPacket(req)
+	err := retry(int(s.args.Retries), &s.args.Backoff, func() (e error) {
+		req := &openSessionRequest{
+			MessageTag:     s.NextMessageTag(),
+			ConsoleID:      s.consoleID(),
+			PrivilegeLevel: priv,
+			CipherSuiteID:  s.cipherSuiteID,
+		}
+		pkt, e = s.exchange(payloadTypeRMCPOpenReq, req, req.MessageTag)
 		return
 	})
 	if err != nil {
@@ -181,14 +256,14 @@ func (s *sessionV2_0) openSession() error {
 			Detail:  pkt.String(),
 		}
 	}
-	if consoleID != osr.ConsoleID {
+	if s.consoleID() != osr.ConsoleID {
 		return &MessageError{
 			Message: fmt.Sprintf("Mismatch console session ID in Open Session Response : 0x%x - 0x%x",
-				consoleID, osr.ConsoleID),
+				s.consoleID(), osr.ConsoleID),
 			Detail: pkt.String(),
 		}
 	}
-	if reqSuite := cipherSuiteIDs[s.args.CipherSuiteID]; !reqSuite.Equal(&osr.CipherSuite) {
+	if reqSuite := cipherSuiteIDs[s.cipherSuiteID]; !reqSuite.Equal(&osr.CipherSuite) {
 		return &MessageError{
 			Message: fmt.Sprintf("Mismatch cipher suite : %s - %s", reqSuite, osr.CipherSuite),
 			Detail:  pkt.String(),
@@ -199,17 +274,13 @@ func (s *sessionV2_0) openSession() error {
 	r1 := &rakpMessage1{
 		ManagedID:       osr.ManagedID,
 		PrivilegeLevel:  s.args.PrivilegeLevel,
-		PrivilegeLookup: false,
+		PrivilegeLookup: s.args.PrivilegeLookup,
 		Username:        s.args.Username,
 	}
 
-	err = retry(int(s.args.Retries), func() (e error) {
-		req := &ipmiPacket{
-			RMCPHeader:    newRMCPHeaderForIPMI(),
-			SessionHeader: s.Header(payloadTypeRAKP1),
-			Request:       r1,
-		}
-		pkt, e = s.SendPacket(req)
+	err = retry(int(s.args.Retries), &s.args.Backoff, func() (e error) {
+		r1.MessageTag = s.NextMessageTag()
+		pkt, e = s.exchange(payloadTypeRAKP1, r1, r1.MessageTag)
 		return
 	})
 	if err != nil {
@@ -229,13 +300,13 @@ func (s *sessionV2_0) openSession() error {
 			Detail:  pkt.String(),
 		}
 	}
-	if consoleID != r2.ConsoleID {
+	if s.consoleID() != r2.ConsoleID {
 		return &MessageError{
-			Message: fmt.Sprintf("Mismatch console session ID in RAKP 2 : 0x%x - 0x%x", consoleID, r2.ConsoleID),
+			Message: fmt.Sprintf("Mismatch console session ID in RAKP 2 : 0x%x - 0x%x", s.consoleID(), r2.ConsoleID),
 			Detail:  pkt.String(),
 		}
 	}
-	if err = r2.ValidateAuthCode(s.args, r1); err != nil {
+	if err = r2.ValidateAuthCode(s.cipherSuiteID, s.args.Password, r1); err != nil {
 		return err
 	}
 
@@ -243,19 +314,16 @@ func (s *sessionV2_0) openSession() error {
 	r3 := &rakpMessage3{
 		StatusCode: rakpStatusNoErrors,
 		ManagedID:  osr.ManagedID,
+		reveal:     s.args.RevealSecrets,
 	}
-	r3.GenerateAuthCode(s.args, r1, r2)
-	r3.GenerateSIK(s.args, r1, r2)
-	r3.GenerateK1(s.args)
-	r3.GenerateK2(s.args)
+	r3.GenerateAuthCode(s.cipherSuiteID, s.args.Password, r1, r2)
+	r3.GenerateSIK(s.cipherSuiteID, s.args.Password, r1, r2)
+	r3.GenerateK1(s.cipherSuiteID)
+	r3.GenerateK2(s.cipherSuiteID)
 
-	err = retry(int(s.args.Retries), func() (e error) {
-		req := &ipmiPacket{
-			RMCPHeader:    newRMCPHeaderForIPMI(),
-			SessionHeader: s.Header(payloadTypeRAKP3),
-			Request:       r3,
-		}
-		pkt, e = s.SendPacket(req)
+	err = retry(int(s.args.Retries), &s.args.Backoff, func() (e error) {
+		r3.MessageTag = s.NextMessageTag()
+		pkt, e = s.exchange(payloadTypeRAKP3, r3, r3.MessageTag)
 		return
 	})
 	if err != nil {
@@ -275,109 +343,181 @@ func (s *sessionV2_0) openSession() error {
 			Detail:  pkt.String(),
 		}
 	}
-	if consoleID != r4.ConsoleID {
+	if s.consoleID() != r4.ConsoleID {
 		return &MessageError{
-			Message: fmt.Sprintf("Mismatch console session ID in RAKP 4 : 0x%x - 0x%x", consoleID, r4.ConsoleID),
+			Message: fmt.Sprintf("Mismatch console session ID in RAKP 4 : 0x%x - 0x%x", s.consoleID(), r4.ConsoleID),
 			Detail:  pkt.String(),
 		}
 	}
-	if err = r4.ValidateAuthCode(s.args, r1, r2, r3); err != nil {
+	if err = r4.ValidateAuthCode(s.cipherSuiteID, r1, r2, r3); err != nil {
 		return err
 	}
 
 	// Set session ID
 	s.id = osr.ManagedID
-	s.k1 = r3.K1[:]
-	s.k2 = r3.K2[:]
+	s.k1 = r3.K1
+	s.k2 = r3.K2
+
+	// Sessions start at User level or the maximum level if it is lower (Section 13.17)
+	s.privilegeLevel = PrivilegeUser
+	if osr.PrivilegeLevel > 0 && osr.PrivilegeLevel < PrivilegeUser {
+		s.privilegeLevel = osr.PrivilegeLevel
+	}
 
 	// Set session privilege level
 	if l := s.args.PrivilegeLevel; l > PrivilegeUser {
-		if _, err := s.execute(newSetSessionPrivilegeCommand(l)); err != nil {
+		cmd := newSetSessionPrivilegeCommand(l)
+		if _, err := s.execute(cmd, s.args.options()); err != nil {
 			return &MessageError{
 				Cause:   err,
 				Message: fmt.Sprintf("Unable to set session privilege level to %s", l),
 			}
 		}
+		s.privilegeLevel = cmd.NewLevel
 	}
 
 	return nil
 }
 
-func (s *sessionV2_0) Close() error {
-	if s.ActiveSession() {
-		if err := s.Execute(newCloseSessionCommand(s.id)); err != nil {
-			return err
+// Sends the session setup message and receives the response which has the same message tag
+func (s *sessionV2_0) exchange(p payloadType, req request, tag uint8) (*ipmiPacket, error) {
+	pkt := &ipmiPacket{
+		RMCPHeader:    newRMCPHeaderForIPMI(),
+		SessionHeader: s.Header(p),
+		Request:       req,
+	}
+
+	deadline := time.Now().Add(s.args.Timeout)
+	res, err := s.SendPacket(pkt, s.args.Timeout)
+	for err == nil {
+		if t, ok := responseMessageTag(res.Response); !ok || t == tag {
+			return res, nil
 		}
 
+		// Discard the response to a previous attempt
+		timeout := deadline.Sub(time.Now())
+		if timeout <= 0 {
+			return nil, errResponseTimeout
+		}
+		res, err = s.RecvPacket(timeout)
+	}
+	return nil, err
+}
+
+// Closes the session and the connection. The state is reset even if BMC fails to close the session
+// (e.g. it has been expired), and the error is returned after that.
+func (s *sessionV2_0) Close() (err error) {
+	if s.ActiveSession() {
+		err = s.Execute(newCloseSessionCommand(s.id), s.args.options())
+
 		s.id = 0
 		s.sequence = 0
 		s.rqSeq = 0
 		s.k1 = nil
 		s.k2 = nil
+		s.inSequence = 0
+		s.inReceived = 0
+		s.privilegeLevel = 0
+		s.rc4In = nil
+		s.rc4InOffset = 0
 	}
 
 	if c := s.conn; c != nil {
-		if err := c.Close(); err != nil {
-			return err
+		if e := c.Close(); e != nil && err == nil {
+			err = e
 		}
 		s.conn = nil
 	}
 
-	return nil
+	return err
 }
 
-func (s *sessionV2_0) Execute(cmd Command) error {
+func (s *sessionV2_0) Execute(cmd Command, opts Options) error {
 	if err := s.Open(); err != nil {
 		return err
 	}
 
-	if _, err := s.execute(cmd); err != nil {
+	if _, err := s.execute(cmd, opts); err != nil {
 		return err
 	}
+	if c, ok := cmd.(*SetSessionPrivilegeCommand); ok {
+		s.privilegeLevel = c.NewLevel
+	}
 	return nil
 }
 
-func (s *sessionV2_0) execute(cmd Command) (response, error) {
+func (s *sessionV2_0) execute(cmd Command, opts Options) (response, error) {
 	var res *ipmiPacket
-	err := retry(int(s.args.Retries), func() (e error) {
+	var rqm *ipmiRequestMessage
+	err := retry(opts.Retries, &s.args.Backoff, func() (e error) {
+		rqm = &ipmiRequestMessage{
+			RsAddr:  bmcSlaveAddress,
+			RqAddr:  remoteSWID,
+			RqSeq:   s.NextRqSeq(),
+			Command: cmd,
+		}
 		req := &ipmiPacket{
 			RMCPHeader:    newRMCPHeaderForIPMI(),
 			SessionHeader: s.Header(payloadTypeIPMI),
-			Request: &ipmiRequestMessage{
-				RsAddr:  bmcSlaveAddress,
-				RqAddr:  remoteSWID,
-				RqSeq:   s.NextRqSeq(),
-				Command: cmd,
-			},
+			Request:       rqm,
+		}
+		deadline := time.Now().Add(opts.Timeout)
+		if res, e = s.SendPacket(req, opts.Timeout); e == nil {
+			res, e = awaitResponse(rqm, res, deadline, s.RecvPacket)
 		}
-		res, e = s.SendPacket(req)
 		return
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	rsm, ok := res.Response.(*ipmiResponseMessage)
-	if !ok {
-		return nil, &MessageError{
-			Message: "Received an unexpected message (Command)",
-			Detail:  res.String(),
-		}
+	rsm, err := commandResponse(res, cmd)
+	if err != nil {
+		return nil, err
 	}
 
-	if rsm.CompletionCode != CompletionOK {
-		return nil, &CommandError{
-			CompletionCode: rsm.CompletionCode,
-			Command:        cmd,
+	deadline := time.Now().Add(opts.Timeout)
+	for isBridgeAcknowledge(cmd, rsm) {
+		// Wait for the response of the bridged request
+		if res, err = awaitResponse(rqm, nil, deadline, s.RecvPacket); err != nil {
+			return nil, err
+		}
+		if rsm, err = commandResponse(res, cmd); err != nil {
+			return nil, err
 		}
 	}
-	if _, err = cmd.Unmarshal(rsm.Data); err != nil {
+
+	if _, err = cmdUnmarshal(cmd, rsm.Data); err != nil {
 		return nil, err
 	}
 
 	return res, nil
 }
 
+func (s *sessionV2_0) executePipelined(cmds []Command, opts Options) []error {
+	if err := s.Open(); err != nil {
+		errs := make([]error, len(cmds))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	newRequest := func(cmd Command) *ipmiPacket {
+		return &ipmiPacket{
+			RMCPHeader:    newRMCPHeaderForIPMI(),
+			SessionHeader: s.Header(payloadTypeIPMI),
+			Request: &ipmiRequestMessage{
+				RsAddr:  bmcSlaveAddress,
+				RqAddr:  remoteSWID,
+				RqSeq:   s.NextRqSeq(),
+				Command: cmd,
+			},
+		}
+	}
+	return executePipelined(cmds, opts, newRequest, s.writePacket, s.RecvPacket)
+}
+
 func (s *sessionV2_0) NextSequence() uint32 {
 	if s.ActiveSession() {
 		switch s.sequence {
@@ -391,6 +531,11 @@ func (s *sessionV2_0) NextSequence() uint32 {
 	return s.sequence
 }
 
+func (s *sessionV2_0) NextMessageTag() uint8 {
+	s.messageTag++
+	return s.messageTag
+}
+
 func (s *sessionV2_0) NextRqSeq() uint8 {
 	n := s.rqSeq
 	s.rqSeq++
@@ -400,42 +545,105 @@ func (s *sessionV2_0) NextRqSeq() uint8 {
 	return n << 2
 }
 
-func (s *sessionV2_0) SendPacket(req *ipmiPacket) (*ipmiPacket, error) {
+// Reject the replayed or out-of-window packet (Section 6.12.13)
+func (s *sessionV2_0) validateSequence(hdr sessionHeader) error {
+	seq := hdr.Sequence()
+	if seq == 0 {
+		return &MessageError{
+			Message: "Received an authenticated message with session sequence number 0",
+			Detail:  hdr.String(),
+		}
+	}
+
+	if s.inSequence == 0 {
+		// First authenticated packet of the session
+		s.inSequence = seq
+		s.inReceived = 1
+		return nil
+	}
+
+	if ahead := seq - s.inSequence; ahead != 0 && ahead <= sequenceWindowSize {
+		// Slide the window
+		if ahead < sequenceWindowSize {
+			s.inReceived <<= ahead
+		} else {
+			s.inReceived = 0
+		}
+		s.inSequence = seq
+		s.inReceived |= 1
+		return nil
+	}
+
+	behind := s.inSequence - seq
+	if behind >= sequenceWindowSize {
+		return &MessageError{
+			Message: fmt.Sprintf("Received session sequence number is out of window : %d - %d", seq, s.inSequence),
+			Detail:  hdr.String(),
+		}
+	}
+	if bit := uint32(1) << behind; s.inReceived&bit != 0 {
+		return &MessageError{
+			Message: fmt.Sprintf("Received session sequence number is replayed : %d", seq),
+			Detail:  hdr.String(),
+		}
+	} else {
+		s.inReceived |= bit
+	}
+	return nil
+}
+
+func (s *sessionV2_0) SendPacket(req *ipmiPacket, timeout time.Duration) (*ipmiPacket, error) {
+	if err := s.writePacket(req, timeout); err != nil {
+		return nil, err
+	}
+	return s.RecvPacket(timeout)
+}
+
+func (s *sessionV2_0) writePacket(req *ipmiPacket, timeout time.Duration) error {
 	if buf, err := req.Request.Marshal(); err == nil {
 		req.PayloadBytes = buf
 		req.SessionHeader.SetPayloadLength(len(buf))
+		s.args.Trace.trace(TraceSendPayload, buf, req.Request)
 	} else {
-		return nil, err
+		return err
 	}
 
 	if s.ActiveSession() {
 		// Encrypt the payload
-		if requiredConfidentiality(s.args.CipherSuiteID) {
+		if requiredConfidentiality(s.cipherSuiteID) {
 			req.SessionHeader.SetEncrypted(true)
-			if buf, err := encryptPayload(req.PayloadBytes, s.k2); err == nil {
+			if buf, err := s.encryptPayload(req.PayloadBytes); err == nil {
 				req.PayloadBytes = buf
 				req.SessionHeader.SetPayloadLength(len(buf))
 			} else {
-				return nil, err
+				return err
 			}
 		}
 		// Append the session trailer
-		if requiredIntegrity(s.args.CipherSuiteID) {
+		if requiredIntegrity(s.cipherSuiteID) {
 			// Trailer's source is the session header and payload
 			req.SessionHeader.SetAuthenticated(true)
 			if msg, err := req.SessionHeader.Marshal(); err == nil {
-				trailer := makeTrailer(append(msg, req.PayloadBytes...), s.k1)
+				trailer := s.makeTrailer(append(msg, req.PayloadBytes...))
 				req.PayloadBytes = append(req.PayloadBytes, trailer...)
 			} else {
-				return nil, err
+				return err
 			}
 		}
 	}
 
-	res, msg, err := sendMessage(s.conn, req, s.args.Timeout)
+	return writeMessage(s.conn, req, timeout, s.args.Trace)
+}
+
+func (s *sessionV2_0) RecvPacket(timeout time.Duration) (*ipmiPacket, error) {
+	res, msg, err := recvMessage(s.conn, s.recvBuf, timeout, s.args)
 	if err != nil {
 		return nil, err
 	}
+	return s.readPacket(res, msg)
+}
+
+func (s *sessionV2_0) readPacket(res response, msg []byte) (*ipmiPacket, error) {
 	pkt, ok := res.(*ipmiPacket)
 	if !ok {
 		return nil, &MessageError{
@@ -445,33 +653,38 @@ func (s *sessionV2_0) SendPacket(req *ipmiPacket) (*ipmiPacket, error) {
 	}
 
 	if s.ActiveSession() {
-		if id := pkt.SessionHeader.ID(); consoleID != id {
+		if id := pkt.SessionHeader.ID(); s.consoleID() != id {
+			// BMC responds to the closed session with the other session ID
 			return nil, &MessageError{
-				Message: fmt.Sprintf("Mismatch console session ID : 0x%x - 0x%x", consoleID, id),
+				Cause:   ErrSessionExpired,
+				Message: fmt.Sprintf("Mismatch console session ID : 0x%x - 0x%x", s.consoleID(), id),
 				Detail:  pkt.String(),
 			}
 		}
 
-		if requiredIntegrity(s.args.CipherSuiteID) {
+		if requiredIntegrity(s.cipherSuiteID) {
 			if !pkt.SessionHeader.PayloadType().Authenticated() {
 				return nil, &MessageError{
 					Message: "Response message is not authenticated",
 					Detail:  pkt.String(),
 				}
 			}
-			if err := validateTrailer(msg[rmcpHeaderSize:], s.k1); err != nil {
+			if err := s.validateTrailer(msg[rmcpHeaderSize:]); err != nil {
+				return nil, err
+			}
+			if err := s.validateSequence(pkt.SessionHeader); err != nil {
 				return nil, err
 			}
 		}
 
-		if requiredConfidentiality(s.args.CipherSuiteID) {
+		if requiredConfidentiality(s.cipherSuiteID) {
 			if !pkt.SessionHeader.PayloadType().Encrypted() {
 				return nil, &MessageError{
 					Message: "Response message is not encrypted",
 					Detail:  pkt.String(),
 				}
 			}
-			if buf, err := decryptPayload(pkt.PayloadBytes, s.k2); err == nil {
+			if buf, err := s.decryptPayload(pkt.PayloadBytes); err == nil {
 				pkt.PayloadBytes = buf
 				pkt.SessionHeader.SetPayloadLength(len(buf))
 			} else {
@@ -484,19 +697,123 @@ func (s *sessionV2_0) SendPacket(req *ipmiPacket) (*ipmiPacket, error) {
 	if _, err := pkt.Response.Unmarshal(pkt.PayloadBytes); err != nil {
 		return nil, err
 	}
+	s.args.Trace.trace(TraceRecvPayload, pkt.PayloadBytes, pkt.Response)
 
+	if p, ok := pkt.Response.(*SOLPacket); ok && s.console != nil {
+		s.console.deliver(p)
+	}
 	return pkt, nil
 }
 
+func (s *sessionV2_0) Info() SessionInfo {
+	return SessionInfo{
+		Open:           s.ActiveSession(),
+		ID:             s.id,
+		Address:        currentAddress(s.args, s.address),
+		PrivilegeLevel: s.privilegeLevel,
+		AuthType:       AuthTypeRMCPPlus,
+		CipherSuiteID:  s.cipherSuiteID,
+	}
+}
+
 func (s *sessionV2_0) String() string {
 	return fmt.Sprintf(`{ID:%d,"Sequence":%d,"RqSeq":%d,"K1":"%s","K2":"%s"}`,
-		s.id, s.sequence, s.rqSeq, hex.EncodeToString(s.k1), hex.EncodeToString(s.k2))
+		s.id, s.sequence, s.rqSeq, secretString(s.k1, s.args.RevealSecrets), secretString(s.k2, s.args.RevealSecrets))
 }
 
 func newSessionV2_0(args *Arguments) session {
 	return &sessionV2_0{
-		args: args,
+		args:    args,
+		recvBuf: make([]byte, recvBufferSize),
+	}
+}
+
+func (s *sessionV2_0) encryptPayload(src []byte) ([]byte, error) {
+	switch cipherSuiteIDs[s.cipherSuiteID].Crypt {
+	case CryptXRC4_128:
+		return encryptPayloadRC4(src, s.k2, 16)
+	case CryptXRC4_40:
+		return encryptPayloadRC4(src, s.k2, 5)
+	default:
+		return encryptPayload(src, s.k2)
+	}
+}
+
+func (s *sessionV2_0) decryptPayload(src []byte) ([]byte, error) {
+	switch cipherSuiteIDs[s.cipherSuiteID].Crypt {
+	case CryptXRC4_128:
+		return s.decryptPayloadRC4(src, 16)
+	case CryptXRC4_40:
+		return s.decryptPayloadRC4(src, 5)
+	default:
+		return decryptPayload(src, s.k2)
+	}
+}
+
+// Section 13.30
+func (s *sessionV2_0) decryptPayloadRC4(src []byte, keyLen int) ([]byte, error) {
+	if l := len(src); l < 4 {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Payload does not contain xRC4 data offset : %d", l),
+		}
+	}
+
+	offset := binary.LittleEndian.Uint32(src)
+	data := src[4:]
+	if offset == 0 {
+		// Initialization vector is present at the start of a new key stream
+		if l := len(data); l < 16 {
+			return nil, &MessageError{
+				Message: fmt.Sprintf("Payload does not contain xRC4 initialization vector : %d", l),
+			}
+		}
+		c, err := newRC4Cipher(s.k2, data[:16], keyLen)
+		if err != nil {
+			return nil, err
+		}
+		s.rc4In, s.rc4InOffset = c, 0
+		data = data[16:]
+	} else if s.rc4In == nil || offset != s.rc4InOffset {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Mismatch xRC4 data offset : %d - %d", s.rc4InOffset, offset),
+		}
 	}
+
+	dst := make([]byte, len(data))
+	s.rc4In.XORKeyStream(dst, data)
+	s.rc4InOffset += uint32(len(data))
+	return dst, nil
+}
+
+// Section 13.30
+func encryptPayloadRC4(src, key []byte, keyLen int) ([]byte, error) {
+	// Start a new key stream for each payload
+	// +-----------------------+
+	// | Data Offset (0)       |  4 bytes
+	// | Initialization Vector | 16 bytes
+	// +-----------------------+
+	// | Encrypted Payload     |
+	// +-----------------------+
+	dst := make([]byte, 20+len(src))
+	iv := dst[4:20]
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	c, err := newRC4Cipher(key, iv, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	c.XORKeyStream(dst[20:], src)
+	return dst, nil
+}
+
+// The key of the stream is MD5(K2 + IV), truncated to 40 bits for xRC4-40
+func newRC4Cipher(key, iv []byte, keyLen int) (*rc4.Cipher, error) {
+	h := md5.New()
+	h.Write(key[:16])
+	h.Write(iv)
+	return rc4.NewCipher(h.Sum(nil)[:keyLen])
 }
 
 // Section 13.29
@@ -553,21 +870,23 @@ func decryptPayload(src, key []byte) ([]byte, error) {
 	return dst[:len(dst)-padLen-1], nil
 }
 
-func makeTrailer(src, key []byte) []byte {
+// Returns the session trailer of the message
+func (s *sessionV2_0) makeTrailer(src []byte) []byte {
 	// Session Trailer (Table 13-8)
 	// +---------------+
 	// | Integrity PAD |  n bytes
 	// | Pad Length    |  1 byte
 	// | Next Header   |  1 byte  (0x07)
-	// | AuthCode      | 12 bytes
+	// | AuthCode      | 12 or 16 bytes
 	// +---------------+
+	size := integrityCodeSize(s.cipherSuiteID)
 	srcLen := len(src)
 	padLen := 0
-	if mod := (srcLen + 1 + 1 + 12) % 4; mod != 0 {
+	if mod := (srcLen + 1 + 1 + size) % 4; mod != 0 {
 		padLen = 4 - mod
 	}
 
-	data := make([]byte, srcLen+padLen+2+12)
+	data := make([]byte, srcLen+padLen+2+size)
 	copy(data, src)
 
 	for i := 0; i < padLen; i++ {
@@ -576,30 +895,26 @@ func makeTrailer(src, key []byte) []byte {
 	data[srcLen+padLen] = byte(padLen)
 	data[srcLen+padLen+1] = 0x07 // Next Header
 
-	mac := hmac.New(sha1.New, key)
-	mac.Write(data[:srcLen+padLen+2])
-	// Use the first 12 bytes of the authcode
-	authCode := mac.Sum(nil)
-	copy(data[srcLen+padLen+2:], authCode[:12])
+	authCode := integrityCode(s.cipherSuiteID, s.k1, s.args.Password, data[:srcLen+padLen+2])
+	copy(data[srcLen+padLen+2:], authCode)
 
 	return data[srcLen:]
 }
 
-func validateTrailer(src, key []byte) error {
-	if l := len(src); l < 12 {
+func (s *sessionV2_0) validateTrailer(src []byte) error {
+	size := integrityCodeSize(s.cipherSuiteID)
+	if l := len(src); l < size {
 		return &MessageError{
 			Message: fmt.Sprintf("Payload does not contain auth code : %d", l),
 		}
 	}
 
-	authCode := src[len(src)-12:]
-	mac := hmac.New(sha1.New, key)
-	mac.Write(src[:len(src)-12])
-
-	if generated := mac.Sum(nil); !bytes.Equal(authCode, generated[:12]) {
+	authCode := src[len(src)-size:]
+	generated := integrityCode(s.cipherSuiteID, s.k1, s.args.Password, src[:len(src)-size])
+	if !hmac.Equal(authCode, generated) {
 		return &MessageError{
 			Message: fmt.Sprintf("Received message with invalid authcode : %s - %s",
-				hex.EncodeToString(authCode), hex.EncodeToString(generated)),
+				secretString(authCode, s.args.RevealSecrets), secretString(generated, s.args.RevealSecrets)),
 			Detail: hex.EncodeToString(src),
 		}
 	}
@@ -0,0 +1,110 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	IBMIANA    = 0x000002 // IBM
+	LenovoIANA = 0x004a66 // Lenovo
+
+	lenovoNetFnOEM NetFn = 0x32
+)
+
+func init() {
+	for _, iana := range []uint32{IBMIANA, LenovoIANA} {
+		RegisterOEMCommand(iana, lenovoNetFnOEM, 0x98,
+			func() Command { return &SetLenovoLANOverUSBCommand{} })
+		RegisterOEMCommand(iana, lenovoNetFnOEM, 0x99,
+			func() Command { return &GetLenovoLANOverUSBCommand{} })
+		RegisterOEMSELDescriber(iana, lenovoSELDescription)
+	}
+}
+
+// Get LAN over USB Command (Lenovo/IBM IMM/XCC OEM)
+type GetLenovoLANOverUSBCommand struct {
+	// Response Data
+	Enabled bool // LAN over USB interface is enabled
+}
+
+func (c *GetLenovoLANOverUSBCommand) Name() string    { return "Get Lenovo LAN over USB" }
+func (c *GetLenovoLANOverUSBCommand) Code() uint8     { return 0x99 }
+func (c *GetLenovoLANOverUSBCommand) Retryable() bool { return true }
+
+func (c *GetLenovoLANOverUSBCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(lenovoNetFnOEM, 0)
+}
+
+func (c *GetLenovoLANOverUSBCommand) String() string           { return cmdToJSON(c) }
+func (c *GetLenovoLANOverUSBCommand) Marshal() ([]byte, error) { return []byte{0x01}, nil }
+
+func (c *GetLenovoLANOverUSBCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	// 0: enabled, 1: disabled
+	c.Enabled = buf[0] == 0x00
+	return buf[1:], nil
+}
+
+// Set LAN over USB Command (Lenovo/IBM IMM/XCC OEM)
+type SetLenovoLANOverUSBCommand struct {
+	// Request Data
+	Enabled bool
+}
+
+func (c *SetLenovoLANOverUSBCommand) Name() string { return "Set Lenovo LAN over USB" }
+func (c *SetLenovoLANOverUSBCommand) Code() uint8  { return 0x98 }
+
+func (c *SetLenovoLANOverUSBCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(lenovoNetFnOEM, 0)
+}
+
+func (c *SetLenovoLANOverUSBCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetLenovoLANOverUSBCommand) Marshal() ([]byte, error) {
+	if c.Enabled {
+		return []byte{0x01, 0x00}, nil
+	}
+	return []byte{0x01, 0x01}, nil
+}
+
+func (c *SetLenovoLANOverUSBCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Lenovo/IBM OEM SEL event, which is recorded as the timestamped OEM record
+type LenovoSELEvent struct {
+	EventID uint32 // IMM/XCC event ID
+	Data    []byte // Auxiliary data of the event
+}
+
+// Decodes the OEM defined data of the timestamped OEM record of Lenovo/IBM
+func DecodeLenovoSELEvent(r *SELTimestampedOEMRecord) (*LenovoSELEvent, error) {
+	if r.ManufacturerID != IBMIANA && r.ManufacturerID != LenovoIANA {
+		return nil, &ArgumentError{
+			Value:   r.ManufacturerID,
+			Message: "Not a Lenovo/IBM OEM SEL record",
+		}
+	}
+	if l := len(r.OEMDefined); l < 4 {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid Lenovo SEL event size : %d/%d", l, 4),
+			Detail:  hex.EncodeToString(r.OEMDefined),
+		}
+	}
+	return &LenovoSELEvent{
+		EventID: binary.LittleEndian.Uint32(r.OEMDefined),
+		Data:    r.OEMDefined[4:],
+	}, nil
+}
+
+func lenovoSELDescription(r *SELTimestampedOEMRecord) string {
+	e, err := DecodeLenovoSELEvent(r)
+	if err != nil {
+		return fmt.Sprintf("Lenovo OEM Event: Data=%s", hex.EncodeToString(r.OEMDefined))
+	}
+	return fmt.Sprintf("Lenovo OEM Event: ID=0x%08x, Data=%s", e.EventID, hex.EncodeToString(e.Data))
+}
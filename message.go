@@ -7,7 +7,8 @@ import (
 )
 
 const (
-	recvBufferSize = 1 << 11
+	udpPayloadMax  = 65507             // Maximum UDP payload over IPv4
+	recvBufferSize = udpPayloadMax + 1 // The extra byte detects a truncated datagram
 )
 
 type request interface {
@@ -20,7 +21,7 @@ type response interface {
 	String() string
 }
 
-func unmarshalMessage(buf []byte) (response, []byte, error) {
+func unmarshalMessage(buf []byte, reveal bool) (response, []byte, error) {
 	rmcp := &rmcpHeader{}
 	rest, err := rmcp.Unmarshal(buf)
 	if err != nil {
@@ -53,11 +54,18 @@ func unmarshalMessage(buf []byte) (response, []byte, error) {
 			}
 		}
 	case rmcpClassIPMI:
+		if len(rest) < 1 {
+			return nil, nil, &MessageError{
+				Message: "IPMI session header is missing",
+				Detail:  rmcp.String(),
+			}
+		}
+
 		var hdr sessionHeader
-		if authType(rest[0]) == authTypeRMCPPlus {
+		if AuthType(rest[0]) == AuthTypeRMCPPlus {
 			hdr = &sessionHeaderV2_0{}
 		} else {
-			hdr = &sessionHeaderV1_5{}
+			hdr = &sessionHeaderV1_5{reveal: reveal}
 		}
 		if rest, err = hdr.Unmarshal(rest); err != nil {
 			return nil, nil, err
@@ -74,10 +82,16 @@ func unmarshalMessage(buf []byte) (response, []byte, error) {
 		case payloadTypeRMCPOpenRes:
 			pkt.Response = &openSessionResponse{}
 		case payloadTypeRAKP2:
-			pkt.Response = &rakpMessage2{}
+			pkt.Response = &rakpMessage2{reveal: reveal}
 		case payloadTypeRAKP4:
-			pkt.Response = &rakpMessage4{}
+			pkt.Response = &rakpMessage4{reveal: reveal}
+		case payloadTypeSOL:
+			pkt.Response = &SOLPacket{}
 		default:
+			if p := newOEMPayload(hdr); p != nil {
+				pkt.Response = p
+				break
+			}
 			return nil, nil, &MessageError{
 				Message: fmt.Sprintf("Unknown IPMI payload type : %s", hdr.PayloadType()),
 				Detail:  pkt.String(),
@@ -85,6 +99,12 @@ func unmarshalMessage(buf []byte) (response, []byte, error) {
 		}
 
 		plen := hdr.PayloadLength()
+		if l := len(rest); plen > l {
+			return nil, nil, &MessageError{
+				Message: fmt.Sprintf("Invalid IPMI payload length : %d/%d", l, plen),
+				Detail:  hdr.String(),
+			}
+		}
 		if _, err = pkt.Unmarshal(rest[:plen]); err != nil {
 			return nil, nil, err
 		}
@@ -93,27 +113,102 @@ func unmarshalMessage(buf []byte) (response, []byte, error) {
 	}
 }
 
-func sendMessage(conn net.Conn, req request, timeout time.Duration) (response, []byte, error) {
+// Direction of a traced message
+type TraceDirection int
+
+const (
+	TraceSend        TraceDirection = iota // Outgoing packet
+	TraceRecv                              // Incoming packet
+	TraceSendPayload                       // Outgoing payload before encryption
+	TraceRecvPayload                       // Incoming payload after decryption
+)
+
+func (d TraceDirection) String() string {
+	switch d {
+	case TraceSend:
+		return "Send"
+	case TraceRecv:
+		return "Recv"
+	case TraceSendPayload:
+		return "SendPayload"
+	case TraceRecvPayload:
+		return "RecvPayload"
+	default:
+		return fmt.Sprintf("Unknown(%d)", d)
+	}
+}
+
+// A hook to trace the raw bytes and the decoded message
+type TraceFunc func(dir TraceDirection, raw []byte, decoded fmt.Stringer)
+
+func (f TraceFunc) trace(dir TraceDirection, raw []byte, decoded fmt.Stringer) {
+	if f != nil {
+		f(dir, raw, decoded)
+	}
+}
+
+func sendMessage(conn net.Conn, req request, timeout time.Duration, args *Arguments) (response, []byte, error) {
+	if err := writeMessage(conn, req, timeout, args.Trace); err != nil {
+		return nil, nil, err
+	}
+	return recvMessage(conn, nil, timeout, args)
+}
+
+// Sends the request without waiting for the response
+func writeMessage(conn net.Conn, req request, timeout time.Duration, trace TraceFunc) error {
 	buf, err := req.Marshal()
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
+	trace.trace(TraceSend, buf, req)
 
 	deadline := time.Now().Add(timeout)
 	if err = conn.SetDeadline(deadline); err != nil {
-		return nil, nil, err
+		return err
 	}
-	if _, err = conn.Write(buf); err != nil {
+	_, err = conn.Write(buf)
+	return err
+}
+
+// Receives a message into `buf`, which is allocated if it is nil.
+// The datagram filling the buffer is reported as ErrMessageTruncated.
+func recvMessage(conn net.Conn, buf []byte, timeout time.Duration, args *Arguments) (response, []byte, error) {
+	trace := args.Trace
+	deadline := time.Now().Add(timeout)
+	if err := conn.SetReadDeadline(deadline); err != nil {
 		return nil, nil, err
 	}
 
-	buf = make([]byte, recvBufferSize)
-	n, err := conn.Read(buf)
-	if err != nil {
-		return nil, nil, err
+	if buf == nil {
+		buf = make([]byte, recvBufferSize)
 	}
-	buf = buf[:n]
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				// Classify the read deadline as ErrTimeout
+				return nil, nil, &timeoutError{Message: err.Error()}
+			}
+			return nil, nil, err
+		}
+		msg := make([]byte, n)
+		copy(msg, buf)
 
-	res, _, err := unmarshalMessage(buf)
-	return res, buf, err
+		if n == len(buf) {
+			trace.trace(TraceRecv, msg, nil)
+			return nil, msg, ErrMessageTruncated
+		}
+
+		res, _, err := unmarshalMessage(msg, args.RevealSecrets)
+		if err != nil {
+			trace.trace(TraceRecv, msg, nil)
+			if _, ok := err.(*MessageError); ok {
+				// Drain an unexpected datagram and wait for the next one until the deadline
+				continue
+			}
+			return nil, msg, err
+		}
+		trace.trace(TraceRecv, msg, res)
+		return res, msg, nil
+	}
 }
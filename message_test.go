@@ -0,0 +1,34 @@
+package ipmigo
+
+import (
+	"testing"
+)
+
+func TestUnmarshalMessageTruncatedHeader(t *testing.T) {
+	// RMCP header of the IPMI class only
+	buf := []byte{rmcpVersion1, 0x00, rmcpNoAckSeq, byte(rmcpClassIPMI)}
+	if _, _, err := unmarshalMessage(buf, false); err == nil {
+		t.Fatal("no error for the truncated header")
+	} else if _, ok := err.(*MessageError); !ok {
+		t.Fatalf("err = %T, expected *MessageError", err)
+	}
+}
+
+func TestUnmarshalMessageOversizedPayloadLength(t *testing.T) {
+	buf := []byte{rmcpVersion1, 0x00, rmcpNoAckSeq, byte(rmcpClassIPMI)}
+	// IPMI v2.0 session header of the IPMI payload with 0xff bytes, followed by 2 bytes
+	buf = append(buf, byte(AuthTypeRMCPPlus), byte(payloadTypeIPMI), 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0x00, 0x01, 0x02)
+	if _, _, err := unmarshalMessage(buf, false); err == nil {
+		t.Fatal("no error for the oversized payload length")
+	} else if _, ok := err.(*MessageError); !ok {
+		t.Fatalf("err = %T, expected *MessageError", err)
+	}
+
+	// IPMI v1.5 session header
+	buf = append(buf[:rmcpHeaderSize], byte(AuthTypeNone), 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0x01, 0x02)
+	if _, _, err := unmarshalMessage(buf, false); err == nil {
+		t.Fatal("no error for the oversized payload length (v1.5)")
+	} else if _, ok := err.(*MessageError); !ok {
+		t.Fatalf("err = %T, expected *MessageError", err)
+	}
+}
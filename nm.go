@@ -0,0 +1,141 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const (
+	nmIANA = 0x000157 // Intel Corporation
+
+	nmPolicySize = 12 // Policy type, exception actions and limits
+)
+
+// Intel Node Manager domain IDs
+const (
+	NMDomainPlatform uint8 = 0x00 // Entire platform
+	NMDomainCPU      uint8 = 0x01 // CPU subsystem
+	NMDomainMemory   uint8 = 0x02 // Memory subsystem
+	NMDomainIO       uint8 = 0x03 // High power I/O subsystem
+)
+
+// Intel Node Manager policy trigger type
+type NMTriggerType uint8
+
+const (
+	NMTriggerNone             NMTriggerType = 0x00 // Power limit policy without trigger
+	NMTriggerInletTemperature NMTriggerType = 0x01 // Inlet temperature limit policy (Celsius)
+	NMTriggerMissingReadings  NMTriggerType = 0x02 // Missing power reading timeout (1/10 s)
+	NMTriggerResetTime        NMTriggerType = 0x03 // Time after platform reset (1/10 s)
+	NMTriggerBootTime         NMTriggerType = 0x04 // Boot time policy
+)
+
+func (t NMTriggerType) String() string {
+	switch t {
+	case NMTriggerNone:
+		return "None"
+	case NMTriggerInletTemperature:
+		return "Inlet Temperature"
+	case NMTriggerMissingReadings:
+		return "Missing Power Readings Timeout"
+	case NMTriggerResetTime:
+		return "Time After Platform Reset"
+	case NMTriggerBootTime:
+		return "Boot Time"
+	default:
+		return fmt.Sprintf("Reserved(%d)", t)
+	}
+}
+
+// Intel Node Manager aggressive CPU power correction
+type NMCorrection uint8
+
+const (
+	NMCorrectionAuto     NMCorrection = 0x00 // Automatic
+	NMCorrectionNoTState NMCorrection = 0x01 // Non-aggressive, T-states and memory throttling are not used
+	NMCorrectionTState   NMCorrection = 0x02 // Aggressive, T-states and memory throttling are used
+)
+
+func (c NMCorrection) String() string {
+	switch c {
+	case NMCorrectionAuto:
+		return "Auto"
+	case NMCorrectionNoTState:
+		return "Not Aggressive"
+	case NMCorrectionTState:
+		return "Aggressive"
+	default:
+		return fmt.Sprintf("Reserved(%d)", c)
+	}
+}
+
+// Intel Node Manager policy
+type NMPolicy struct {
+	DomainID   uint8
+	PolicyID   uint8
+	Enabled    bool
+	Trigger    NMTriggerType
+	Correction NMCorrection
+	Volatile   bool // Policy is not stored in the persistent storage
+
+	// Policy exception actions
+	SendAlert bool
+	Shutdown  bool
+
+	Limit            uint16        // Power limit in watts (Boot time policy: the performance settings)
+	CorrectionTime   time.Duration // Correction time limit in milliseconds
+	TriggerLimit     uint16        // Trigger limit in the unit of the trigger type
+	StatisticsPeriod time.Duration // Statistics reporting period in seconds
+}
+
+// Encodes the policy trigger type and the exception actions, and the limits
+func (p *NMPolicy) marshal(add bool) []byte {
+	buf := make([]byte, nmPolicySize)
+	buf[0] = uint8(p.Trigger)&0x0f | uint8(p.Correction)&0x03<<5
+	if add {
+		buf[0] |= 0x10
+	}
+	if p.Volatile {
+		buf[0] |= 0x80
+	}
+	if p.SendAlert {
+		buf[1] |= 0x01
+	}
+	if p.Shutdown {
+		buf[1] |= 0x02
+	}
+	binary.LittleEndian.PutUint16(buf[2:], p.Limit)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(p.CorrectionTime/time.Millisecond))
+	binary.LittleEndian.PutUint16(buf[8:], p.TriggerLimit)
+	binary.LittleEndian.PutUint16(buf[10:], uint16(p.StatisticsPeriod/time.Second))
+	return buf
+}
+
+func (p *NMPolicy) unmarshal(buf []byte) []byte {
+	p.Trigger = NMTriggerType(buf[0] & 0x0f)
+	p.Correction = NMCorrection(buf[0] >> 5 & 0x03)
+	p.Volatile = buf[0]&0x80 != 0
+	p.SendAlert = buf[1]&0x01 != 0
+	p.Shutdown = buf[1]&0x02 != 0
+	p.Limit = binary.LittleEndian.Uint16(buf[2:])
+	p.CorrectionTime = time.Duration(binary.LittleEndian.Uint32(buf[4:])) * time.Millisecond
+	p.TriggerLimit = binary.LittleEndian.Uint16(buf[8:])
+	p.StatisticsPeriod = time.Duration(binary.LittleEndian.Uint16(buf[10:])) * time.Second
+	return buf[nmPolicySize:]
+}
+
+// Intel Node Manager statistics mode
+type NMStatisticsMode uint8
+
+const (
+	NMStatisticsGlobalPower        NMStatisticsMode = 0x01 // Global power statistics in watts
+	NMStatisticsGlobalInletTemp    NMStatisticsMode = 0x02 // Global inlet temperature statistics in Celsius
+	NMStatisticsGlobalThrottling   NMStatisticsMode = 0x03 // Global throttling statistics in %
+	NMStatisticsGlobalVolumetric   NMStatisticsMode = 0x04 // Global volumetric airflow statistics in 1/10 CFM
+	NMStatisticsGlobalOutletTemp   NMStatisticsMode = 0x05 // Global outlet airflow temperature statistics in Celsius
+	NMStatisticsGlobalChassisPower NMStatisticsMode = 0x06 // Global chassis power statistics in watts
+	NMStatisticsPolicyPower        NMStatisticsMode = 0x11 // Per policy power statistics in watts
+	NMStatisticsPolicyTrigger      NMStatisticsMode = 0x12 // Per policy trigger statistics in the unit of the trigger
+	NMStatisticsPolicyThrottling   NMStatisticsMode = 0x13 // Per policy throttling statistics in %
+)
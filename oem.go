@@ -0,0 +1,136 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const oemIANASize = 3
+
+// OEMCommandBase is the base of the OEM commands whose request and response data are prefixed with
+// the IANA enterprise number (e.g. the commands of the OEM/Group NetFn).
+// It is embedded into the OEM command to encode and validate the prefix.
+type OEMCommandBase struct {
+	IANA uint32 // IANA enterprise number of the command set
+}
+
+// Sets the IANA of the command set if it is not specified
+func (b *OEMCommandBase) defaultIANA(iana uint32) {
+	if b.IANA == 0 {
+		b.IANA = iana
+	}
+}
+
+// Returns the request data prefixed with the IANA
+func (b *OEMCommandBase) MarshalIANA(data ...byte) []byte {
+	return oemMarshalIANA(b.IANA, data...)
+}
+
+// Validates the IANA echoed in the response and that at least `min` bytes follow it,
+// then returns the data following the IANA.
+func (b *OEMCommandBase) UnmarshalIANA(c Command, buf []byte, min int) ([]byte, error) {
+	if err := oemValidateResponse(c, b.IANA, buf, min); err != nil {
+		return nil, err
+	}
+	return buf[oemIANASize:], nil
+}
+
+func oemMarshalIANA(iana uint32, data ...byte) []byte {
+	return append([]byte{byte(iana), byte(iana >> 8), byte(iana >> 16)}, data...)
+}
+
+func oemValidateResponse(c Command, iana uint32, buf []byte, min int) error {
+	if err := cmdValidateLength(c, buf, oemIANASize+min); err != nil {
+		return err
+	}
+	if v := uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16; v != iana&0xffffff {
+		return &MessageError{
+			Message: fmt.Sprintf("Invalid %s Response IANA : 0x%06x", c.Name(), v),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	return nil
+}
+
+// Returns a new OEM command
+type OEMCommandFactory func() Command
+
+type oemCommandKey struct {
+	iana  uint32
+	netFn NetFn
+	code  uint8
+}
+
+var oemCommands = struct {
+	sync.RWMutex
+	m map[oemCommandKey]OEMCommandFactory
+}{m: make(map[oemCommandKey]OEMCommandFactory)}
+
+func validOEMNetFn(n NetFn) bool {
+	// OEM/Group and Controller-specific OEM/Group (Section 5.1)
+	return n == NetFnOEMGroupReq || (n >= NetFnOEMReq && n <= netFnOEMMax)
+}
+
+// Register the OEM command of the vendor by the IANA enterprise number, the request NetFn and the command code.
+// The command set of the vendor can be provided by the other packages.
+func RegisterOEMCommand(iana uint32, netFn NetFn, code uint8, f OEMCommandFactory) error {
+	if !validOEMNetFn(netFn) || netFn&0x01 != 0 {
+		return &ArgumentError{
+			Value:   netFn,
+			Message: "Invalid OEM request NetFn",
+		}
+	}
+	if f == nil {
+		return &ArgumentError{
+			Value:   f,
+			Message: "OEM command factory is required",
+		}
+	}
+
+	oemCommands.Lock()
+	defer oemCommands.Unlock()
+	oemCommands.m[oemCommandKey{iana & 0xffffff, netFn, code}] = f
+	return nil
+}
+
+// Returns a new OEM command, or nil if the command is not registered
+func NewOEMCommand(iana uint32, netFn NetFn, code uint8) Command {
+	oemCommands.RLock()
+	f := oemCommands.m[oemCommandKey{iana & 0xffffff, netFn, code}]
+	oemCommands.RUnlock()
+	if f == nil {
+		return nil
+	}
+	return f()
+}
+
+// Returns new commands of the vendor registered by the IANA enterprise number,
+// which are sorted by the NetFn and the command code.
+func OEMCommands(iana uint32) []Command {
+	oemCommands.RLock()
+	var keys []oemCommandKey
+	for k := range oemCommands.m {
+		if k.iana == iana&0xffffff {
+			keys = append(keys, k)
+		}
+	}
+	fs := make([]OEMCommandFactory, len(keys))
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].netFn != keys[j].netFn {
+			return keys[i].netFn < keys[j].netFn
+		}
+		return keys[i].code < keys[j].code
+	})
+	for i, k := range keys {
+		fs[i] = oemCommands.m[k]
+	}
+	oemCommands.RUnlock()
+
+	cmds := make([]Command, len(fs))
+	for i, f := range fs {
+		cmds[i] = f()
+	}
+	return cmds
+}
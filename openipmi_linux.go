@@ -0,0 +1,293 @@
+//go:build linux
+// +build linux
+
+package ipmigo
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Linux OpenIPMI driver interface (See linux/ipmi.h)
+const (
+	openIPMISystemInterfaceAddrType = 0x0c
+	openIPMIIPMBAddrType            = 0x01
+	openIPMIBMCChannel              = 0x0f
+	openIPMIResponseRecvType        = 1
+	openIPMIMaxMessageLength        = 1024
+	openIPMIPollIn                  = 0x0001
+
+	openIPMIIOCMagic = 'i'
+	iocWrite         = 1
+	iocRead          = 2
+)
+
+type openIPMISystemInterfaceAddr struct {
+	AddrType int32
+	Channel  int16
+	LUN      uint8
+}
+
+type openIPMIIPMBAddr struct {
+	AddrType  int32
+	Channel   int16
+	SlaveAddr uint8
+	LUN       uint8
+}
+
+// struct pollfd of poll(2)
+type openIPMIPollFd struct {
+	Fd      int32
+	Events  int16
+	Revents int16
+}
+
+type openIPMIMsg struct {
+	NetFn   uint8
+	Cmd     uint8
+	DataLen uint16
+	Data    *byte
+}
+
+type openIPMIReq struct {
+	Addr    *byte
+	AddrLen uint32
+	MsgID   int
+	Msg     openIPMIMsg
+}
+
+type openIPMIRecv struct {
+	RecvType int32
+	Addr     *byte
+	AddrLen  uint32
+	MsgID    int
+	Msg      openIPMIMsg
+}
+
+func ioc(dir, nr, size uintptr) uintptr {
+	return dir<<30 | size<<16 | openIPMIIOCMagic<<8 | nr
+}
+
+var (
+	ipmictlReceiveMsgTrunc = ioc(iocRead|iocWrite, 11, unsafe.Sizeof(openIPMIRecv{}))
+	ipmictlSendCommand     = ioc(iocRead, 13, unsafe.Sizeof(openIPMIReq{}))
+)
+
+var errOpenIPMITimeout error = &timeoutError{Message: "OpenIPMI response timeout"}
+
+// In-band session via the Linux OpenIPMI driver (e.g. /dev/ipmi0)
+type sessionOpenIPMI struct {
+	file  *os.File
+	args  *Arguments
+	msgID int
+}
+
+// RMCP/ASF ping is not available via the system interface
+func (s *sessionOpenIPMI) Ping() (*Pong, error) {
+	return nil, &ArgumentError{
+		Value:   s.args.Device,
+		Message: "Ping is not supported via the system interface",
+	}
+}
+
+func (s *sessionOpenIPMI) Open() error {
+	if s.file != nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(s.args.Device, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	return nil
+}
+
+func (s *sessionOpenIPMI) Close() error {
+	if f := s.file; f != nil {
+		s.file = nil
+		return f.Close()
+	}
+	return nil
+}
+
+func (s *sessionOpenIPMI) Execute(cmd Command, opts Options) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+
+	// The driver bridges the request to IPMB by itself and returns the response of the target
+	target := openIPMIIPMBAddr{AddrType: openIPMISystemInterfaceAddrType, Channel: openIPMIBMCChannel}
+	if sm, ok := cmd.(*SendMessageCommand); ok {
+		if _, ok := sm.Command.(*SendMessageCommand); ok {
+			return &ArgumentError{
+				Value:   s.args.Device,
+				Message: "Double bridging is not supported via the system interface",
+			}
+		}
+		target = openIPMIIPMBAddr{AddrType: openIPMIIPMBAddrType, Channel: int16(sm.Channel), SlaveAddr: sm.RsAddr}
+		cmd = sm.Command
+	}
+
+	var data []byte
+	err := retry(opts.Retries, &s.args.Backoff, func() (e error) {
+		data, e = s.execute(cmd, target, opts.Timeout)
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := cmdUnmarshal(cmd, data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Returns the response data without the completion code.
+// The target is the system interface or the IPMB address of the bridged controller.
+func (s *sessionOpenIPMI) execute(cmd Command, target openIPMIIPMBAddr, timeout time.Duration) ([]byte, error) {
+	data, err := cmdMarshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	s.msgID++
+	var addr unsafe.Pointer
+	var addrLen uintptr
+	if target.AddrType == openIPMIIPMBAddrType {
+		ipmb := target
+		ipmb.LUN = cmd.NetFnRsLUN().RsLUN()
+		addr, addrLen = unsafe.Pointer(&ipmb), unsafe.Sizeof(ipmb)
+	} else {
+		si := openIPMISystemInterfaceAddr{
+			AddrType: openIPMISystemInterfaceAddrType,
+			Channel:  openIPMIBMCChannel,
+			LUN:      cmd.NetFnRsLUN().RsLUN(),
+		}
+		addr, addrLen = unsafe.Pointer(&si), unsafe.Sizeof(si)
+	}
+	req := openIPMIReq{
+		Addr:    (*byte)(addr),
+		AddrLen: uint32(addrLen),
+		MsgID:   s.msgID,
+		Msg: openIPMIMsg{
+			NetFn:   uint8(cmd.NetFnRsLUN().NetFn()),
+			Cmd:     cmd.Code(),
+			DataLen: uint16(len(data)),
+		},
+	}
+	if len(data) > 0 {
+		req.Msg.Data = &data[0]
+	}
+
+	s.args.Trace.trace(TraceSend, data, cmd)
+	if err := s.ioctl(ipmictlSendCommand, unsafe.Pointer(&req)); err != nil {
+		return nil, err
+	}
+	runtime.KeepAlive(addr)
+	runtime.KeepAlive(data)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		buf, recvType, msgID, err := s.recv(deadline)
+		if err != nil {
+			return nil, err
+		}
+		s.args.Trace.trace(TraceRecv, buf, nil)
+
+		// Drain the async events and the commands, and discard the response of a previous request
+		if recvType != openIPMIResponseRecvType || msgID != s.msgID {
+			continue
+		}
+
+		if len(buf) < 1 {
+			return nil, &MessageError{
+				Message: "Received an empty response (OpenIPMI)",
+			}
+		}
+		if cc := CompletionCode(buf[0]); cc != CompletionOK {
+			return nil, &CommandError{
+				CompletionCode: cc,
+				Command:        cmd,
+				Response:       append([]byte(nil), buf[1:]...),
+			}
+		}
+		return buf[1:], nil
+	}
+}
+
+// Receives a message until the deadline, and returns the data, the receive type and the message ID
+func (s *sessionOpenIPMI) recv(deadline time.Time) ([]byte, int32, int, error) {
+	for {
+		timeout := deadline.Sub(time.Now())
+		if timeout <= 0 {
+			return nil, 0, 0, errOpenIPMITimeout
+		}
+
+		// poll(2) is used since select(2) can not watch the descriptor beyond FD_SETSIZE
+		fds := openIPMIPollFd{Fd: int32(s.file.Fd()), Events: openIPMIPollIn}
+		ts := syscall.NsecToTimespec(timeout.Nanoseconds())
+		n, _, errno := syscall.Syscall6(syscall.SYS_PPOLL, uintptr(unsafe.Pointer(&fds)), 1,
+			uintptr(unsafe.Pointer(&ts)), 0, 0, 0)
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return nil, 0, 0, errno
+		}
+		if n == 0 {
+			return nil, 0, 0, errOpenIPMITimeout
+		}
+		break
+	}
+
+	// Large enough for the system interface and IPMB addresses
+	var addr openIPMIIPMBAddr
+	buf := make([]byte, openIPMIMaxMessageLength)
+	recv := openIPMIRecv{
+		Addr:    (*byte)(unsafe.Pointer(&addr)),
+		AddrLen: uint32(unsafe.Sizeof(addr)),
+		Msg: openIPMIMsg{
+			Data:    &buf[0],
+			DataLen: uint16(len(buf)),
+		},
+	}
+	if err := s.ioctl(ipmictlReceiveMsgTrunc, unsafe.Pointer(&recv)); err != nil {
+		if err == syscall.EMSGSIZE {
+			return nil, 0, 0, ErrMessageTruncated
+		}
+		return nil, 0, 0, err
+	}
+	runtime.KeepAlive(&addr)
+
+	return buf[:recv.Msg.DataLen], recv.RecvType, recv.MsgID, nil
+}
+
+func (s *sessionOpenIPMI) ioctl(req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, s.file.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// The system interface has no session
+func (s *sessionOpenIPMI) Info() SessionInfo {
+	return SessionInfo{
+		Open:    s.file != nil,
+		Address: s.args.Device,
+	}
+}
+
+func (s *sessionOpenIPMI) String() string {
+	return fmt.Sprintf(`{"Device":"%s","MsgID":%d}`, s.args.Device, s.msgID)
+}
+
+func newSessionOpenIPMI(args *Arguments) (session, error) {
+	return &sessionOpenIPMI{args: args}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package ipmigo
+
+func newSessionOpenIPMI(args *Arguments) (session, error) {
+	return nil, &ArgumentError{
+		Value:   args.Device,
+		Message: "In-band access is supported on Linux only",
+	}
+}
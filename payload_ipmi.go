@@ -2,7 +2,9 @@ package ipmigo
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"time"
 )
 
 const (
@@ -29,6 +31,40 @@ const (
 	NetFnTransportRes
 )
 
+const (
+	NetFnGroupExtensionReq NetFn = iota + 0x2c
+	NetFnGroupExtensionRes
+	NetFnOEMGroupReq
+	NetFnOEMGroupRes
+	NetFnOEMReq // Controller-specific OEM/Group, 0x30 to 0x3f
+	NetFnOEMRes
+
+	netFnOEMMax NetFn = 0x3f
+)
+
+var netFnNames = []string{"Chassis", "Bridge", "Sensor/Event", "App", "Firmware", "Storage", "Transport"}
+
+// Returns the name of the function with the direction (e.g. "App Request")
+func (n NetFn) String() string {
+	var s string
+	switch {
+	case int(n>>1) < len(netFnNames):
+		s = netFnNames[n>>1]
+	case n&^1 == NetFnGroupExtensionReq:
+		s = "Group Extension"
+	case n&^1 == NetFnOEMGroupReq:
+		s = "OEM/Group"
+	case n >= NetFnOEMReq && n <= netFnOEMMax:
+		s = fmt.Sprintf("OEM(0x%02x)", uint8(n&^1))
+	default:
+		return fmt.Sprintf("Reserved(0x%02x)", uint8(n))
+	}
+	if n&1 == 0 {
+		return s + " Request"
+	}
+	return s + " Response"
+}
+
 // Network Function and Logical Unit Number
 type NetFnRsLUN uint8
 
@@ -53,7 +89,12 @@ type ipmiRequestMessage struct {
 }
 
 func (m *ipmiRequestMessage) Marshal() ([]byte, error) {
-	data, err := m.Command.Marshal()
+	if c, ok := m.Command.(*SendMessageCommand); ok {
+		// Encapsulated message uses the same sequence number
+		c.rqSeq = m.RqSeq
+	}
+
+	data, err := cmdMarshal(m.Command)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +191,71 @@ func (m *ipmiResponseMessage) String() string {
 		m.RqAddr, m.NetFnRsRUN, m.RsAddr, m.RqSeq, m.Code, m.CompletionCode, hex.EncodeToString(m.Data))
 }
 
+// Returns the response message of the command from the packet
+func commandResponse(pkt *ipmiPacket, cmd Command) (*ipmiResponseMessage, error) {
+	rsm, ok := pkt.Response.(*ipmiResponseMessage)
+	if !ok {
+		return nil, &MessageError{
+			Message: "Received an unexpected message (Command)",
+			Detail:  pkt.String(),
+		}
+	}
+
+	if rsm.CompletionCode != CompletionOK {
+		return nil, &CommandError{
+			CompletionCode: rsm.CompletionCode,
+			Command:        cmd,
+			Response:       append([]byte(nil), rsm.Data...),
+		}
+	}
+	return rsm, nil
+}
+
+// Returns true if the packet is the response to the request
+func matchResponse(req *ipmiRequestMessage, pkt *ipmiPacket) bool {
+	if pkt == nil {
+		return false
+	}
+	rsm, ok := pkt.Response.(*ipmiResponseMessage)
+	if !ok {
+		return false
+	}
+	return rsm.RqSeq>>2 == req.RqSeq>>2 &&
+		rsm.NetFnRsRUN.NetFn() == req.Command.NetFnRsLUN().NetFn()+1 &&
+		rsm.Code == req.Command.Code()
+}
+
+// Returns true if the receive error is caused by a broken or unexpected datagram, which can be discarded.
+// The expired session is reported since the later datagrams are also rejected.
+func discardable(err error) bool {
+	_, ok := err.(*MessageError)
+	return ok && err != ErrMessageTruncated && !errors.Is(err, ErrSessionExpired)
+}
+
+var errResponseTimeout error = &timeoutError{Message: "Timed out waiting for the response"}
+
+// Receives packets until the response to the request arrives, discarding stale ones
+func awaitResponse(req *ipmiRequestMessage, pkt *ipmiPacket, deadline time.Time,
+	recv func(time.Duration) (*ipmiPacket, error)) (*ipmiPacket, error) {
+
+	for !matchResponse(req, pkt) {
+		timeout := deadline.Sub(time.Now())
+		if timeout <= 0 {
+			return nil, errResponseTimeout
+		}
+
+		var err error
+		if pkt, err = recv(timeout); err != nil {
+			if discardable(err) {
+				// Discard a broken or unexpected datagram
+				continue
+			}
+			return nil, err
+		}
+	}
+	return pkt, nil
+}
+
 func checksum(buf []byte) byte {
 	var c byte
 	for _, x := range buf {
@@ -0,0 +1,164 @@
+package ipmigo
+
+import (
+	"sync"
+	"time"
+)
+
+// An OEMPayload is a message carried by OEM payload types (Section 13.27.3)
+type OEMPayload interface {
+	Marshal() ([]byte, error)
+	Unmarshal(buf []byte) ([]byte, error)
+	String() string
+}
+
+// Returns a new OEMPayload to unmarshal the received payload
+type OEMPayloadFactory func() OEMPayload
+
+type oemPayloadKey struct {
+	payloadType uint8
+	iana        uint32
+	payloadID   uint16
+}
+
+var oemPayloads = struct {
+	sync.RWMutex
+	m map[oemPayloadKey]OEMPayloadFactory
+}{m: make(map[oemPayloadKey]OEMPayloadFactory)}
+
+func validOEMPayloadType(t uint8) bool {
+	return t == payloadTypeOEM || (t >= 0x20 && t <= 0x27)
+}
+
+// Register the handler of the OEM payload type.
+// The IANA and the payload ID are used only when the payload type is OEM explicit(0x02).
+func RegisterOEMPayload(payloadType uint8, iana uint32, payloadID uint16, f OEMPayloadFactory) error {
+	if !validOEMPayloadType(payloadType) {
+		return &ArgumentError{
+			Value:   payloadType,
+			Message: "Invalid OEM Payload Type",
+		}
+	}
+	if payloadType != payloadTypeOEM {
+		iana, payloadID = 0, 0
+	}
+
+	oemPayloads.Lock()
+	defer oemPayloads.Unlock()
+	oemPayloads.m[oemPayloadKey{payloadType, iana & 0xffffff, payloadID}] = f
+	return nil
+}
+
+// Returns a new payload for the session header, or nil if no handler is registered
+func newOEMPayload(hdr sessionHeader) OEMPayload {
+	h, ok := hdr.(*sessionHeaderV2_0)
+	if !ok {
+		return nil
+	}
+
+	key := oemPayloadKey{payloadType: uint8(h.payloadType.Pure())}
+	if key.payloadType == payloadTypeOEM {
+		key.iana, key.payloadID = h.oemIANA, h.oemPayloadID
+	}
+
+	oemPayloads.RLock()
+	f := oemPayloads.m[key]
+	oemPayloads.RUnlock()
+	if f == nil {
+		return nil
+	}
+	return f()
+}
+
+// Sends the OEM payload and returns the response payload created by the registered handler
+func (s *sessionV2_0) sendOEMPayload(t uint8, iana uint32, payloadID uint16, req OEMPayload,
+	opts Options) (OEMPayload, error) {
+
+	if err := s.Open(); err != nil {
+		return nil, err
+	}
+
+	var res *ipmiPacket
+	err := retry(opts.Retries, &s.args.Backoff, func() (e error) {
+		hdr := s.Header(payloadType(t)).(*sessionHeaderV2_0)
+		if t == payloadTypeOEM {
+			hdr.oemIANA, hdr.oemPayloadID = iana&0xffffff, payloadID
+		}
+		pkt := &ipmiPacket{
+			RMCPHeader:    newRMCPHeaderForIPMI(),
+			SessionHeader: hdr,
+			Request:       req,
+		}
+		if e = s.writePacket(pkt, opts.Timeout); e != nil {
+			return
+		}
+		res, e = s.awaitOEMPayload(hdr, time.Now().Add(opts.Timeout))
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Response.(OEMPayload), nil
+}
+
+// Receives packets until the OEM payload of the request header arrives, discarding the others
+func (s *sessionV2_0) awaitOEMPayload(req *sessionHeaderV2_0, deadline time.Time) (*ipmiPacket, error) {
+	for {
+		timeout := deadline.Sub(time.Now())
+		if timeout <= 0 {
+			return nil, errResponseTimeout
+		}
+
+		pkt, err := s.RecvPacket(timeout)
+		if err != nil {
+			if discardable(err) {
+				// Discard a broken or unexpected datagram
+				continue
+			}
+			return nil, err
+		}
+		if matchOEMPayload(req, pkt) {
+			return pkt, nil
+		}
+	}
+}
+
+// Returns true if the packet is the OEM payload in the session of the request header
+func matchOEMPayload(req *sessionHeaderV2_0, pkt *ipmiPacket) bool {
+	if _, ok := pkt.Response.(OEMPayload); !ok {
+		return false
+	}
+	hdr, ok := pkt.SessionHeader.(*sessionHeaderV2_0)
+	if !ok || hdr.payloadType.Pure() != req.payloadType.Pure() {
+		return false
+	}
+	if req.payloadType.Pure() == payloadTypeOEM &&
+		(hdr.oemIANA != req.oemIANA || hdr.oemPayloadID != req.oemPayloadID) {
+		return false
+	}
+	// The packet outside of the session has neither the session ID nor the sequence number
+	return (req.id == 0) == (hdr.id == 0) && (req.id == 0) == (hdr.sequence == 0)
+}
+
+// Sends the OEM payload over the IPMI v2.0 session.
+// The handler of the response payload must be registered by RegisterOEMPayload.
+func (c *Client) SendOEMPayload(payloadType uint8, iana uint32, payloadID uint16, req OEMPayload) (OEMPayload, error) {
+	if !validOEMPayloadType(payloadType) {
+		return nil, &ArgumentError{
+			Value:   payloadType,
+			Message: "Invalid OEM Payload Type",
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.session.(*sessionV2_0)
+	if !ok {
+		return nil, &ArgumentError{
+			Value:   c.args.Version,
+			Message: "OEM payloads require IPMI v2.0",
+		}
+	}
+	return s.sendOEMPayload(payloadType, iana, payloadID, req, c.args.options())
+}
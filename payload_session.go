@@ -2,11 +2,13 @@ package ipmigo
 
 import (
 	"crypto/hmac"
+	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha1"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"hash"
 )
 
 const (
@@ -17,30 +19,30 @@ const (
 	rakpMessage3Size        = 8
 	rakpMessage4Size        = 8
 
-	integrityCheckSize = 12        // Supported HMAC-SHA1-96 only (Section 13.28.1)
-	authCodeSize       = sha1.Size // Supported RAKP-HMAC-SHA1 only (Section 13.28.1)
-	sikSize            = sha1.Size
+	hmacSHA1_96Size = 12 // HMAC-SHA1-96 (Section 13.28.4)
+	constSize       = 20
 )
 
-var const1 = [sikSize]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
-var const2 = [sikSize]byte{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
+// Constants to generate K1 and K2, which are 20 bytes for all algorithms (Section 13.32)
+var const1 = [constSize]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+var const2 = [constSize]byte{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
 
 // Authentication Algorithm (Section 13.28)
-type authAlgorithm uint8
+type AuthAlgorithm uint8
 
 const (
-	authRakpNone authAlgorithm = iota
-	authRakpHmacSHA1
-	authRakpHmacMD5
+	AuthRakpNone AuthAlgorithm = iota
+	AuthRakpHmacSHA1
+	AuthRakpHmacMD5
 )
 
-func (a authAlgorithm) String() string {
+func (a AuthAlgorithm) String() string {
 	switch a {
-	case authRakpNone:
+	case AuthRakpNone:
 		return "RAKP-none"
-	case authRakpHmacSHA1:
+	case AuthRakpHmacSHA1:
 		return "RAKP-HMAC-SHA1"
-	case authRakpHmacMD5:
+	case AuthRakpHmacMD5:
 		return "RAKP-HMAC-MD5"
 	default:
 		return fmt.Sprintf("Unknown(%d)", a)
@@ -48,24 +50,24 @@ func (a authAlgorithm) String() string {
 }
 
 // Integrity Algorithm (Section 13.28.4)
-type integrityAlgorithm uint8
+type IntegrityAlgorithm uint8
 
 const (
-	integrityNone integrityAlgorithm = iota
-	integrityHmacSHA1_96
-	integrityHmacMD5_128
-	integrityMD5_128
+	IntegrityNone IntegrityAlgorithm = iota
+	IntegrityHmacSHA1_96
+	IntegrityHmacMD5_128
+	IntegrityMD5_128
 )
 
-func (a integrityAlgorithm) String() string {
+func (a IntegrityAlgorithm) String() string {
 	switch a {
-	case integrityNone:
+	case IntegrityNone:
 		return "None"
-	case integrityHmacSHA1_96:
+	case IntegrityHmacSHA1_96:
 		return "HMAC-SHA1-96"
-	case integrityHmacMD5_128:
+	case IntegrityHmacMD5_128:
 		return "HMAC-MD5-128"
-	case integrityMD5_128:
+	case IntegrityMD5_128:
 		return "MD5-128"
 	default:
 		return fmt.Sprintf("Unknown(%d)", a)
@@ -73,63 +75,90 @@ func (a integrityAlgorithm) String() string {
 }
 
 // Confidentiality Algorithm (Section 13.28.5)
-type cryptAlgorithm uint8
+type CryptAlgorithm uint8
 
 const (
-	cryptNone cryptAlgorithm = iota
-	cryptAesCBC_128
-	cryptXRC4_128
-	cryptXRC4_40
+	CryptNone CryptAlgorithm = iota
+	CryptAesCBC_128
+	CryptXRC4_128
+	CryptXRC4_40
 )
 
-func (a cryptAlgorithm) String() string {
+func (a CryptAlgorithm) String() string {
 	switch a {
-	case cryptNone:
+	case CryptNone:
 		return "None"
-	case cryptAesCBC_128:
+	case CryptAesCBC_128:
 		return "AES-CBC-128"
-	case cryptXRC4_128:
-		return "xRC4-128"
-	case cryptXRC4_40:
+	case CryptXRC4_128:
 		return "xRC4-128"
+	case CryptXRC4_40:
+		return "xRC4-40"
 	default:
 		return fmt.Sprintf("Unknown(%d)", a)
 	}
 }
 
 // Cipher Suite (Section 22.15.2)
-type cipherSuite struct {
-	Auth      authAlgorithm
-	Integrity integrityAlgorithm
-	Crypt     cryptAlgorithm
+type CipherSuite struct {
+	Auth      AuthAlgorithm
+	Integrity IntegrityAlgorithm
+	Crypt     CryptAlgorithm
 }
 
-func (c *cipherSuite) Equal(o *cipherSuite) bool {
+func (c *CipherSuite) Equal(o *CipherSuite) bool {
 	return c.Auth == o.Auth && c.Integrity == o.Integrity && c.Crypt == o.Crypt
 }
 
-func (c *cipherSuite) String() string {
+func (c *CipherSuite) String() string {
 	return fmt.Sprintf(`{"Auth":"%s","Integrity":"%s","Crypt":"%s"}`,
 		c.Auth, c.Integrity, c.Crypt)
 }
 
 // Cipher Suite IDs (Table 22-20)
-var cipherSuiteIDs []cipherSuite = []cipherSuite{
-	cipherSuite{authRakpNone, integrityNone, cryptNone},
-	cipherSuite{authRakpHmacSHA1, integrityNone, cryptNone},
-	cipherSuite{authRakpHmacSHA1, integrityHmacSHA1_96, cryptNone},
-	cipherSuite{authRakpHmacSHA1, integrityHmacSHA1_96, cryptAesCBC_128},
-	cipherSuite{authRakpHmacSHA1, integrityHmacSHA1_96, cryptXRC4_128},
-	cipherSuite{authRakpHmacSHA1, integrityHmacSHA1_96, cryptXRC4_40},
-	cipherSuite{authRakpHmacMD5, integrityNone, cryptNone},
-	cipherSuite{authRakpHmacMD5, integrityHmacMD5_128, cryptNone},
-	cipherSuite{authRakpHmacMD5, integrityHmacMD5_128, cryptAesCBC_128},
-	cipherSuite{authRakpHmacMD5, integrityHmacMD5_128, cryptXRC4_128},
-	cipherSuite{authRakpHmacMD5, integrityHmacMD5_128, cryptXRC4_40},
-	cipherSuite{authRakpHmacMD5, integrityMD5_128, cryptNone},
-	cipherSuite{authRakpHmacMD5, integrityMD5_128, cryptAesCBC_128},
-	cipherSuite{authRakpHmacMD5, integrityMD5_128, cryptXRC4_128},
-	cipherSuite{authRakpHmacMD5, integrityMD5_128, cryptXRC4_40},
+var cipherSuiteIDs []CipherSuite = []CipherSuite{
+	CipherSuite{AuthRakpNone, IntegrityNone, CryptNone},
+	CipherSuite{AuthRakpHmacSHA1, IntegrityNone, CryptNone},
+	CipherSuite{AuthRakpHmacSHA1, IntegrityHmacSHA1_96, CryptNone},
+	CipherSuite{AuthRakpHmacSHA1, IntegrityHmacSHA1_96, CryptAesCBC_128},
+	CipherSuite{AuthRakpHmacSHA1, IntegrityHmacSHA1_96, CryptXRC4_128},
+	CipherSuite{AuthRakpHmacSHA1, IntegrityHmacSHA1_96, CryptXRC4_40},
+	CipherSuite{AuthRakpHmacMD5, IntegrityNone, CryptNone},
+	CipherSuite{AuthRakpHmacMD5, IntegrityHmacMD5_128, CryptNone},
+	CipherSuite{AuthRakpHmacMD5, IntegrityHmacMD5_128, CryptAesCBC_128},
+	CipherSuite{AuthRakpHmacMD5, IntegrityHmacMD5_128, CryptXRC4_128},
+	CipherSuite{AuthRakpHmacMD5, IntegrityHmacMD5_128, CryptXRC4_40},
+	CipherSuite{AuthRakpHmacMD5, IntegrityMD5_128, CryptNone},
+	CipherSuite{AuthRakpHmacMD5, IntegrityMD5_128, CryptAesCBC_128},
+	CipherSuite{AuthRakpHmacMD5, IntegrityMD5_128, CryptXRC4_128},
+	CipherSuite{AuthRakpHmacMD5, IntegrityMD5_128, CryptXRC4_40},
+}
+
+// Returns the cipher suite of the ID (Table 22-20)
+func LookupCipherSuite(id uint) (*CipherSuite, error) {
+	if id >= uint(len(cipherSuiteIDs)) {
+		return nil, &ArgumentError{
+			Value:   id,
+			Message: "Invalid Cipher Suite ID",
+		}
+	}
+	c := cipherSuiteIDs[id]
+	return &c, nil
+}
+
+// Cipher Suite IDs that ipmigo supports, in order of preference
+var preferredCipherSuiteIDs []uint = []uint{3, 4, 2, 1, 8, 9, 7, 12, 13, 11, 6}
+
+// Returns the most preferred cipher suite ID in the IDs
+func selectCipherSuiteID(ids []uint) (uint, bool) {
+	for _, p := range preferredCipherSuiteIDs {
+		for _, id := range ids {
+			if p == id {
+				return id, true
+			}
+		}
+	}
+	return 0, false
 }
 
 // RMCP+ Open Session Request (Section 13.17)
@@ -267,7 +296,7 @@ type openSessionResponse struct {
 	PrivilegeLevel PrivilegeLevel
 	ConsoleID      uint32 // Remote console session ID
 	ManagedID      uint32 // Managed system session ID
-	CipherSuite    cipherSuite
+	CipherSuite    CipherSuite
 }
 
 func (o *openSessionResponse) Unmarshal(buf []byte) ([]byte, error) {
@@ -280,9 +309,9 @@ func (o *openSessionResponse) Unmarshal(buf []byte) ([]byte, error) {
 	o.PrivilegeLevel = PrivilegeLevel(buf[2])
 	o.ConsoleID = binary.LittleEndian.Uint32(buf[4:])
 	o.ManagedID = binary.LittleEndian.Uint32(buf[8:])
-	o.CipherSuite.Auth = authAlgorithm(buf[16])
-	o.CipherSuite.Integrity = integrityAlgorithm(buf[24])
-	o.CipherSuite.Crypt = cryptAlgorithm(buf[32])
+	o.CipherSuite.Auth = AuthAlgorithm(buf[16])
+	o.CipherSuite.Integrity = IntegrityAlgorithm(buf[24])
+	o.CipherSuite.Crypt = CryptAlgorithm(buf[32])
 	return buf[openSessionResponseSize:], nil
 }
 
@@ -293,6 +322,19 @@ func (o *openSessionResponse) String() string {
 		o.MessageTag, o.StatusCode, o.PrivilegeLevel, o.ConsoleID, o.ManagedID, &o.CipherSuite)
 }
 
+// Returns the message tag if the response is a RMCP+ session setup message
+func responseMessageTag(res response) (uint8, bool) {
+	switch r := res.(type) {
+	case *openSessionResponse:
+		return r.MessageTag, true
+	case *rakpMessage2:
+		return r.MessageTag, true
+	case *rakpMessage4:
+		return r.MessageTag, true
+	}
+	return 0, false
+}
+
 // RAKP Message 1 (Section 13.20)
 type rakpMessage1 struct {
 	MessageTag      uint8
@@ -352,16 +394,18 @@ type rakpMessage2 struct {
 	ConsoleID           uint32    // Remote console session ID
 	ManagedRand         [16]uint8 // Managed system random number
 	ManagedGUID         [16]uint8 // Managed system GUID
-	KeyExchangeAuthCode [authCodeSize]byte
+	KeyExchangeAuthCode []byte
+
+	reveal bool // Shows the secrets in String() and errors
 }
 
-func (r *rakpMessage2) ValidateAuthCode(args *Arguments, r1 *rakpMessage1) error {
-	if !requiredAuthentication(args.CipherSuiteID) {
+func (r *rakpMessage2) ValidateAuthCode(cid uint, password string, r1 *rakpMessage1) error {
+	if !requiredAuthentication(cid) {
 		return nil
 	}
 
 	key := make([]byte, passwordMaxLengthV2_0)
-	copy(key, args.Password)
+	copy(key, password)
 
 	data := make([]byte, 58+len(r1.Username))
 	binary.LittleEndian.PutUint32(data, r.ConsoleID)      // SIDm
@@ -373,13 +417,10 @@ func (r *rakpMessage2) ValidateAuthCode(args *Arguments, r1 *rakpMessage1) error
 	data[57] = byte(len(r1.Username))                     // ULENGTHm
 	copy(data[58:], r1.Username)                          // UNAMEm
 
-	mac := hmac.New(sha1.New, key)
-	mac.Write(data)
-
-	if s := mac.Sum(nil); !hmac.Equal(r.KeyExchangeAuthCode[:], s) {
+	if s := authHMAC(cid, key, data); !hmac.Equal(r.KeyExchangeAuthCode, s) {
 		return &MessageError{
 			Message: fmt.Sprintf("RAKP 2 HMAC is invalid : %s - %s",
-				hex.EncodeToString(r.KeyExchangeAuthCode[:]), hex.EncodeToString(s)),
+				secretString(r.KeyExchangeAuthCode, r.reveal), secretString(s, r.reveal)),
 			Detail: r.String(),
 		}
 	}
@@ -397,9 +438,9 @@ func (r *rakpMessage2) Unmarshal(buf []byte) ([]byte, error) {
 	r.ConsoleID = binary.LittleEndian.Uint32(buf[4:])
 	copy(r.ManagedRand[:], buf[8:24])
 	copy(r.ManagedGUID[:], buf[24:40])
-	copy(r.KeyExchangeAuthCode[:], buf[40:])
+	r.KeyExchangeAuthCode = append([]byte(nil), buf[40:]...)
 
-	return buf[size:], nil
+	return nil, nil
 }
 
 func (r *rakpMessage2) String() string {
@@ -407,7 +448,7 @@ func (r *rakpMessage2) String() string {
 		`{"MessageTag":%d,"StatusCode":"%s","ConsoleID":%d,`+
 			`"ManagedRand":"%s","ManagedGUID":"%s","KeyExchangeAuthCode":"%s"}`,
 		r.MessageTag, r.StatusCode, r.ConsoleID, hex.EncodeToString(r.ManagedRand[:]),
-		hex.EncodeToString(r.ManagedGUID[:]), hex.EncodeToString(r.KeyExchangeAuthCode[:]))
+		hex.EncodeToString(r.ManagedGUID[:]), secretString(r.KeyExchangeAuthCode, r.reveal))
 }
 
 // RAKP Message 3 (Section 13.22)
@@ -415,20 +456,22 @@ type rakpMessage3 struct {
 	MessageTag          uint8
 	StatusCode          rakpStatusCode
 	ManagedID           uint32
-	KeyExchangeAuthCode [authCodeSize]byte
+	KeyExchangeAuthCode []byte
+
+	SIK []byte // Session Integrity Key
+	K1  []byte
+	K2  []byte
 
-	SIK [sikSize]byte // Session Integrity Key
-	K1  [sikSize]byte
-	K2  [sikSize]byte
+	reveal bool // Shows the secrets in String()
 }
 
-func (r *rakpMessage3) GenerateAuthCode(args *Arguments, r1 *rakpMessage1, r2 *rakpMessage2) {
-	if !requiredAuthentication(args.CipherSuiteID) {
+func (r *rakpMessage3) GenerateAuthCode(cid uint, password string, r1 *rakpMessage1, r2 *rakpMessage2) {
+	if !requiredAuthentication(cid) {
 		return
 	}
 
 	key := make([]byte, passwordMaxLengthV2_0)
-	copy(key, args.Password)
+	copy(key, password)
 
 	data := make([]byte, 22+len(r1.Username))
 	copy(data, r2.ManagedRand[:])                          // Rc
@@ -437,19 +480,17 @@ func (r *rakpMessage3) GenerateAuthCode(args *Arguments, r1 *rakpMessage1, r2 *r
 	data[21] = byte(len(r1.Username))                      // ULENGTHm
 	copy(data[22:], r1.Username)                           // UNAMEm
 
-	mac := hmac.New(sha1.New, key)
-	mac.Write(data)
-	copy(r.KeyExchangeAuthCode[:], mac.Sum(nil))
+	r.KeyExchangeAuthCode = authHMAC(cid, key, data)
 }
 
-func (r *rakpMessage3) GenerateSIK(args *Arguments, r1 *rakpMessage1, r2 *rakpMessage2) {
-	if !requiredAuthentication(args.CipherSuiteID) {
+func (r *rakpMessage3) GenerateSIK(cid uint, password string, r1 *rakpMessage1, r2 *rakpMessage2) {
+	if !requiredAuthentication(cid) {
 		return
 	}
 
 	// Not support KG key
 	key := make([]byte, passwordMaxLengthV2_0)
-	copy(key, args.Password)
+	copy(key, password)
 
 	data := make([]byte, 34+len(r1.Username))
 	copy(data, r1.ConsoleRand[:])      // Rm
@@ -458,35 +499,23 @@ func (r *rakpMessage3) GenerateSIK(args *Arguments, r1 *rakpMessage1, r2 *rakpMe
 	data[33] = byte(len(r1.Username))  // ULENGTHm
 	copy(data[34:], r1.Username)       // UNAMEm
 
-	mac := hmac.New(sha1.New, key)
-	mac.Write(data)
-	copy(r.SIK[:], mac.Sum(nil))
+	r.SIK = authHMAC(cid, key, data)
 }
 
-func (r *rakpMessage3) GenerateK1(args *Arguments) {
-	if !requiredAuthentication(args.CipherSuiteID) {
+func (r *rakpMessage3) GenerateK1(cid uint) {
+	if !requiredAuthentication(cid) {
 		return
 	}
 
-	key := make([]byte, len(r.SIK))
-	copy(key, r.SIK[:])
-
-	mac := hmac.New(sha1.New, key)
-	mac.Write(const1[:])
-	copy(r.K1[:], mac.Sum(nil))
+	r.K1 = authHMAC(cid, r.SIK, const1[:])
 }
 
-func (r *rakpMessage3) GenerateK2(args *Arguments) {
-	if !requiredAuthentication(args.CipherSuiteID) {
+func (r *rakpMessage3) GenerateK2(cid uint) {
+	if !requiredAuthentication(cid) {
 		return
 	}
 
-	key := make([]byte, len(r.SIK))
-	copy(key, r.SIK[:])
-
-	mac := hmac.New(sha1.New, key)
-	mac.Write(const2[:])
-	copy(r.K2[:], mac.Sum(nil))
+	r.K2 = authHMAC(cid, r.SIK, const2[:])
 }
 
 func (r *rakpMessage3) Marshal() ([]byte, error) {
@@ -498,7 +527,7 @@ func (r *rakpMessage3) Marshal() ([]byte, error) {
 	// buf[2] = 0 // reserved
 	// buf[3] = 0 // reserved
 	binary.LittleEndian.PutUint32(buf[4:], r.ManagedID)
-	copy(buf[8:], r.KeyExchangeAuthCode[:])
+	copy(buf[8:], r.KeyExchangeAuthCode)
 
 	return buf, nil
 }
@@ -506,35 +535,37 @@ func (r *rakpMessage3) Marshal() ([]byte, error) {
 func (r *rakpMessage3) String() string {
 	return fmt.Sprintf(
 		`{"MessageTag":%d,"StatusCode":"%s","ManagedID":%d,"KeyExchangeAuthCode":"%s"}`,
-		r.MessageTag, r.StatusCode, r.ManagedID, hex.EncodeToString(r.KeyExchangeAuthCode[:]))
+		r.MessageTag, r.StatusCode, r.ManagedID, secretString(r.KeyExchangeAuthCode, r.reveal))
 }
 
 type rakpMessage4 struct {
 	MessageTag          uint8
 	StatusCode          rakpStatusCode
 	ConsoleID           uint32 // Remote console session ID
-	IntegrityCheckValue [integrityCheckSize]byte
+	IntegrityCheckValue []byte
+
+	reveal bool // Shows the secrets in String() and errors
 }
 
-func (r *rakpMessage4) ValidateAuthCode(args *Arguments, r1 *rakpMessage1, r2 *rakpMessage2, r3 *rakpMessage3) error {
-	if !requiredAuthentication(args.CipherSuiteID) {
+func (r *rakpMessage4) ValidateAuthCode(cid uint, r1 *rakpMessage1, r2 *rakpMessage2, r3 *rakpMessage3) error {
+	if !requiredAuthentication(cid) {
 		return nil
 	}
 
-	key := make([]byte, len(r3.SIK))
-	copy(key, r3.SIK[:])
-
 	data := make([]byte, 36)
 	copy(data, r1.ConsoleRand[:])                          // Rm
 	binary.LittleEndian.PutUint32(data[16:], r1.ManagedID) // SIDc
 	copy(data[20:], r2.ManagedGUID[:])                     // GUIDc
 
-	mac := hmac.New(sha1.New, key)
-	mac.Write(data)
-	if s := mac.Sum(nil)[:integrityCheckSize]; !hmac.Equal(r.IntegrityCheckValue[:], s) {
+	s := authHMAC(cid, r3.SIK, data)
+	if cipherSuiteIDs[cid].Auth == AuthRakpHmacSHA1 {
+		// HMAC-SHA1-96 is used for RAKP-HMAC-SHA1
+		s = s[:hmacSHA1_96Size]
+	}
+	if !hmac.Equal(r.IntegrityCheckValue, s) {
 		return &MessageError{
 			Message: fmt.Sprintf("RAKP 4 HMAC is invalid : %s - %s",
-				hex.EncodeToString(r.IntegrityCheckValue[:]), hex.EncodeToString(s)),
+				secretString(r.IntegrityCheckValue, r.reveal), secretString(s, r.reveal)),
 			Detail: r.String(),
 		}
 	}
@@ -542,7 +573,7 @@ func (r *rakpMessage4) ValidateAuthCode(args *Arguments, r1 *rakpMessage1, r2 *r
 }
 
 func (r *rakpMessage4) Unmarshal(buf []byte) ([]byte, error) {
-	size := rakpMessage4Size + len(r.IntegrityCheckValue)
+	size := rakpMessage4Size
 	if l := len(buf); l < size {
 		buf = append(buf, make([]byte, size-l)...)
 	}
@@ -550,46 +581,95 @@ func (r *rakpMessage4) Unmarshal(buf []byte) ([]byte, error) {
 	r.MessageTag = buf[0]
 	r.StatusCode = rakpStatusCode(buf[1])
 	r.ConsoleID = binary.LittleEndian.Uint32(buf[4:])
-	copy(r.IntegrityCheckValue[:], buf[8:])
+	r.IntegrityCheckValue = append([]byte(nil), buf[8:]...)
 
-	return buf[size:], nil
+	return nil, nil
 }
 
 func (r *rakpMessage4) String() string {
 	return fmt.Sprintf(
 		`{"MessageTag":%d,"StatusCode":"%s","ConsoleID":%d,"IntegrityCheckValue":"%s"}`,
-		r.MessageTag, r.StatusCode, r.ConsoleID, hex.EncodeToString(r.IntegrityCheckValue[:]))
+		r.MessageTag, r.StatusCode, r.ConsoleID, secretString(r.IntegrityCheckValue, r.reveal))
 }
 
 func requiredAuthentication(cid uint) bool {
 	switch suite := cipherSuiteIDs[cid]; suite.Auth {
 	default:
 		panic(`ipmigo: unsupported authentication algorithm - ` + suite.Auth.String())
-	case authRakpNone:
+	case AuthRakpNone:
 		return false
-	case authRakpHmacSHA1:
+	case AuthRakpHmacSHA1, AuthRakpHmacMD5:
 		return true
 	}
 }
 
+// Returns the HMAC of the authentication algorithm (Section 13.28)
+func authHMAC(cid uint, key, data []byte) []byte {
+	var h func() hash.Hash
+	switch suite := cipherSuiteIDs[cid]; suite.Auth {
+	case AuthRakpHmacSHA1:
+		h = sha1.New
+	case AuthRakpHmacMD5:
+		h = md5.New
+	default:
+		panic(`ipmigo: unsupported authentication algorithm - ` + suite.Auth.String())
+	}
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
 func requiredIntegrity(cid uint) bool {
 	switch suite := cipherSuiteIDs[cid]; suite.Integrity {
 	default:
 		panic(`ipmigo: unsupported integrity algorithm - ` + suite.Integrity.String())
-	case integrityNone:
+	case IntegrityNone:
 		return false
-	case integrityHmacSHA1_96:
+	case IntegrityHmacSHA1_96, IntegrityHmacMD5_128, IntegrityMD5_128:
 		return true
 	}
 }
 
+// Returns the size of the AuthCode of the session trailer (Section 13.28.4)
+func integrityCodeSize(cid uint) int {
+	if cipherSuiteIDs[cid].Integrity == IntegrityHmacSHA1_96 {
+		return hmacSHA1_96Size
+	}
+	return md5.Size
+}
+
+// Returns the AuthCode of the session trailer (Section 13.28.4).
+// MD5-128 uses the password instead of K1.
+func integrityCode(cid uint, k1 []byte, password string, data []byte) []byte {
+	switch suite := cipherSuiteIDs[cid]; suite.Integrity {
+	case IntegrityHmacSHA1_96:
+		mac := hmac.New(sha1.New, k1)
+		mac.Write(data)
+		return mac.Sum(nil)[:hmacSHA1_96Size]
+	case IntegrityHmacMD5_128:
+		mac := hmac.New(md5.New, k1)
+		mac.Write(data)
+		return mac.Sum(nil)
+	case IntegrityMD5_128:
+		key := make([]byte, passwordMaxLengthV2_0)
+		copy(key, password)
+		h := md5.New()
+		h.Write(key)
+		h.Write(data)
+		h.Write(key)
+		return h.Sum(nil)
+	default:
+		panic(`ipmigo: unsupported integrity algorithm - ` + suite.Integrity.String())
+	}
+}
+
 func requiredConfidentiality(cid uint) bool {
 	switch suite := cipherSuiteIDs[cid]; suite.Crypt {
 	default:
 		panic(`ipmigo: unsupported confidentiality algorithm - ` + suite.Crypt.String())
-	case cryptNone:
+	case CryptNone:
 		return false
-	case cryptAesCBC_128:
+	case CryptAesCBC_128, CryptXRC4_128, CryptXRC4_40:
 		return true
 	}
 }
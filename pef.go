@@ -0,0 +1,320 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Parameter of the PEF configuration (Table 30-6)
+type PEFConfigParameter interface {
+	Selector() uint8
+	Marshal() ([]byte, error)
+	Unmarshal(buf []byte) ([]byte, error)
+}
+
+// PEF configuration parameter selectors (Table 30-6)
+const (
+	PEFParamSetInProgress       uint8 = 0
+	PEFParamControl             uint8 = 1
+	PEFParamActionGlobalControl uint8 = 2
+	PEFParamStartupDelay        uint8 = 3
+	PEFParamAlertStartupDelay   uint8 = 4
+	PEFParamEventFilterCount    uint8 = 5
+	PEFParamEventFilter         uint8 = 6
+	PEFParamEventFilterData1    uint8 = 7
+	PEFParamAlertPolicyCount    uint8 = 8
+	PEFParamAlertPolicy         uint8 = 9
+	PEFParamSystemGUID          uint8 = 10
+	PEFParamAlertStringCount    uint8 = 11
+	PEFParamAlertStringKeys     uint8 = 12
+	PEFParamAlertStrings        uint8 = 13
+)
+
+const (
+	pefEventFilterSize      = 20
+	pefAlertPolicySize      = 3
+	pefAlertStringBlockSize = 16
+
+	pefEventFilterTypePreconfigured = 0x02
+)
+
+// Event filter action for the group control operation (Table 30-2)
+const PEFActionGroupControl PEFAction = 1 << 6
+
+// Set In Progress parameter (Selector 0)
+type PEFSetInProgress struct {
+	State uint8 // (0x00: Set complete, 0x01: Set in progress, 0x02: Commit write)
+}
+
+func (p *PEFSetInProgress) Selector() uint8          { return PEFParamSetInProgress }
+func (p *PEFSetInProgress) Marshal() ([]byte, error) { return []byte{p.State & 0x03}, nil }
+
+func (p *PEFSetInProgress) Unmarshal(buf []byte) ([]byte, error) {
+	if err := pefValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.State = buf[0] & 0x03
+	return buf[1:], nil
+}
+
+// PEF Control parameter (Selector 1)
+type PEFControl struct {
+	Enabled           bool // Enable PEF
+	EventMessages     bool // Generate event messages for PEF actions
+	StartupDelay      bool // Enable PEF startup delay
+	AlertStartupDelay bool // Enable PEF alert startup delay
+}
+
+func (p *PEFControl) Selector() uint8 { return PEFParamControl }
+
+func (p *PEFControl) Marshal() ([]byte, error) {
+	var b byte
+	if p.Enabled {
+		b |= 0x01
+	}
+	if p.EventMessages {
+		b |= 0x02
+	}
+	if p.StartupDelay {
+		b |= 0x04
+	}
+	if p.AlertStartupDelay {
+		b |= 0x08
+	}
+	return []byte{b}, nil
+}
+
+func (p *PEFControl) Unmarshal(buf []byte) ([]byte, error) {
+	if err := pefValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.Enabled = buf[0]&0x01 != 0
+	p.EventMessages = buf[0]&0x02 != 0
+	p.StartupDelay = buf[0]&0x04 != 0
+	p.AlertStartupDelay = buf[0]&0x08 != 0
+	return buf[1:], nil
+}
+
+// Mask and compare values of an event data byte (Table 30-2)
+type PEFEventDataMask struct {
+	AND      uint8 // Bits to be compared
+	Compare1 uint8 // Bits that must match exactly (1b) or may match any (0b)
+	Compare2 uint8 // Value to be compared
+}
+
+// Event Filter Table entry parameter (Selector 6, Table 30-2)
+type PEFEventFilter struct {
+	Set uint8 // Filter number (1-based)
+
+	Enabled              bool
+	Preconfigured        bool // Manufacturer pre-configured filter (false: Software configurable)
+	Actions              PEFAction
+	AlertPolicy          uint8 // Policy number of the alert policy table
+	GroupControlSelector uint8
+	Severity             uint8 // Event severity (Table 30-3)
+	GeneratorAddress     uint8 // Slave address or software ID of the generator (0xff: Any)
+	GeneratorChannelLUN  uint8 // Channel and LUN of the generator (0xff: Any)
+	SensorType           SensorType
+	SensorNumber         uint8 // (0xff: Any)
+	EventTrigger         uint8 // Event/reading type (0xff: Any)
+	EventOffsetMask      uint16
+	EventData            [3]PEFEventDataMask
+}
+
+func (p *PEFEventFilter) Selector() uint8 { return PEFParamEventFilter }
+
+func (p *PEFEventFilter) Marshal() ([]byte, error) {
+	buf := make([]byte, 1+pefEventFilterSize)
+	buf[0] = p.Set & 0x7f
+	if p.Enabled {
+		buf[1] |= 0x80
+	}
+	if p.Preconfigured {
+		buf[1] |= pefEventFilterTypePreconfigured << 5
+	}
+	buf[2] = byte(p.Actions) & 0x7f
+	buf[3] = p.GroupControlSelector&0x07<<4 | p.AlertPolicy&0x0f
+	buf[4] = p.Severity
+	buf[5] = p.GeneratorAddress
+	buf[6] = p.GeneratorChannelLUN
+	buf[7] = byte(p.SensorType)
+	buf[8] = p.SensorNumber
+	buf[9] = p.EventTrigger
+	buf[10] = byte(p.EventOffsetMask)
+	buf[11] = byte(p.EventOffsetMask >> 8)
+	for i, d := range p.EventData {
+		buf[12+i*3] = d.AND
+		buf[13+i*3] = d.Compare1
+		buf[14+i*3] = d.Compare2
+	}
+	return buf, nil
+}
+
+func (p *PEFEventFilter) Unmarshal(buf []byte) ([]byte, error) {
+	if err := pefValidateLength(p, buf, 1+pefEventFilterSize); err != nil {
+		return nil, err
+	}
+	p.Set = buf[0] & 0x7f
+	p.Enabled = buf[1]&0x80 != 0
+	p.Preconfigured = buf[1]>>5&0x03 == pefEventFilterTypePreconfigured
+	p.Actions = PEFAction(buf[2] & 0x7f)
+	p.GroupControlSelector = buf[3] >> 4 & 0x07
+	p.AlertPolicy = buf[3] & 0x0f
+	p.Severity = buf[4]
+	p.GeneratorAddress = buf[5]
+	p.GeneratorChannelLUN = buf[6]
+	p.SensorType = SensorType(buf[7])
+	p.SensorNumber = buf[8]
+	p.EventTrigger = buf[9]
+	p.EventOffsetMask = uint16(buf[10]) | uint16(buf[11])<<8
+	for i := range p.EventData {
+		p.EventData[i] = PEFEventDataMask{AND: buf[12+i*3], Compare1: buf[13+i*3], Compare2: buf[14+i*3]}
+	}
+	return buf[1+pefEventFilterSize:], nil
+}
+
+// Alert Policy Table entry parameter (Selector 9, Table 30-9)
+type PEFAlertPolicy struct {
+	Set uint8 // Entry number (1-based)
+
+	PolicyNumber        uint8
+	Enabled             bool
+	Policy              uint8 // (0: Always send, 1: Proceed to next entry if succeeded, ...)
+	Channel             uint8
+	Destination         uint8 // Destination selector of the channel
+	EventSpecificString bool  // Alert string is looked up by the event filter (false: AlertStringSet is used)
+	AlertStringSet      uint8
+}
+
+func (p *PEFAlertPolicy) Selector() uint8 { return PEFParamAlertPolicy }
+
+func (p *PEFAlertPolicy) Marshal() ([]byte, error) {
+	buf := make([]byte, 1+pefAlertPolicySize)
+	buf[0] = p.Set & 0x7f
+	buf[1] = p.PolicyNumber&0x0f<<4 | p.Policy&0x07
+	if p.Enabled {
+		buf[1] |= 0x08
+	}
+	buf[2] = p.Channel&0x0f<<4 | p.Destination&0x0f
+	buf[3] = p.AlertStringSet & 0x7f
+	if p.EventSpecificString {
+		buf[3] |= 0x80
+	}
+	return buf, nil
+}
+
+func (p *PEFAlertPolicy) Unmarshal(buf []byte) ([]byte, error) {
+	if err := pefValidateLength(p, buf, 1+pefAlertPolicySize); err != nil {
+		return nil, err
+	}
+	p.Set = buf[0] & 0x7f
+	p.PolicyNumber = buf[1] >> 4
+	p.Enabled = buf[1]&0x08 != 0
+	p.Policy = buf[1] & 0x07
+	p.Channel = buf[2] >> 4
+	p.Destination = buf[2] & 0x0f
+	p.EventSpecificString = buf[3]&0x80 != 0
+	p.AlertStringSet = buf[3] & 0x7f
+	return buf[1+pefAlertPolicySize:], nil
+}
+
+// Alert String Keys parameter (Selector 12)
+type PEFAlertStringKeys struct {
+	Set uint8 // String selector (0: Volatile string)
+
+	EventFilter    uint8 // Filter number (0: Unused)
+	AlertStringSet uint8
+}
+
+func (p *PEFAlertStringKeys) Selector() uint8 { return PEFParamAlertStringKeys }
+
+func (p *PEFAlertStringKeys) Marshal() ([]byte, error) {
+	return []byte{p.Set & 0x7f, p.EventFilter & 0x7f, p.AlertStringSet & 0x7f}, nil
+}
+
+func (p *PEFAlertStringKeys) Unmarshal(buf []byte) ([]byte, error) {
+	if err := pefValidateLength(p, buf, 3); err != nil {
+		return nil, err
+	}
+	p.Set = buf[0] & 0x7f
+	p.EventFilter = buf[1] & 0x7f
+	p.AlertStringSet = buf[2] & 0x7f
+	return buf[3:], nil
+}
+
+// Alert Strings parameter (Selector 13), which is a block of the null-terminated string
+type PEFAlertString struct {
+	Set   uint8 // String selector (0: Volatile string)
+	Block uint8 // Block number (1-based)
+	Data  []byte
+}
+
+func (p *PEFAlertString) Selector() uint8 { return PEFParamAlertStrings }
+
+func (p *PEFAlertString) Marshal() ([]byte, error) {
+	if len(p.Data) > pefAlertStringBlockSize {
+		return nil, &ArgumentError{
+			Value:   p.Data,
+			Message: fmt.Sprintf("Alert string block is longer than %d bytes", pefAlertStringBlockSize),
+		}
+	}
+	return append([]byte{p.Set & 0x7f, p.Block}, p.Data...), nil
+}
+
+func (p *PEFAlertString) Unmarshal(buf []byte) ([]byte, error) {
+	if err := pefValidateLength(p, buf, 2); err != nil {
+		return nil, err
+	}
+	p.Set = buf[0] & 0x7f
+	p.Block = buf[1]
+	p.Data = make([]byte, len(buf)-2)
+	copy(p.Data, buf[2:])
+	return nil, nil
+}
+
+// PEF configuration parameter which is not decoded
+type PEFConfigRaw struct {
+	ID   uint8 // Parameter selector
+	Data []byte
+}
+
+func (p *PEFConfigRaw) Selector() uint8          { return p.ID }
+func (p *PEFConfigRaw) Marshal() ([]byte, error) { return p.Data, nil }
+
+func (p *PEFConfigRaw) Unmarshal(buf []byte) ([]byte, error) {
+	p.Data = make([]byte, len(buf))
+	copy(p.Data, buf)
+	return nil, nil
+}
+
+func pefValidateLength(p PEFConfigParameter, buf []byte, min int) error {
+	if l := len(buf); l < min {
+		return &MessageError{
+			Message: fmt.Sprintf("Invalid PEF configuration parameter %d size : %d/%d", p.Selector(), l, min),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	return nil
+}
+
+// Returns the alert string of the string selector, which is read block by block until the null terminator.
+func GetPEFAlertString(c *Client, set uint8) (string, error) {
+	var s []byte
+	for block := uint8(1); block != 0; block++ {
+		p := &PEFAlertString{}
+		cmd := &GetPEFConfigParametersCommand{SetSelector: set, BlockSelector: block, Parameter: p}
+		if err := c.Execute(cmd); err != nil {
+			return "", err
+		}
+		for _, b := range p.Data {
+			if b == 0 {
+				return string(s), nil
+			}
+			s = append(s, b)
+		}
+		if len(p.Data) < pefAlertStringBlockSize {
+			break
+		}
+	}
+	return string(s), nil
+}
@@ -0,0 +1,108 @@
+package ipmigo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	petPayloadMinSize = 47
+	petEpochOffset    = 883612800 // Seconds from 1970-01-01 to 1998-01-01
+)
+
+// Platform Event Trap (PET v1.0), which is the variable binding of the SNMP trap sent by PEF
+type PETEvent struct {
+	GUID           [16]byte
+	Sequence       uint16    // Sequence number or cookie
+	Timestamp      Timestamp // Local time of the event
+	UTCOffset      int16     // Offset from UTC in minutes (-1: Unspecified)
+	TrapSource     uint8
+	EventSource    uint8
+	Severity       uint8
+	SensorDevice   uint8 // Slave address or software ID of the sensor owner
+	SensorNumber   uint8
+	Entity         EntityID
+	EntityInstance uint8
+	EventData      [8]byte
+	LanguageCode   uint8
+	ManufacturerID uint32
+	SystemID       uint16
+	OEMCustom      []byte
+
+	// Fields taken from the specific trap number
+	SensorType SensorType
+	EventType  EventType
+	EventDir   uint8 // (0: Assertion, 1: Deassertion)
+}
+
+// Decodes the specific trap number and the payload of the PET variable binding.
+func DecodePET(specificTrap uint32, payload []byte) (*PETEvent, error) {
+	if l := len(payload); l < petPayloadMinSize {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid PET size : %d/%d", l, petPayloadMinSize),
+			Detail:  hex.EncodeToString(payload),
+		}
+	}
+
+	// Multi-byte fields are MSB first
+	e := &PETEvent{
+		Sequence:       binary.BigEndian.Uint16(payload[16:18]),
+		UTCOffset:      int16(binary.BigEndian.Uint16(payload[22:24])),
+		TrapSource:     payload[24],
+		EventSource:    payload[25],
+		Severity:       payload[26],
+		SensorDevice:   payload[27],
+		SensorNumber:   payload[28],
+		Entity:         EntityID(payload[29]),
+		EntityInstance: payload[30],
+		LanguageCode:   payload[39],
+		ManufacturerID: binary.BigEndian.Uint32(payload[40:44]),
+		SystemID:       binary.BigEndian.Uint16(payload[44:46]),
+		SensorType:     SensorType(specificTrap >> 16),
+		EventType:      EventType(specificTrap >> 8 & 0x7f),
+		EventDir:       uint8(specificTrap >> 7 & 0x01),
+	}
+	copy(e.GUID[:], payload[0:16])
+	copy(e.EventData[:], payload[31:39])
+
+	if t := binary.BigEndian.Uint32(payload[18:22]); t == 0 {
+		e.Timestamp.Value = timestampUnspecified
+	} else {
+		e.Timestamp.Value = t + petEpochOffset
+	}
+
+	// The OEM custom fields are terminated by 0xc1
+	if oem := payload[46:]; len(oem) > 0 && oem[0] != 0xc1 {
+		e.OEMCustom = make([]byte, len(oem))
+		copy(e.OEMCustom, oem)
+	}
+	return e, nil
+}
+
+// Returns the event as SEL event record, which provides the event descriptions
+func (e *PETEvent) SELEventRecord() *SELEventRecord {
+	r := &SELEventRecord{
+		RecordType:   0x02, // System event record
+		Timestamp:    e.Timestamp,
+		GeneratorID:  uint16(e.SensorDevice),
+		EvMRev:       0x04,
+		SensorType:   e.SensorType,
+		SensorNumber: e.SensorNumber,
+		EventType:    e.EventType,
+		EventDir:     e.EventDir,
+		EventData1:   e.EventData[0],
+		EventData2:   e.EventData[1],
+		EventData3:   e.EventData[2],
+	}
+	r.data = []byte{0, 0, byte(r.RecordType),
+		byte(r.Timestamp.Value), byte(r.Timestamp.Value >> 8), byte(r.Timestamp.Value >> 16), byte(r.Timestamp.Value >> 24),
+		byte(r.GeneratorID), byte(r.GeneratorID >> 8), r.EvMRev, byte(r.SensorType), r.SensorNumber,
+		r.EventDir<<7 | byte(r.EventType), r.EventData1, r.EventData2, r.EventData3}
+	return r
+}
+
+// Returns event description.
+func (e *PETEvent) Description() string {
+	return e.SELEventRecord().Description()
+}
@@ -0,0 +1,105 @@
+package ipmigo
+
+import (
+	"time"
+)
+
+// Upper limit of the outstanding requests, which is less than the number of RqSeq
+const pipelineWindowMax = 16
+
+// A session which can have several requests outstanding at once
+type pipelineSession interface {
+	executePipelined([]Command, Options) []error
+}
+
+// Sends all requests and then receives the responses in any order, matched by RqSeq.
+// Returns the error of each command. The failed commands are not retried.
+func executePipelined(cmds []Command, opts Options, newRequest func(Command) *ipmiPacket,
+	write func(*ipmiPacket, time.Duration) error, recv func(time.Duration) (*ipmiPacket, error)) []error {
+
+	errs := make([]error, len(cmds))
+	reqs := make([]*ipmiRequestMessage, len(cmds))
+	pending := 0
+
+	deadline := time.Now().Add(opts.Timeout)
+	for i, cmd := range cmds {
+		req := newRequest(cmd)
+		if err := write(req, opts.Timeout); err != nil {
+			errs[i] = err
+			continue
+		}
+		reqs[i] = req.Request.(*ipmiRequestMessage)
+		pending++
+	}
+
+	for pending > 0 {
+		timeout := deadline.Sub(time.Now())
+		if timeout <= 0 {
+			failPipelined(errs, reqs, errResponseTimeout)
+			break
+		}
+
+		pkt, err := recv(timeout)
+		if err != nil {
+			if discardable(err) {
+				// Discard a broken or unexpected datagram
+				continue
+			}
+			failPipelined(errs, reqs, err)
+			break
+		}
+
+		// Stale responses are discarded
+		for i, rqm := range reqs {
+			if rqm == nil || !matchResponse(rqm, pkt) {
+				continue
+			}
+			reqs[i] = nil
+			pending--
+
+			if rsm, err := commandResponse(pkt, cmds[i]); err != nil {
+				errs[i] = err
+			} else {
+				_, errs[i] = cmdUnmarshal(cmds[i], rsm.Data)
+			}
+			break
+		}
+	}
+	return errs
+}
+
+func failPipelined(errs []error, reqs []*ipmiRequestMessage, err error) {
+	for i, rqm := range reqs {
+		if rqm != nil {
+			errs[i] = err
+		}
+	}
+}
+
+// Executes the commands with overlapping the round trips, up to `Arguments.PipelineWindow` commands at once.
+// The commands are executed one by one if the session can not pipeline them.
+func (c *Client) executePipelined(cmds []Command) []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	opts := c.args.options()
+	errs := make([]error, len(cmds))
+
+	window := int(c.args.PipelineWindow)
+	ps, ok := c.session.(pipelineSession)
+	if !ok || window <= 1 || c.bridged() {
+		for i, cmd := range cmds {
+			errs[i] = c.session.Execute(c.bridge(cmd), opts)
+		}
+		return errs
+	}
+
+	for n := 0; n < len(cmds); n += window {
+		end := n + window
+		if end > len(cmds) {
+			end = len(cmds)
+		}
+		copy(errs[n:end], ps.executePipelined(cmds[n:end], opts))
+	}
+	return errs
+}
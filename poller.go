@@ -0,0 +1,66 @@
+package ipmigo
+
+import (
+	"sync"
+	"time"
+)
+
+// A poller calls the function from a goroutine at the interval until it is stopped
+type poller struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// Starts calling `f` at the interval, and at once if `now` is true.
+// The error of `f` is kept as the last error, and `cleanup` (if not nil) is called when polling is stopped.
+func newPoller(interval time.Duration, now bool, f func(stop <-chan struct{}) error, cleanup func()) *poller {
+	p := &poller{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go p.run(interval, now, f, cleanup)
+	return p
+}
+
+func (p *poller) run(interval time.Duration, now bool, f func(stop <-chan struct{}) error, cleanup func()) {
+	defer close(p.done)
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if now {
+			err := f(p.stop)
+			p.mu.Lock()
+			p.err = err
+			p.mu.Unlock()
+		}
+		now = true
+
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns the last error of `f`
+func (p *poller) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Stops polling and waits until `f` returns. It is safe to call more than once.
+func (p *poller) Stop() {
+	p.once.Do(func() { close(p.stop) })
+	<-p.done
+}
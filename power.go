@@ -0,0 +1,79 @@
+package ipmigo
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	powerPollInterval = time.Second
+)
+
+// Powers on the chassis and waits until the power is on.
+func (c *Client) PowerOn(ctx context.Context) error {
+	return c.setPowerState(ctx, ChassisControlPowerUp, true)
+}
+
+// Powers off the chassis and waits until the power is off.
+// A soft shutdown is requested to the OS (via ACPI) unless `force` is true.
+func (c *Client) PowerOff(ctx context.Context, force bool) error {
+	ctl := ChassisControlSoftShutdown
+	if force {
+		ctl = ChassisControlPowerDown
+	}
+	return c.setPowerState(ctx, ctl, false)
+}
+
+// Power cycles the chassis. The power is turned off and then back on after a delay.
+func (c *Client) PowerCycle(ctx context.Context) error {
+	return c.chassisControl(ctx, ChassisControlPowerCycle)
+}
+
+// Hard resets the chassis.
+func (c *Client) Reset(ctx context.Context) error {
+	return c.chassisControl(ctx, ChassisControlHardReset)
+}
+
+// Polls the chassis status until the power state is the desired one (true: on, false: off),
+// or the context is done.
+func (c *Client) WaitForPowerState(ctx context.Context, on bool) error {
+	ticker := time.NewTicker(powerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Errors are ignored because BMC may not respond during the transition
+		cmd := &GetChassisStatusCommand{}
+		if err := c.Execute(cmd); err == nil && cmd.PowerIsOn == on {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) setPowerState(ctx context.Context, ctl ChassisControl, on bool) error {
+	status := &GetChassisStatusCommand{}
+	if err := c.Execute(status); err != nil {
+		return err
+	}
+	if status.PowerIsOn == on {
+		return nil
+	}
+
+	if err := c.chassisControl(ctx, ctl); err != nil {
+		return err
+	}
+	return c.WaitForPowerState(ctx, on)
+}
+
+// Executes the chassis control command, which is not retried because it is not idempotent
+func (c *Client) chassisControl(ctx context.Context, ctl ChassisControl) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Execute(&ChassisControlCommand{Control: ctl})
+}
@@ -1,24 +1,38 @@
 package ipmigo
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math"
+	"sync"
+	"time"
 )
 
 const (
 	sdrFirstID uint16 = 0x0000
 	sdrLastID  uint16 = 0xffff
 
-	sdrIDStringMaxSize  = 16
-	sdrHeaderSize       = 5
-	sdrDefaultReadBytes = 32
-
-	sdrCommonSensorSize     = 18
-	sdrFullSensorSize       = 25 + sdrCommonSensorSize
-	sdrCompactSensorSize    = 9 + sdrCommonSensorSize
-	sdrFRUDeviceLocatorSize = 11
+	sdrIDStringMaxSize   = 16
+	sdrHeaderSize        = 5
+	sdrDefaultReadBytes  = 32
+	sdrDefaultWriteBytes = 16
+	sdrReadBytesStep     = 8
+	sdrReadGrowAfter     = 64 // Successful reads before trying the larger chunk
+
+	sdrClearPollInterval = time.Second
+	sdrReserveRetries    = 3 // Retries of the reservation while writing SDR repository
+
+	sdrCommonSensorSize            = 18
+	sdrFullSensorSize              = 25 + sdrCommonSensorSize
+	sdrCompactSensorSize           = 9 + sdrCommonSensorSize
+	sdrEventOnlySensorSize         = 12
+	sdrEntityAssociationSize       = 11
+	sdrDeviceEntityAssociationSize = 21
+	sdrFRUDeviceLocatorSize        = 11
+	sdrMCDeviceLocatorSize         = 11
+	sdrOEMSize                     = 3
 )
 
 // Sensor Data Record Type
@@ -98,7 +112,7 @@ type SDRCommonSensor struct {
 	SensorNumber  uint8
 
 	Entity struct {
-		ID       uint8 // (See Table 43-13)
+		ID       EntityID
 		Instance uint8
 		Logical  bool
 	}
@@ -133,7 +147,7 @@ type SDRCommonSensor struct {
 	SensorUnits struct {
 		Percentage   bool
 		Modifier     uint8
-		RateUnit     uint8
+		RateUnit     RateUnit
 		Analog       uint8
 		BaseType     UnitType
 		ModifierType UnitType
@@ -144,6 +158,11 @@ func (r *SDRCommonSensor) Type() SDRType { return r.header.RecordType }
 func (r *SDRCommonSensor) ID() uint16    { return r.header.RecordID }
 func (r *SDRCommonSensor) Data() []byte  { return r.data }
 
+// Returns the description of the entity (e.g. "Processor 1")
+func (r *SDRCommonSensor) EntityDescription() string {
+	return entityDescription(r.Entity.ID, r.Entity.Instance)
+}
+
 func (r *SDRCommonSensor) Unmarshal(buf []byte) ([]byte, error) {
 	if l := len(buf); l < sdrCommonSensorSize {
 		return nil, &MessageError{
@@ -156,16 +175,16 @@ func (r *SDRCommonSensor) Unmarshal(buf []byte) ([]byte, error) {
 	r.OwnerLUN = buf[1] & 0x03
 	r.ChannelNumber = buf[1] & 0xf0 >> 4
 	r.SensorNumber = buf[2]
-	r.Entity.ID = buf[3]
+	r.Entity.ID = EntityID(buf[3])
 	r.Entity.Instance = buf[4] & 0x7f
 	r.Entity.Logical = buf[4]&0x80 != 0
 	r.SensorInitialization.Scanning = buf[5]&0x01 != 0
 	r.SensorInitialization.EventGen = buf[5]&0x02 != 0
-	r.SensorInitialization.InitSensorType = buf[5]&0x03 != 0
-	r.SensorInitialization.InitHysteresis = buf[5]&0x04 != 0
-	r.SensorInitialization.InitThresholds = buf[5]&0x05 != 0
-	r.SensorInitialization.InitEvents = buf[5]&0x06 != 0
-	r.SensorInitialization.InitScanning = buf[5]&0x07 != 0
+	r.SensorInitialization.InitSensorType = buf[5]&0x04 != 0
+	r.SensorInitialization.InitHysteresis = buf[5]&0x08 != 0
+	r.SensorInitialization.InitThresholds = buf[5]&0x10 != 0
+	r.SensorInitialization.InitEvents = buf[5]&0x20 != 0
+	r.SensorInitialization.InitScanning = buf[5]&0x40 != 0
 	r.SensorCapabilities.EventMessage = buf[6] & 0x03
 	r.SensorCapabilities.Threshold = buf[6] & 0x0c >> 2
 	r.SensorCapabilities.Hysteresis = buf[6] & 0x30 >> 4
@@ -178,7 +197,7 @@ func (r *SDRCommonSensor) Unmarshal(buf []byte) ([]byte, error) {
 	r.Mask.DiscreteOrReadableThreshold = uint16(buf[13]) | uint16(buf[14])<<8
 	r.SensorUnits.Percentage = buf[15]&0x01 != 0
 	r.SensorUnits.Modifier = buf[15] & 0x06 >> 1
-	r.SensorUnits.RateUnit = buf[15] & 0x38 >> 3
+	r.SensorUnits.RateUnit = RateUnit(buf[15] & 0x38 >> 3)
 	r.SensorUnits.Analog = buf[15] & 0xc0 >> 6
 	r.SensorUnits.BaseType = UnitType(buf[16])
 	r.SensorUnits.ModifierType = UnitType(buf[17])
@@ -186,22 +205,38 @@ func (r *SDRCommonSensor) Unmarshal(buf []byte) ([]byte, error) {
 	return buf[sdrCommonSensorSize:], nil
 }
 
+// Returns the target to bridge requests to the sensor owner,
+// false if the owner is BMC or a system software.
+func (r *SDRCommonSensor) OwnerTarget() (BridgeTarget, bool) {
+	return sensorOwnerTarget(r.OwnerID, r.ChannelNumber)
+}
+
+// Returns the unit string composed of the percentage, the base and modifier units and the rate unit
+// (e.g. "degrees C", "% RH", "errors/sec", "Watts * hour").
 func (r *SDRCommonSensor) UnitString() string {
+	u := &r.SensorUnits
+
 	var s string
-	switch r.SensorUnits.Modifier {
+	switch u.Modifier {
 	case 0x01:
-		s = fmt.Sprintf("%s/%s", r.SensorUnits.BaseType, r.SensorUnits.ModifierType)
+		s = fmt.Sprintf("%s/%s", u.BaseType, u.ModifierType)
 	case 0x02:
-		s = fmt.Sprintf("%s * %s", r.SensorUnits.BaseType, r.SensorUnits.ModifierType)
+		s = fmt.Sprintf("%s * %s", u.BaseType, u.ModifierType)
 	default:
-		if r.SensorUnits.BaseType == 0 && r.SensorUnits.Percentage {
-			return "percent"
+		if u.BaseType != 0 || !u.Percentage {
+			s = u.BaseType.String()
 		}
-		s = r.SensorUnits.BaseType.String()
 	}
 
-	if r.SensorUnits.Percentage {
-		s = "% " + s
+	if u.Percentage {
+		if s == "" {
+			s = "percent"
+		} else {
+			s = "% " + s
+		}
+	}
+	if u.RateUnit != RateUnitNone {
+		s += "/" + u.RateUnit.Abbreviation()
 	}
 	return s
 }
@@ -372,10 +407,47 @@ func (r *SDRFullSensor) ConvertSensorReading(value uint8) float64 {
 	}
 }
 
+// Thresholds converted to the engineering units, nil if the threshold is not readable
+type SensorThresholds struct {
+	UpperNonRecover *float64
+	UpperCrit       *float64
+	UpperNonCrit    *float64
+	LowerNonRecover *float64
+	LowerCrit       *float64
+	LowerNonCrit    *float64
+}
+
+// Returns the thresholds converted to the engineering units.
+// Only the thresholds in the readable threshold mask are converted.
+func (r *SDRFullSensor) ConvertedThresholds() *SensorThresholds {
+	t := &SensorThresholds{}
+	if !r.IsThresholdBaseSensor() || !r.IsAnalogReading() {
+		return t
+	}
+
+	readable := uint8(r.Mask.DiscreteOrReadableThreshold)
+	convert := func(bit uint8, value uint8) *float64 {
+		if readable&bit == 0 {
+			return nil
+		}
+		v := r.ConvertSensorReading(value)
+		return &v
+	}
+	t.LowerNonCrit = convert(0x01, r.Threshold.LowerNonCrit)
+	t.LowerCrit = convert(0x02, r.Threshold.LowerCrit)
+	t.LowerNonRecover = convert(0x04, r.Threshold.LowerNonRecover)
+	t.UpperNonCrit = convert(0x08, r.Threshold.UpperNonCrit)
+	t.UpperCrit = convert(0x10, r.Threshold.UpperCrit)
+	t.UpperNonRecover = convert(0x20, r.Threshold.UpperNonRecover)
+	return t
+}
+
 // Compact Sensor Record (Section 43.2)
 type SDRCompactSensor struct {
 	SDRCommonSensor
 
+	SensorDirection uint8 // (0: unspecified, 1: input, 2: output)
+
 	Share struct {
 		Count          uint8
 		ModifierType   uint8 // (0: numeric, 1: alpha)
@@ -407,10 +479,11 @@ func (r *SDRCompactSensor) Unmarshal(buf []byte) ([]byte, error) {
 		return nil, err
 	}
 
+	r.SensorDirection = buf[0] & 0xc0 >> 6
 	r.Share.Count = buf[0] & 0x0f
 	r.Share.ModifierType = buf[0] & 0x30 >> 4
 	r.Share.ModifierOffset = buf[1] & 0x7f
-	r.Share.EntityInstance = buf[2] & 0x80 >> 7
+	r.Share.EntityInstance = buf[1] & 0x80 >> 7
 	r.Threshold.PositiveHysteresis = buf[2]
 	r.Threshold.NegativeHysteresis = buf[3]
 	r.OEM = buf[7]
@@ -430,6 +503,341 @@ func (r *SDRCompactSensor) SensorID() string {
 	return decodeSensorID(r.IDType, r.IDString)
 }
 
+// Returns the number of sensors that share the record.
+func (r *SDRCompactSensor) ShareCount() int {
+	if r.Share.Count == 0 {
+		return 1
+	}
+	return int(r.Share.Count)
+}
+
+// Returns the sensor ID of the n-th (0-based) sensor that shares the record.
+// The ID string instance modifier is appended to the ID string (Section 43.2).
+func (r *SDRCompactSensor) SharedSensorID(n int) string {
+	if r.ShareCount() <= 1 {
+		return r.SensorID()
+	}
+	return sharedSensorID(r.SensorID(), r.Share.ModifierType, r.Share.ModifierOffset, n)
+}
+
+// Returns the sensor number of the n-th (0-based) sensor that shares the record.
+func (r *SDRCompactSensor) SharedSensorNumber(n int) uint8 {
+	return r.SensorNumber + uint8(n)
+}
+
+// Returns the entity instance of the n-th (0-based) sensor that shares the record.
+func (r *SDRCompactSensor) SharedEntityInstance(n int) uint8 {
+	if r.Share.EntityInstance == 0x01 {
+		return r.Entity.Instance + uint8(n)
+	}
+	return r.Entity.Instance
+}
+
+// Event-Only Sensor Record (Section 43.3)
+type SDREventOnlySensor struct {
+	header *sdrHeader
+	data   []byte
+
+	OwnerID       uint8
+	OwnerLUN      uint8
+	FRUOwnerLUN   uint8
+	ChannelNumber uint8
+	SensorNumber  uint8
+
+	Entity struct {
+		ID       EntityID
+		Instance uint8
+		Logical  bool
+	}
+
+	SensorType       SensorType
+	EventReadingType uint8 // (See Table 42-1)
+	SensorDirection  uint8 // (0: unspecified, 1: input, 2: output)
+
+	Share struct {
+		Count          uint8
+		ModifierType   uint8 // (0: numeric, 1: alpha)
+		ModifierOffset uint8
+		EntityInstance uint8 // (0: same, 1: increments)
+	}
+
+	OEM      uint8
+	IDType   uint8
+	IDLength uint8
+	IDString []byte
+}
+
+func (r *SDREventOnlySensor) Type() SDRType { return r.header.RecordType }
+func (r *SDREventOnlySensor) ID() uint16    { return r.header.RecordID }
+func (r *SDREventOnlySensor) Data() []byte  { return r.data }
+
+// Returns the description of the entity (e.g. "Processor 1")
+func (r *SDREventOnlySensor) EntityDescription() string {
+	return entityDescription(r.Entity.ID, r.Entity.Instance)
+}
+
+func (r *SDREventOnlySensor) Unmarshal(buf []byte) ([]byte, error) {
+	if l := len(buf); l < sdrEventOnlySensorSize {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid SDREventOnlySensor size : %d/%d", l, sdrEventOnlySensorSize),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	r.data = buf
+	r.OwnerID = buf[0]
+	r.OwnerLUN = buf[1] & 0x03
+	r.FRUOwnerLUN = buf[1] & 0x0c >> 2
+	r.ChannelNumber = buf[1] & 0xf0 >> 4
+	r.SensorNumber = buf[2]
+	r.Entity.ID = EntityID(buf[3])
+	r.Entity.Instance = buf[4] & 0x7f
+	r.Entity.Logical = buf[4]&0x80 != 0
+	r.SensorType = SensorType(buf[5])
+	r.EventReadingType = buf[6]
+	r.SensorDirection = buf[7] & 0xc0 >> 6
+	r.Share.Count = buf[7] & 0x0f
+	r.Share.ModifierType = buf[7] & 0x30 >> 4
+	r.Share.ModifierOffset = buf[8] & 0x7f
+	r.Share.EntityInstance = buf[8] & 0x80 >> 7
+	r.OEM = buf[10]
+	r.IDType = buf[11] & 0xc0 >> 6
+	r.IDLength = buf[11] & 0x1f
+	if l := int(r.IDLength); l > 0 {
+		r.IDString = buf[12:]
+		if l < len(r.IDString) {
+			r.IDString = r.IDString[:l]
+		}
+	}
+
+	return nil, nil
+}
+
+// Returns the target to bridge requests to the sensor owner,
+// false if the owner is BMC or a system software.
+func (r *SDREventOnlySensor) OwnerTarget() (BridgeTarget, bool) {
+	return sensorOwnerTarget(r.OwnerID, r.ChannelNumber)
+}
+
+func (r *SDREventOnlySensor) SensorID() string {
+	return decodeSensorID(r.IDType, r.IDString)
+}
+
+// Returns the number of sensors that share the record.
+func (r *SDREventOnlySensor) ShareCount() int {
+	if r.Share.Count == 0 {
+		return 1
+	}
+	return int(r.Share.Count)
+}
+
+// Returns the sensor ID of the n-th (0-based) sensor that shares the record.
+func (r *SDREventOnlySensor) SharedSensorID(n int) string {
+	if r.ShareCount() <= 1 {
+		return r.SensorID()
+	}
+	return sharedSensorID(r.SensorID(), r.Share.ModifierType, r.Share.ModifierOffset, n)
+}
+
+// Returns the sensor number of the n-th (0-based) sensor that shares the record.
+func (r *SDREventOnlySensor) SharedSensorNumber(n int) uint8 {
+	return r.SensorNumber + uint8(n)
+}
+
+// Returns the entity instance of the n-th (0-based) sensor that shares the record.
+func (r *SDREventOnlySensor) SharedEntityInstance(n int) uint8 {
+	if r.Share.EntityInstance == 0x01 {
+		return r.Entity.Instance + uint8(n)
+	}
+	return r.Entity.Instance
+}
+
+// A sensor described by a sensor record.
+// A compact or event-only sensor record with the share count describes multiple logical sensors.
+type LogicalSensor struct {
+	Record         SDR // Full, compact or event-only sensor record
+	Index          int // Index (0-based) in the sensors that share the record
+	SensorID       string
+	SensorNumber   uint8
+	OwnerID        uint8
+	OwnerLUN       uint8
+	EntityID       EntityID
+	EntityInstance uint8
+}
+
+// Returns the logical sensors of the sensor records, other records are skipped
+func ExpandSensors(records []SDR) []*LogicalSensor {
+	var sensors []*LogicalSensor
+	for _, record := range records {
+		switch r := record.(type) {
+		case *SDRFullSensor:
+			sensors = append(sensors, &LogicalSensor{
+				Record:         r,
+				SensorID:       r.SensorID(),
+				SensorNumber:   r.SensorNumber,
+				OwnerID:        r.OwnerID,
+				OwnerLUN:       r.OwnerLUN,
+				EntityID:       r.Entity.ID,
+				EntityInstance: r.Entity.Instance,
+			})
+		case *SDRCompactSensor:
+			for i := 0; i < r.ShareCount(); i++ {
+				sensors = append(sensors, &LogicalSensor{
+					Record:         r,
+					Index:          i,
+					SensorID:       r.SharedSensorID(i),
+					SensorNumber:   r.SharedSensorNumber(i),
+					OwnerID:        r.OwnerID,
+					OwnerLUN:       r.OwnerLUN,
+					EntityID:       r.Entity.ID,
+					EntityInstance: r.SharedEntityInstance(i),
+				})
+			}
+		case *SDREventOnlySensor:
+			for i := 0; i < r.ShareCount(); i++ {
+				sensors = append(sensors, &LogicalSensor{
+					Record:         r,
+					Index:          i,
+					SensorID:       r.SharedSensorID(i),
+					SensorNumber:   r.SharedSensorNumber(i),
+					OwnerID:        r.OwnerID,
+					OwnerLUN:       r.OwnerLUN,
+					EntityID:       r.Entity.ID,
+					EntityInstance: r.SharedEntityInstance(i),
+				})
+			}
+		}
+	}
+	return sensors
+}
+
+// Entity referenced by the entity association records
+type SDREntityRef struct {
+	ID            EntityID
+	Instance      uint8
+	DeviceAddress uint8 // Slave address of the device (Only for the device-relative record)
+	Channel       uint8 // Channel of the device (Only for the device-relative record)
+}
+
+// Entity Association Record (Section 43.4)
+type SDREntityAssociation struct {
+	header *sdrHeader
+	data   []byte
+
+	Container            SDREntityRef
+	Ranges               bool           // Contained entities are specified as ranges instead of a list
+	Linked               bool           // Contained entities are continued in the linked records
+	PresenceSensorAlways bool           // Contained entities have a presence sensor or are always present
+	Contained            []SDREntityRef // Unused fields are omitted, pairs of the first and last entity for ranges
+}
+
+func (r *SDREntityAssociation) Type() SDRType { return r.header.RecordType }
+func (r *SDREntityAssociation) ID() uint16    { return r.header.RecordID }
+func (r *SDREntityAssociation) Data() []byte  { return r.data }
+
+func (r *SDREntityAssociation) Unmarshal(buf []byte) ([]byte, error) {
+	if l := len(buf); l < sdrEntityAssociationSize {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid SDREntityAssociation size : %d/%d", l, sdrEntityAssociationSize),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	r.data = buf
+	r.Container = SDREntityRef{ID: EntityID(buf[0]), Instance: buf[1]}
+	r.unmarshalFlags(buf[2])
+
+	r.Contained = nil
+	for i := 3; i+1 < sdrEntityAssociationSize; i += 2 {
+		r.Contained = append(r.Contained, SDREntityRef{ID: EntityID(buf[i]), Instance: buf[i+1]})
+	}
+	r.trimContained()
+
+	return nil, nil
+}
+
+// Returns the contained entities, which are expanded if they are specified as ranges.
+func (r *SDREntityAssociation) ContainedEntities() []SDREntityRef {
+	if !r.Ranges {
+		return r.Contained
+	}
+
+	var entities []SDREntityRef
+	for i := 0; i+1 < len(r.Contained); i += 2 {
+		first, last := r.Contained[i], r.Contained[i+1]
+		for n := int(first.Instance); n <= int(last.Instance); n++ {
+			e := first
+			e.Instance = uint8(n)
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+func (r *SDREntityAssociation) unmarshalFlags(b byte) {
+	r.Ranges = b&0x80 != 0
+	r.Linked = b&0x40 != 0
+	r.PresenceSensorAlways = b&0x20 != 0
+}
+
+// Removes unused fields, which have the zero entity ID
+func (r *SDREntityAssociation) trimContained() {
+	if r.Ranges {
+		// Keep the pairs of ranges
+		n := 0
+		for i := 0; i+1 < len(r.Contained); i += 2 {
+			if r.Contained[i].ID != 0 {
+				r.Contained[n], r.Contained[n+1] = r.Contained[i], r.Contained[i+1]
+				n += 2
+			}
+		}
+		r.Contained = r.Contained[:n]
+		return
+	}
+
+	n := 0
+	for _, e := range r.Contained {
+		if e.ID != 0 {
+			r.Contained[n] = e
+			n++
+		}
+	}
+	r.Contained = r.Contained[:n]
+}
+
+// Device-relative Entity Association Record (Section 43.5)
+type SDRDeviceEntityAssociation struct {
+	SDREntityAssociation
+}
+
+func (r *SDRDeviceEntityAssociation) Unmarshal(buf []byte) ([]byte, error) {
+	if l := len(buf); l < sdrDeviceEntityAssociationSize {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid SDRDeviceEntityAssociation size : %d/%d", l, sdrDeviceEntityAssociationSize),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	r.data = buf
+	r.Container = SDREntityRef{
+		ID:            EntityID(buf[0]),
+		Instance:      buf[1],
+		DeviceAddress: buf[2] &^ 0x01,
+		Channel:       buf[3] >> 4,
+	}
+	r.unmarshalFlags(buf[4])
+
+	r.Contained = nil
+	for i := 5; i+3 < sdrDeviceEntityAssociationSize; i += 4 {
+		r.Contained = append(r.Contained, SDREntityRef{
+			ID:            EntityID(buf[i+2]),
+			Instance:      buf[i+3],
+			DeviceAddress: buf[i] &^ 0x01,
+			Channel:       buf[i+1] >> 4,
+		})
+	}
+	r.trimContained()
+
+	return nil, nil
+}
+
 // FRU Device Locator Record (Section 43.8)
 type SDRFRUDeviceLocator struct {
 	header *sdrHeader
@@ -445,7 +853,7 @@ type SDRFRUDeviceLocator struct {
 	DeviceTypeModifier uint8
 
 	Entity struct {
-		ID       uint8
+		ID       EntityID
 		Instance uint8
 	}
 
@@ -459,6 +867,11 @@ func (r *SDRFRUDeviceLocator) Type() SDRType { return r.header.RecordType }
 func (r *SDRFRUDeviceLocator) ID() uint16    { return r.header.RecordID }
 func (r *SDRFRUDeviceLocator) Data() []byte  { return r.data }
 
+// Returns the description of the entity (e.g. "Processor 1")
+func (r *SDRFRUDeviceLocator) EntityDescription() string {
+	return entityDescription(r.Entity.ID, r.Entity.Instance)
+}
+
 func (r *SDRFRUDeviceLocator) Unmarshal(buf []byte) ([]byte, error) {
 	if l := len(buf); l < sdrFRUDeviceLocatorSize {
 		return nil, &MessageError{
@@ -470,12 +883,12 @@ func (r *SDRFRUDeviceLocator) Unmarshal(buf []byte) ([]byte, error) {
 	r.SlaveAddress = buf[0] & 0xfe >> 1
 	r.DeviceID = buf[1]
 	r.BusID = buf[2] & 0x07
-	r.AccessLUN = buf[2] & 18 >> 3
+	r.AccessLUN = buf[2] & 0x18 >> 3
 	r.Logical = buf[2]&0x80 != 0
 	r.ChannelNumber = buf[3] & 0xf0 >> 4
 	r.DeviceType = buf[5]
 	r.DeviceTypeModifier = buf[6]
-	r.Entity.ID = buf[7]
+	r.Entity.ID = EntityID(buf[7])
 	r.Entity.Instance = buf[8]
 	r.OEM = buf[9]
 	r.IDType = buf[10] & 0xc0 >> 6
@@ -494,32 +907,299 @@ func (r *SDRFRUDeviceLocator) SensorID() string {
 	return decodeSensorID(r.IDType, r.IDString)
 }
 
+// Management Controller Device Locator Record (Section 43.9)
+type SDRMCDeviceLocator struct {
+	header *sdrHeader
+	data   []byte
+
+	SlaveAddress  uint8 // 8-bit slave address of the controller
+	ChannelNumber uint8
+
+	ACPISystemPowerNotification bool
+	ACPIDevicePowerNotification bool
+	StaticController            bool  // (false: Dynamic controller)
+	LogInitAgentErrors          bool  // Controller logs initialization agent errors
+	LogInitErrors               bool  // Log initialization agent errors accessing this controller
+	GlobalInitialization        uint8 // (0: Enable event message generation, 1: Disable, 2: Do not initialize)
+
+	Capabilities struct {
+		ChassisDevice      bool
+		Bridge             bool
+		IPMBEventGenerator bool
+		IPMBEventReceiver  bool
+		FRUInventoryDevice bool
+		SELDevice          bool
+		SDRRepoDevice      bool
+		SensorDevice       bool
+	}
+
+	Entity struct {
+		ID       EntityID
+		Instance uint8
+	}
+
+	OEM      uint8
+	IDType   uint8
+	IDLength uint8
+	IDString []byte
+}
+
+func (r *SDRMCDeviceLocator) Type() SDRType { return r.header.RecordType }
+func (r *SDRMCDeviceLocator) ID() uint16    { return r.header.RecordID }
+func (r *SDRMCDeviceLocator) Data() []byte  { return r.data }
+
+// Returns the description of the entity (e.g. "Processor 1")
+func (r *SDRMCDeviceLocator) EntityDescription() string {
+	return entityDescription(r.Entity.ID, r.Entity.Instance)
+}
+
+func (r *SDRMCDeviceLocator) Unmarshal(buf []byte) ([]byte, error) {
+	if l := len(buf); l < sdrMCDeviceLocatorSize {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid SDRMCDeviceLocator size : %d/%d", l, sdrMCDeviceLocatorSize),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	r.data = buf
+	r.SlaveAddress = buf[0] &^ 0x01
+	r.ChannelNumber = buf[1] & 0x0f
+	r.ACPISystemPowerNotification = buf[2]&0x80 != 0
+	r.ACPIDevicePowerNotification = buf[2]&0x40 != 0
+	r.StaticController = buf[2]&0x20 != 0
+	r.LogInitAgentErrors = buf[2]&0x08 != 0
+	r.LogInitErrors = buf[2]&0x04 != 0
+	r.GlobalInitialization = buf[2] & 0x03
+	r.Capabilities.ChassisDevice = buf[3]&0x80 != 0
+	r.Capabilities.Bridge = buf[3]&0x40 != 0
+	r.Capabilities.IPMBEventGenerator = buf[3]&0x20 != 0
+	r.Capabilities.IPMBEventReceiver = buf[3]&0x10 != 0
+	r.Capabilities.FRUInventoryDevice = buf[3]&0x08 != 0
+	r.Capabilities.SELDevice = buf[3]&0x04 != 0
+	r.Capabilities.SDRRepoDevice = buf[3]&0x02 != 0
+	r.Capabilities.SensorDevice = buf[3]&0x01 != 0
+	r.Entity.ID = EntityID(buf[7])
+	r.Entity.Instance = buf[8]
+	r.OEM = buf[9]
+	r.IDType = buf[10] & 0xc0 >> 6
+	r.IDLength = buf[10] & 0x1f
+	if l := int(r.IDLength); l > 0 {
+		r.IDString = buf[11:]
+		if l < len(r.IDString) {
+			r.IDString = r.IDString[:l]
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *SDRMCDeviceLocator) SensorID() string {
+	return decodeSensorID(r.IDType, r.IDString)
+}
+
+// Returns the target to bridge requests to the controller.
+func (r *SDRMCDeviceLocator) BridgeTarget() BridgeTarget {
+	return BridgeTarget{Channel: r.ChannelNumber, Address: r.SlaveAddress}
+}
+
+// OEM Record (Section 43.12)
+type SDROEM struct {
+	header *sdrHeader
+	data   []byte
+
+	ManufacturerID uint32 // IANA enterprise number
+	OEMData        []byte
+}
+
+func (r *SDROEM) Type() SDRType { return r.header.RecordType }
+func (r *SDROEM) ID() uint16    { return r.header.RecordID }
+func (r *SDROEM) Data() []byte  { return r.data }
+
+func (r *SDROEM) Unmarshal(buf []byte) ([]byte, error) {
+	if l := len(buf); l < sdrOEMSize {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid SDROEM size : %d/%d", l, sdrOEMSize),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	r.data = buf
+	r.ManufacturerID = uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16
+	r.OEMData = buf[sdrOEMSize:]
+	return nil, nil
+}
+
+func (r *SDROEM) String() string {
+	return fmt.Sprintf(`{"ManufacturerID":%d,"OEMData":"%s"}`, r.ManufacturerID, hex.EncodeToString(r.OEMData))
+}
+
+// Returns a vendor specific record decoded from the OEM record.
+// The OEM record is returned as it is if the decoder returns nil.
+type OEMSDRDecoder func(r *SDROEM) (SDR, error)
+
+var oemSDRDecoders = struct {
+	sync.RWMutex
+	m map[uint32]OEMSDRDecoder
+}{m: make(map[uint32]OEMSDRDecoder)}
+
+// Register the decoder of the OEM records by the manufacturer ID (IANA enterprise number).
+func RegisterOEMSDRDecoder(iana uint32, d OEMSDRDecoder) {
+	oemSDRDecoders.Lock()
+	defer oemSDRDecoders.Unlock()
+	oemSDRDecoders.m[iana&0xffffff] = d
+}
+
+func decodeOEMSDR(r *SDROEM) (SDR, error) {
+	oemSDRDecoders.RLock()
+	d := oemSDRDecoders.m[r.ManufacturerID]
+	oemSDRDecoders.RUnlock()
+	if d == nil {
+		return r, nil
+	}
+
+	sdr, err := d(r)
+	if err != nil {
+		return nil, err
+	}
+	if sdr == nil {
+		return r, nil
+	}
+	return sdr, nil
+}
+
 // Two's complement to signed int16
 func tos16(n uint16, bits int) int16 {
 	shift := uint(16 - bits)
 	return int16(n<<shift) >> shift
 }
 
+func entityDescription(id EntityID, instance uint8) string {
+	return fmt.Sprintf("%s %d", id, instance)
+}
+
+func sensorOwnerTarget(ownerID, channel uint8) (BridgeTarget, bool) {
+	// The bit 0 is set if the owner ID is a system software ID
+	if ownerID&0x01 != 0 || ownerID == bmcSlaveAddress {
+		return BridgeTarget{}, false
+	}
+	return BridgeTarget{Channel: channel, Address: ownerID}, true
+}
+
+// Appends the ID string instance modifier of the n-th shared sensor
+func sharedSensorID(id string, modType, offset uint8, n int) string {
+	m := int(offset) + n
+	if modType == 0x01 {
+		// Alpha modifier (0: A, 25: Z, 26: AA, ...)
+		var b []byte
+		for m++; m > 0; m = (m - 1) / 26 {
+			b = append([]byte{byte('A' + (m-1)%26)}, b...)
+		}
+		return id + string(b)
+	}
+	return fmt.Sprintf("%s%d", id, m)
+}
+
+// Type/Length Byte Format (Section 43.15)
 func decodeSensorID(t uint8, b []byte) string {
-	// Support only 8-bit ASCII (Section 43.15)
 	switch t {
+	case 0x01:
+		return decodeBCDPlus(b)
+	case 0x02:
+		return decode6bitASCII(b)
 	case 0x03:
 		return string(b)
 	}
+	// Unicode is not supported
 	return "0x" + hex.EncodeToString(b)
 }
 
-func sdrGetRecordHeaderAndNextID(c *Client, reservation, recordID uint16) (*sdrHeader, uint16, error) {
+const bcdPlusChars = "0123456789 -.:,_"
+
+// Decodes BCD plus, the most significant digit first
+func decodeBCDPlus(b []byte) string {
+	s := make([]byte, 0, len(b)*2)
+	for _, c := range b {
+		s = append(s, bcdPlusChars[c>>4], bcdPlusChars[c&0x0f])
+	}
+	return string(s)
+}
+
+// Decodes 6-bit packed ASCII, which packs 4 characters into 3 bytes
+func decode6bitASCII(b []byte) string {
+	s := make([]byte, 0, len(b)*8/6)
+	var acc uint32
+	var bits uint
+	for _, c := range b {
+		acc |= uint32(c) << bits
+		bits += 8
+		for bits >= 6 {
+			s = append(s, byte(acc&0x3f)+0x20)
+			acc >>= 6
+			bits -= 6
+		}
+	}
+	return string(s)
+}
+
+// SDR repository, or device SDRs of the LUN
+type sdrSource struct {
+	device bool
+	lun    uint8
+}
+
+func (r *sdrSource) reserve(c *Client) (uint16, error) {
+	if r.device {
+		cmd := &ReserveDeviceSDRRepositoryCommand{RsLUN: r.lun}
+		err := c.Execute(cmd)
+		return cmd.ReservationID, err
+	}
+	cmd := &ReserveSDRRepositoryCommand{}
+	err := c.Execute(cmd)
+	return cmd.ReservationID, err
+}
+
+func (r *sdrSource) get(c *Client, gsc *GetSDRCommand) error {
+	if r.device {
+		cmd := &GetDeviceSDRCommand{GetSDRCommand: *gsc, RsLUN: r.lun}
+		err := c.Execute(cmd)
+		*gsc = cmd.GetSDRCommand
+		return err
+	}
+	return c.Execute(gsc)
+}
+
+// Executes the commands with overlapping the round trips, and returns the error of each command
+func (r *sdrSource) getPipelined(c *Client, gscs []*GetSDRCommand) []error {
+	cmds := make([]Command, len(gscs))
+	for i, gsc := range gscs {
+		if r.device {
+			cmds[i] = &GetDeviceSDRCommand{GetSDRCommand: *gsc, RsLUN: r.lun}
+		} else {
+			cmds[i] = gsc
+		}
+	}
+
+	errs := c.executePipelined(cmds)
+	if r.device {
+		for i, gsc := range gscs {
+			*gsc = cmds[i].(*GetDeviceSDRCommand).GetSDRCommand
+		}
+	}
+	return errs
+}
+
+func sdrGetRecordHeaderAndNextID(c *Client, repo *sdrSource, reservation, recordID uint16) (*sdrHeader, uint16, error) {
 	gsc := &GetSDRCommand{
 		ReservationID: reservation,
 		RecordID:      recordID,
 		RecordOffset:  0,
 		ReadBytes:     sdrHeaderSize,
 	}
-	if err := c.Execute(gsc); err != nil {
+	if err := repo.get(c, gsc); err != nil {
 		return nil, 0, err
 	}
+	return sdrUnmarshalHeader(gsc, recordID)
+}
 
+func sdrUnmarshalHeader(gsc *GetSDRCommand, recordID uint16) (*sdrHeader, uint16, error) {
 	header := &sdrHeader{}
 	if _, err := header.Unmarshal(gsc.RecordData); err != nil {
 		return nil, 0, err
@@ -531,13 +1211,43 @@ func sdrGetRecordHeaderAndNextID(c *Client, reservation, recordID uint16) (*sdrH
 	return header, gsc.NextRecordID, nil
 }
 
-func sdrGetRecord(c *Client, reservation uint16, header *sdrHeader) (SDR, error) {
+// Returns the bytes to read of each Get SDR (c.mu must be held)
+func (c *Client) sdrSize() *transferSize {
+	if c.sdrReadSize == nil {
+		c.sdrReadSize = newTransferSize(c.args.SDRReadBytes, sdrHeaderSize, sdrReadBytesStep, sdrReadGrowAfter)
+	}
+	return c.sdrReadSize
+}
+
+// Returns the bytes to read of each Get SDR, which are adjusted to the upper limit that BMC can respond
+func (c *Client) sdrReadBytes() uint8 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sdrSize().bytes
+}
+
+// Decreases the bytes to read of each Get SDR, returns false if it can not be decreased
+func (c *Client) shrinkSDRReadBytes() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sdrSize().shrink()
+}
+
+// Tries the larger bytes to read after the successful reads, up to `Arguments.SDRReadBytes`
+func (c *Client) growSDRReadBytes() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sdrSize().grow()
+}
+
+// Returns the record key and body bytes of the record
+func sdrReadRecord(c *Client, repo *sdrSource, reservation uint16, header *sdrHeader) ([]byte, error) {
 	buf := make([]byte, header.RemainingBytes)
 
 	for n := uint8(0); n < header.RemainingBytes; {
 		r := header.RemainingBytes - n
-		if r > c.sdrReadingBytes {
-			r = c.sdrReadingBytes
+		if b := c.sdrReadBytes(); r > b {
+			r = b
 		}
 
 		gsc := &GetSDRCommand{
@@ -546,33 +1256,114 @@ func sdrGetRecord(c *Client, reservation uint16, header *sdrHeader) (SDR, error)
 			RecordOffset:  n + sdrHeaderSize,
 			ReadBytes:     r,
 		}
-		if err := c.Execute(gsc); err != nil {
+		if err := repo.get(c, gsc); err != nil {
 			// Adjust to the upper limit that BMC can be responded
 			if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionRequestDataFieldExceedEd {
-				if c.sdrReadingBytes > sdrHeaderSize {
-					c.sdrReadingBytes -= 8
-					if c.sdrReadingBytes < sdrHeaderSize {
-						c.sdrReadingBytes = sdrHeaderSize
-					}
+				if c.shrinkSDRReadBytes() {
 					continue
 				}
 			}
 			return nil, err
 		}
+		c.growSDRReadBytes()
 		copy(buf[n:], gsc.RecordData)
 		n += uint8(len(gsc.RecordData))
 	}
+	return buf, nil
+}
+
+// Returns the record key and body bytes of the record, and the header of the next record.
+// The requests are pipelined if enabled, otherwise the next header is not read (nil).
+func sdrReadRecordAndNextHeader(c *Client, repo *sdrSource, reservation uint16, header *sdrHeader,
+	nextID uint16) ([]byte, *sdrHeader, uint16, error) {
+
+	size := c.sdrReadBytes()
+	var gscs []*GetSDRCommand
+	for n := uint8(0); n < header.RemainingBytes; {
+		r := header.RemainingBytes - n
+		if r > size {
+			r = size
+		}
+		gscs = append(gscs, &GetSDRCommand{
+			ReservationID: reservation,
+			RecordID:      header.RecordID,
+			RecordOffset:  n + sdrHeaderSize,
+			ReadBytes:     r,
+		})
+		n += r
+	}
+	chunks := len(gscs)
+	if nextID != sdrLastID {
+		gscs = append(gscs, &GetSDRCommand{
+			ReservationID: reservation,
+			RecordID:      nextID,
+			RecordOffset:  0,
+			ReadBytes:     sdrHeaderSize,
+		})
+	}
+
+	if c.args.PipelineWindow <= 1 || len(gscs) < 2 || len(gscs) > int(c.args.PipelineWindow) {
+		data, err := sdrReadRecord(c, repo, reservation, header)
+		return data, nil, 0, err
+	}
+
+	errs := repo.getPipelined(c, gscs)
+	for _, err := range errs {
+		if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionReservationCancelled {
+			return nil, nil, 0, err
+		}
+	}
+
+	buf := make([]byte, 0, header.RemainingBytes)
+	for i, gsc := range gscs[:chunks] {
+		if errs[i] != nil || len(gsc.RecordData) != int(gsc.ReadBytes) {
+			// Read again one by one, which adjusts the bytes to read
+			data, err := sdrReadRecord(c, repo, reservation, header)
+			return data, nil, 0, err
+		}
+		buf = append(buf, gsc.RecordData...)
+		c.growSDRReadBytes()
+	}
+
+	if chunks == len(gscs) || errs[chunks] != nil {
+		return buf, nil, 0, nil
+	}
+	next, nextNextID, err := sdrUnmarshalHeader(gscs[chunks], nextID)
+	if err != nil {
+		return buf, nil, 0, nil
+	}
+	return buf, next, nextNextID, nil
+}
 
+func sdrDecodeRecord(args *Arguments, header *sdrHeader, buf []byte) (SDR, error) {
 	// TODO Add a new record type
 	switch t := header.RecordType; t {
 	case SDRTypeFullSensor:
-		r := &SDRFullSensor{SDRCommonSensor: SDRCommonSensor{args: c.args, header: header}}
+		r := &SDRFullSensor{SDRCommonSensor: SDRCommonSensor{args: args, header: header}}
 		if _, err := r.Unmarshal(buf); err != nil {
 			return nil, err
 		}
 		return r, nil
 	case SDRTypeCompactSensor:
-		r := &SDRCompactSensor{SDRCommonSensor: SDRCommonSensor{args: c.args, header: header}}
+		r := &SDRCompactSensor{SDRCommonSensor: SDRCommonSensor{args: args, header: header}}
+		if _, err := r.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		return r, nil
+	case SDRTypeEventOnlySensor:
+		r := &SDREventOnlySensor{header: header}
+		if _, err := r.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		return r, nil
+	case SDRTypeEntityAssociation:
+		r := &SDREntityAssociation{header: header}
+		if _, err := r.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		return r, nil
+	case SDRTypeDeviceEntityAssociation:
+		r := &SDRDeviceEntityAssociation{SDREntityAssociation{header: header}}
 		if _, err := r.Unmarshal(buf); err != nil {
 			return nil, err
 		}
@@ -583,7 +1374,20 @@ func sdrGetRecord(c *Client, reservation uint16, header *sdrHeader) (SDR, error)
 			return nil, err
 		}
 		return r, nil
+	case SDRTypeMCDeviceLocator:
+		r := &SDRMCDeviceLocator{header: header}
+		if _, err := r.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		return r, nil
 	default:
+		if t >= SDRTypeOEM {
+			r := &SDROEM{header: header}
+			if _, err := r.Unmarshal(buf); err != nil {
+				return nil, err
+			}
+			return decodeOEMSDR(r)
+		}
 		return &sdrRaw{
 			header: header,
 			data:   buf,
@@ -614,22 +1418,79 @@ func SDRGetRecordsRepo(c *Client, filter func(id uint16, t SDRType) bool) ([]SDR
 		}
 	}
 
-	sensors := make([]SDR, 0, gic.RecordCount)
+	return sdrGetRecords(c, &sdrSource{}, int(gic.RecordCount), filter)
+}
+
+// Returns all sensor records from device SDRs.
+func SDRGetAllRecordsDevice(c *Client) ([]SDR, error) {
+	return SDRGetRecordsDevice(c, nil)
+}
+
+// Returns sensor records from device SDRs of all LUNs that have sensors.
+func SDRGetRecordsDevice(c *Client, filter func(id uint16, t SDRType) bool) ([]SDR, error) {
+	gic := &GetDeviceSDRInfoCommand{SDRCount: true}
+	if err := c.Execute(gic); err != nil {
+		return nil, err
+	}
+
+	luns := gic.LUNs
+	if luns == 0 {
+		luns = 0x01
+	}
+
+	var sensors []SDR
+	for lun := uint8(0); lun < 4; lun++ {
+		if luns&(1<<lun) == 0 {
+			continue
+		}
+		records, err := sdrGetRecords(c, &sdrSource{device: true, lun: lun}, int(gic.Count), filter)
+		if err != nil {
+			return nil, err
+		}
+		sensors = append(sensors, records...)
+	}
+	return sensors, nil
+}
+
+func sdrGetRecords(c *Client, repo *sdrSource, count int, filter func(id uint16, t SDRType) bool) ([]SDR, error) {
+	records, err := sdrReadRecords(c, repo, count, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := make([]SDR, 0, len(records))
+	for _, r := range records {
+		sensor, err := sdrDecodeRecord(c.args, r.header, r.data)
+		if err != nil {
+			return nil, err
+		}
+		sensors = append(sensors, sensor)
+	}
+	return sensors, nil
+}
+
+// Header and bytes of the record which is not decoded
+type sdrRecord struct {
+	header *sdrHeader
+	data   []byte
+}
+
+func sdrReadRecords(c *Client, repo *sdrSource, count int, filter func(id uint16, t SDRType) bool) ([]sdrRecord, error) {
+	records := make([]sdrRecord, 0, count)
 
 retry:
-	rsc := &ReserveSDRRepositoryCommand{}
-	if err := c.Execute(rsc); err != nil {
+	records = records[:0]
+	reservation, err := repo.reserve(c)
+	if err != nil {
 		return nil, err
 	}
-	reservation := rsc.ReservationID
 
 	var header *sdrHeader
 	var nextID uint16
-	var err error
 
 	for recordID := sdrFirstID; recordID != sdrLastID; {
 		if header == nil {
-			header, nextID, err = sdrGetRecordHeaderAndNextID(c, reservation, recordID)
+			header, nextID, err = sdrGetRecordHeaderAndNextID(c, repo, reservation, recordID)
 			if err != nil {
 				if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionReservationCancelled {
 					goto retry
@@ -639,7 +1500,7 @@ retry:
 		}
 
 		if filter == nil || filter(header.RecordID, header.RecordType) {
-			record, err := sdrGetRecord(c, reservation, header)
+			data, next, nextNextID, err := sdrReadRecordAndNextHeader(c, repo, reservation, header, nextID)
 			if err != nil {
 				if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionReservationCancelled {
 					goto retry
@@ -647,12 +1508,123 @@ retry:
 				return nil, err
 			}
 
-			sensors = append(sensors, record)
+			records = append(records, sdrRecord{header: header, data: data})
+			if next != nil {
+				header, recordID, nextID = next, nextID, nextNextID
+				continue
+			}
 		}
 
 		header = nil
 		recordID = nextID
 	}
 
-	return sensors, nil
+	return records, nil
+}
+
+// Adds the record (including the header) to SDR repository, and returns the record ID.
+// The record is written in parts by Partial Add SDR if BMC does not accept it at once.
+func SDRAddRecordRepo(c *Client, data []byte) (uint16, error) {
+	if len(data) < sdrHeaderSize {
+		return 0, &ArgumentError{
+			Value:   data,
+			Message: "SDR record is shorter than the header",
+		}
+	}
+
+	asc := &AddSDRCommand{RecordData: data}
+	err := c.Execute(asc)
+	if err == nil {
+		return asc.RecordID, nil
+	}
+	if e, ok := err.(*CommandError); !ok || (e.CompletionCode != CompletionRequestDataInvalidLength &&
+		e.CompletionCode != CompletionRequestDataFieldExceedEd && e.CompletionCode != CompletionCantBeProvided) {
+		return 0, err
+	}
+
+	for i := 0; ; i++ {
+		recordID, err := sdrPartialAddRecord(c, data)
+		if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionReservationCancelled &&
+			i < sdrReserveRetries {
+			// The repository was modified, write the record again with a new reservation
+			continue
+		}
+		return recordID, err
+	}
+}
+
+// Writes the record in parts by Partial Add SDR
+func sdrPartialAddRecord(c *Client, data []byte) (uint16, error) {
+	rsc := &ReserveSDRRepositoryCommand{}
+	if err := c.Execute(rsc); err != nil {
+		return 0, err
+	}
+
+	var recordID uint16
+	for n := 0; n < len(data); n += sdrDefaultWriteBytes {
+		end := n + sdrDefaultWriteBytes
+		if end > len(data) {
+			end = len(data)
+		}
+
+		pac := &PartialAddSDRCommand{
+			ReservationID: rsc.ReservationID,
+			RecordID:      recordID,
+			RecordOffset:  uint8(n),
+			Last:          end == len(data),
+			RecordData:    data[n:end],
+		}
+		if err := c.Execute(pac); err != nil {
+			return 0, err
+		}
+		recordID = pac.ResRecordID
+	}
+	return recordID, nil
+}
+
+// Deletes the record from SDR repository.
+func SDRDeleteRecordRepo(c *Client, recordID uint16) error {
+	for i := 0; ; i++ {
+		rsc := &ReserveSDRRepositoryCommand{}
+		if err := c.Execute(rsc); err != nil {
+			return err
+		}
+
+		err := c.Execute(&DeleteSDRCommand{ReservationID: rsc.ReservationID, RecordID: recordID})
+		if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionReservationCancelled &&
+			i < sdrReserveRetries {
+			continue
+		}
+		return err
+	}
+}
+
+// Erases all records in SDR repository, and waits until the erasure is completed or the context is done.
+func SDRClearRepo(ctx context.Context, c *Client) error {
+	rsc := &ReserveSDRRepositoryCommand{}
+	if err := c.Execute(rsc); err != nil {
+		return err
+	}
+
+	csc := &ClearSDRRepositoryCommand{ReservationID: rsc.ReservationID}
+	if err := c.Execute(csc); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(sdrClearPollInterval)
+	defer ticker.Stop()
+
+	for !csc.Completed {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		csc = &ClearSDRRepositoryCommand{ReservationID: rsc.ReservationID, GetStatus: true}
+		if err := c.Execute(csc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,87 @@
+package ipmigo
+
+import (
+	"io"
+)
+
+// Writes all records of SDR repository in the binary format of `ipmitool sdr dump`,
+// which is the concatenation of the records including the headers.
+func SDRDumpRepo(c *Client, w io.Writer) error {
+	gic := &GetSDRRepositoryInfoCommand{}
+	if err := c.Execute(gic); err != nil {
+		return err
+	}
+
+	records, err := sdrReadRecords(c, &sdrSource{}, int(gic.RecordCount), nil)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if _, err := w.Write(sdrMarshalRecord(r.header, r.data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads and decodes the records from the binary dump.
+func SDRReadDump(r io.Reader) ([]SDR, error) {
+	var sensors []SDR
+	err := sdrReadDump(r, func(header *sdrHeader, data []byte) error {
+		sensor, err := sdrDecodeRecord(nil, header, data)
+		if err != nil {
+			return err
+		}
+		sensors = append(sensors, sensor)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sensors, nil
+}
+
+// Adds all records of the binary dump to SDR repository.
+// The record IDs are assigned by BMC, and the existing records are not cleared (See SDRClearRepo).
+func SDRRestoreRepo(c *Client, r io.Reader) error {
+	return sdrReadDump(r, func(header *sdrHeader, data []byte) error {
+		_, err := SDRAddRecordRepo(c, sdrMarshalRecord(header, data))
+		return err
+	})
+}
+
+func sdrReadDump(r io.Reader, fn func(header *sdrHeader, data []byte) error) error {
+	buf := make([]byte, sdrHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		header := &sdrHeader{}
+		if _, err := header.Unmarshal(buf); err != nil {
+			return err
+		}
+
+		data := make([]byte, header.RemainingBytes)
+		if _, err := io.ReadFull(r, data); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		if err := fn(header, data); err != nil {
+			return err
+		}
+	}
+}
+
+func sdrMarshalRecord(header *sdrHeader, data []byte) []byte {
+	buf := []byte{byte(header.RecordID), byte(header.RecordID >> 8), header.SDRVersion,
+		byte(header.RecordType), byte(len(data))}
+	return append(buf, data...)
+}
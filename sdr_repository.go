@@ -0,0 +1,102 @@
+package ipmigo
+
+// An index of SDR records, which is built from the records of SDR walking
+type SDRRepository struct {
+	records  []SDR
+	byName   map[string][]SDR
+	byNumber map[sdrSensorKey]SDR
+	byEntity map[sdrEntityKey][]SDR
+	byType   map[SDRType][]SDR
+}
+
+type sdrSensorKey struct {
+	owner  uint8
+	number uint8
+}
+
+type sdrEntityKey struct {
+	id       EntityID
+	instance uint8
+}
+
+// Create an SDRRepository from the records.
+// The sensors that share a record are indexed by their own name, number and entity instance.
+func NewSDRRepository(records []SDR) *SDRRepository {
+	r := &SDRRepository{
+		records:  records,
+		byName:   make(map[string][]SDR),
+		byNumber: make(map[sdrSensorKey]SDR),
+		byEntity: make(map[sdrEntityKey][]SDR),
+		byType:   make(map[SDRType][]SDR),
+	}
+
+	for _, s := range ExpandSensors(records) {
+		r.addSensor(s.Record, s.SensorID, s.OwnerID, s.SensorNumber, s.EntityID, s.EntityInstance)
+	}
+	for _, sdr := range records {
+		r.byType[sdr.Type()] = append(r.byType[sdr.Type()], sdr)
+
+		switch s := sdr.(type) {
+		case *SDRFRUDeviceLocator:
+			r.addName(sdr, s.SensorID())
+			r.addEntity(sdr, s.Entity.ID, s.Entity.Instance)
+		case *SDRMCDeviceLocator:
+			r.addName(sdr, s.SensorID())
+			r.addEntity(sdr, s.Entity.ID, s.Entity.Instance)
+		}
+	}
+	return r
+}
+
+func (r *SDRRepository) addSensor(sdr SDR, name string, owner, number uint8, id EntityID, instance uint8) {
+	r.addName(sdr, name)
+	r.addEntity(sdr, id, instance)
+
+	// The first one wins if the sensor number is duplicated
+	key := sdrSensorKey{owner: owner, number: number}
+	if _, ok := r.byNumber[key]; !ok {
+		r.byNumber[key] = sdr
+	}
+}
+
+func (r *SDRRepository) addName(sdr SDR, name string) {
+	if name != "" {
+		r.byName[name] = appendSDR(r.byName[name], sdr)
+	}
+}
+
+func (r *SDRRepository) addEntity(sdr SDR, id EntityID, instance uint8) {
+	key := sdrEntityKey{id: id, instance: instance}
+	r.byEntity[key] = appendSDR(r.byEntity[key], sdr)
+}
+
+// Appends the record unless it has been appended by another shared sensor
+func appendSDR(records []SDR, sdr SDR) []SDR {
+	if n := len(records); n > 0 && records[n-1] == sdr {
+		return records
+	}
+	return append(records, sdr)
+}
+
+// Returns all records in the order of SDR walking
+func (r *SDRRepository) Records() []SDR { return r.records }
+
+// Returns the number of the records
+func (r *SDRRepository) Len() int { return len(r.records) }
+
+// Returns the records which have the sensor ID (or the device ID string)
+func (r *SDRRepository) BySensorName(name string) []SDR { return r.byName[name] }
+
+// Returns the sensor record of the owner (slave address or software ID) and the sensor number
+func (r *SDRRepository) BySensorNumber(owner, number uint8) (SDR, bool) {
+	sdr, ok := r.byNumber[sdrSensorKey{owner: owner, number: number}]
+	return sdr, ok
+}
+
+// Returns the records of the entity
+func (r *SDRRepository) ByEntity(id EntityID, instance uint8) []SDR {
+	return r.byEntity[sdrEntityKey{id: id, instance: instance}]
+}
+
+// Returns the records of the record type
+func (r *SDRRepository) ByType(t SDRType) []SDR { return r.byType[t] }
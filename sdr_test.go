@@ -0,0 +1,73 @@
+package ipmigo
+
+import (
+	"reflect"
+	"testing"
+)
+
+// A session of BMC which can not respond Get SDR of more than `limit` bytes
+type sdrTestSession struct {
+	limit uint8
+	reads []uint8 // Bytes to read of each Get SDR
+}
+
+func (s *sdrTestSession) Ping() (*Pong, error) { return &Pong{}, nil }
+func (s *sdrTestSession) Open() error          { return nil }
+func (s *sdrTestSession) Close() error         { return nil }
+func (s *sdrTestSession) Info() SessionInfo    { return SessionInfo{} }
+
+func (s *sdrTestSession) Execute(cmd Command, opts Options) error {
+	gsc, ok := cmd.(*GetSDRCommand)
+	if !ok {
+		return &CommandError{CompletionCode: CompletionInvalidCommand, Command: cmd}
+	}
+	s.reads = append(s.reads, gsc.ReadBytes)
+	if gsc.ReadBytes > s.limit {
+		return &CommandError{CompletionCode: CompletionRequestDataFieldExceedEd, Command: cmd}
+	}
+	gsc.RecordData = make([]byte, gsc.ReadBytes)
+	return nil
+}
+
+func TestSDRReadSizeShrink(t *testing.T) {
+	c := &Client{args: &Arguments{SDRReadBytes: 30}}
+
+	var sizes []uint8
+	for i := 0; c.shrinkSDRReadBytes(); i++ {
+		if i > 0xff {
+			t.Fatalf("not clamped, sizes = %v", sizes)
+		}
+		sizes = append(sizes, c.sdrReadBytes())
+	}
+	if expected := []uint8{22, 14, 6, sdrHeaderSize}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("sizes = %v, expected %v", sizes, expected)
+	}
+	if b := c.sdrReadBytes(); b != sdrHeaderSize {
+		t.Errorf("final size = %d, expected %d", b, sdrHeaderSize)
+	}
+}
+
+func TestSDRReadSizeRemembered(t *testing.T) {
+	s := &sdrTestSession{limit: 16}
+	args := Arguments{Version: V2_0, Address: "192.0.2.1", SDRReadBytes: 30}
+	args.setDefault()
+	c := &Client{session: s, args: &args}
+	header := &sdrHeader{RecordID: 1, RemainingBytes: 28}
+
+	// The first walk shrinks the size to the limit
+	if _, err := sdrReadRecord(c, &sdrSource{}, 0, header); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []uint8{28, 22, 14, 14}; !reflect.DeepEqual(s.reads, expected) {
+		t.Errorf("first walk reads = %v, expected %v", s.reads, expected)
+	}
+
+	// The second walk starts at the shrunk size
+	s.reads = nil
+	if _, err := sdrReadRecord(c, &sdrSource{}, 0, header); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []uint8{14, 14}; !reflect.DeepEqual(s.reads, expected) {
+		t.Errorf("second walk reads = %v, expected %v", s.reads, expected)
+	}
+}
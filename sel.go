@@ -1,9 +1,12 @@
 package ipmigo
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"sync"
+	"time"
 )
 
 const (
@@ -11,6 +14,9 @@ const (
 	selLastID  uint16 = 0xffff
 
 	selRecordSize = 16
+
+	selClearPollInterval = time.Second
+	selReserveRetries    = 3 // Retries of the reservation while reading SEL
 )
 
 // Sensor Event Log Record Type
@@ -94,15 +100,68 @@ func (r *SELEventRecord) GetEventTriggerThreshold() (uint8, bool) {
 	return 0, false
 }
 
+// Returns the trigger reading and the trigger threshold of threshold-base sensor in engineering units.
+// `ok` is false if the record has neither of them.
+func (r *SELEventRecord) ConvertTriggerValues(s *SDRFullSensor) (reading, threshold *float64, ok bool) {
+	if v, found := r.GetEventTriggerReading(); found {
+		x := s.ConvertSensorReading(v)
+		reading = &x
+	}
+	if v, found := r.GetEventTriggerThreshold(); found {
+		x := s.ConvertSensorReading(v)
+		threshold = &x
+	}
+	return reading, threshold, reading != nil || threshold != nil
+}
+
+// Returns event description with the trigger values of the sensor in engineering units,
+// e.g. "Temp exceeded 85.0 degrees C (reading 87.5)".
+// The description is same as Description if the values are not available.
+func (r *SELEventRecord) DescriptionWithSDR(s *SDRFullSensor) string {
+	if s == nil || !r.EventType.IsThreshold() || !s.IsAnalogReading() {
+		return r.Description()
+	}
+	reading, threshold, ok := r.ConvertTriggerValues(s)
+	if !ok {
+		return r.Description()
+	}
+
+	unit := s.UnitString()
+	format := func(v float64) string {
+		if unit == "" {
+			return fmt.Sprintf("%.1f", v)
+		}
+		return fmt.Sprintf("%.1f %s", v, unit)
+	}
+
+	// Even offsets are "going low" and odd offsets are "going high" (Table 42-2)
+	verb := "exceeded"
+	if r.EventData1&0x01 == 0 {
+		verb = "dropped below"
+	}
+
+	var desc string
+	if threshold != nil {
+		desc = fmt.Sprintf("%s %s %s", s.SensorID(), verb, format(*threshold))
+		if reading != nil {
+			desc += fmt.Sprintf(" (reading %.1f)", *reading)
+		}
+	} else {
+		desc = fmt.Sprintf("%s %s (reading %s)", s.SensorID(), r.Description(), format(*reading))
+	}
+	if !r.IsAssertionEvent() {
+		desc += " deasserted"
+	}
+	return desc
+}
+
 // Returns event description.
 func (r *SELEventRecord) Description() string {
 	var f func() (string, bool)
 	switch t := r.EventType; {
 	case t.IsGeneric() || t.IsThreshold():
 		f = func() (string, bool) {
-			offset := r.EventData1 & 0x0f
-			desc, ok := sensorGenericEventDesc[uint32(r.EventType)<<8|uint32(offset)]
-			return desc, ok
+			return r.EventType.OffsetDescription(r.EventData1 & 0x0f)
 		}
 	case t.IsSensorSpecific():
 		f = func() (string, bool) {
@@ -130,7 +189,7 @@ func (r *SELEventRecord) Description() string {
 	case t.IsOEM():
 		f = func() (string, bool) {
 			return fmt.Sprintf("OEM Event: Type=0x%02x, Data1=0x%02x, Data2=0x%02x, Data3=0x%02x",
-				r.EventType, r.EventData1, r.EventData2, r.EventData3), true
+				uint8(r.EventType), r.EventData1, r.EventData2, r.EventData3), true
 		}
 	default:
 		f = func() (string, bool) { return "", false }
@@ -140,7 +199,7 @@ func (r *SELEventRecord) Description() string {
 		return desc
 	} else {
 		return fmt.Sprintf("Event: Type=0x%02x, Data1=0x%02x, Data2=0x%02x, Data3=0x%02x",
-			r.EventType, r.EventData1, r.EventData2, r.EventData3)
+			uint8(r.EventType), r.EventData1, r.EventData2, r.EventData3)
 	}
 }
 
@@ -176,6 +235,39 @@ func (r *SELTimestampedOEMRecord) Unmarshal(buf []byte) ([]byte, error) {
 	return buf[selRecordSize:], nil
 }
 
+// Returns the description of the record decoded by the OEM SEL describer of the manufacturer
+func (r *SELTimestampedOEMRecord) Description() string {
+	if desc, ok := r.oemDescription(); ok {
+		return desc
+	}
+	return fmt.Sprintf("OEM Record: Manufacturer=0x%06x, Data=%s", r.ManufacturerID, hex.EncodeToString(r.OEMDefined))
+}
+
+func (r *SELTimestampedOEMRecord) oemDescription() (string, bool) {
+	oemSELDescribers.RLock()
+	d := oemSELDescribers.m[r.ManufacturerID]
+	oemSELDescribers.RUnlock()
+	if d == nil {
+		return "", false
+	}
+	return d(r), true
+}
+
+// Returns the description of the OEM defined data of the timestamped OEM record
+type OEMSELDescriber func(r *SELTimestampedOEMRecord) string
+
+var oemSELDescribers = struct {
+	sync.RWMutex
+	m map[uint32]OEMSELDescriber
+}{m: make(map[uint32]OEMSELDescriber)}
+
+// Register the describer of the timestamped OEM records by the manufacturer ID (IANA enterprise number).
+func RegisterOEMSELDescriber(iana uint32, d OEMSELDescriber) {
+	oemSELDescribers.Lock()
+	defer oemSELDescribers.Unlock()
+	oemSELDescribers.m[iana&0xffffff] = d
+}
+
 // Non-Timestamped OEM SEL record (Section 32.3)
 type SELNonTimestampedOEMRecord struct {
 	data []byte
@@ -214,7 +306,14 @@ func selGetRecord(c *Client, reservation, id uint16) (record SELRecord, nextID u
 		ReadBytes:     0xff,
 	}
 	if err = c.Execute(gse); err != nil {
-		return
+		if e, ok := err.(*CommandError); !ok || reservation == 0 ||
+			(e.CompletionCode != CompletionCantReturnDataBytes && e.CompletionCode != CompletionRequestDataFieldExceedEd) {
+			return
+		}
+		// Some BMCs can not return the entire record at once
+		if gse, err = selGetRecordPartial(c, reservation, id); err != nil {
+			return
+		}
 	}
 	if l := len(gse.RecordData); l < 3 {
 		err = &MessageError{Message: fmt.Sprintf("Invalid SELRecord size : %d", l)}
@@ -244,18 +343,48 @@ func selGetRecord(c *Client, reservation, id uint16) (record SELRecord, nextID u
 	return record, gse.NextRecordID, nil
 }
 
-func SELGetEntries(c *Client, offset, num int) (records []SELRecord, total int, err error) {
+func selGetInfo(c *Client) (*GetSELInfoCommand, error) {
 	gsi := &GetSELInfoCommand{}
-	if err = c.Execute(gsi); err != nil {
-		return
+	if err := c.Execute(gsi); err != nil {
+		return nil, err
 	}
 
 	if v := gsi.SELVersion; v != 0x51 && v != 0x02 {
-		return nil, 0, &MessageError{
+		return nil, &MessageError{
 			Message: fmt.Sprintf("Unknown SEL version : %d", v),
 		}
 	}
-	total = int(gsi.Entries)
+	return gsi, nil
+}
+
+// Reads the record in halves, which requires the reservation
+func selGetRecordPartial(c *Client, reservation, id uint16) (*GetSELEntryCommand, error) {
+	const half = selRecordSize / 2
+
+	var data []byte
+	var gse *GetSELEntryCommand
+	for offset := uint8(0); offset < selRecordSize; offset += half {
+		gse = &GetSELEntryCommand{
+			ReservationID: reservation,
+			RecordID:      id,
+			RecordOffset:  offset,
+			ReadBytes:     half,
+		}
+		if err := c.Execute(gse); err != nil {
+			return nil, err
+		}
+		data = append(data, gse.RecordData...)
+	}
+	gse.RecordData = data
+	return gse, nil
+}
+
+func SELGetEntries(c *Client, offset, num int) (records []SELRecord, total int, err error) {
+	it, err := NewSELIterator(c)
+	if err != nil {
+		return
+	}
+	total = it.Total()
 
 	if total == 0 || num <= 0 || offset < 0 || offset >= total {
 		return
@@ -264,35 +393,338 @@ func SELGetEntries(c *Client, offset, num int) (records []SELRecord, total int,
 		num = n
 	}
 
-	roffset := selFirstID
-	if offset > 0 {
-		// get first record
-		r, n, e := selGetRecord(c, 0x00, selFirstID)
-		if e != nil {
-			return
+	// The records before the offset are walked through, because the record IDs are not always contiguous
+	records = make([]SELRecord, 0, num)
+	for len(records) < num && it.Next() {
+		if it.Count() > offset {
+			records = append(records, it.Record())
 		}
+	}
+	// The records read before the error are also returned
+	err = it.Err()
+	return
+}
+
+// A SELIterator walks SEL records lazily by the next record ID.
+// The walk can be terminated at any time by not calling Next.
+type SELIterator struct {
+	client      *Client
+	reserve     bool
+	reservation uint16
+	nextID      uint16
+	record      SELRecord
+	err         error
+	count       int
+	total       int
+}
 
-		delta := n - r.ID()
-		roffset = delta*uint16(offset) + r.ID()
+// Create a SELIterator which starts from the first record
+func NewSELIterator(c *Client) (*SELIterator, error) {
+	gsi, err := selGetInfo(c)
+	if err != nil {
+		return nil, err
 	}
 
-	records = make([]SELRecord, 0, num)
+	it := &SELIterator{
+		client:  c,
+		reserve: gsi.SupportReserve,
+		nextID:  selFirstID,
+		total:   int(gsi.Entries),
+	}
+	if gsi.Entries == 0 {
+		it.nextID = selLastID
+	}
+	if it.reserve {
+		if err := it.reserveSEL(); err != nil {
+			return nil, err
+		}
+	}
+	return it, nil
+}
 
+func (it *SELIterator) reserveSEL() error {
 	rsc := &ReserveSELCommand{}
-	if err = c.Execute(rsc); err != nil {
-		return
+	if err := it.client.Execute(rsc); err != nil {
+		return err
 	}
+	it.reservation = rsc.ReservationID
+	return nil
+}
 
-	for n, id := 0, roffset; n < num && id != selLastID; n++ {
-		var r SELRecord
-		if r, id, err = selGetRecord(c, rsc.ReservationID, id); err != nil {
-			return
+// Reads the next record, and returns false when the walk is finished or an error occurred
+func (it *SELIterator) Next() bool {
+	if it.err != nil || it.nextID == selLastID {
+		it.record = nil
+		return false
+	}
+
+	record, nextID, err := selGetRecord(it.client, it.reservation, it.nextID)
+	for i := 0; i < selReserveRetries && it.reserve; i++ {
+		if e, ok := err.(*CommandError); !ok || e.CompletionCode != CompletionReservationCancelled {
+			break
+		}
+		// SEL was modified, continue from the current record with a new reservation
+		if err = it.reserveSEL(); err == nil {
+			record, nextID, err = selGetRecord(it.client, it.reservation, it.nextID)
+		}
+	}
+	if err != nil {
+		it.err = err
+		it.record = nil
+		return false
+	}
+
+	it.record = record
+	it.nextID = nextID
+	it.count++
+	return true
+}
+
+// Returns the record read by the last Next
+func (it *SELIterator) Record() SELRecord { return it.record }
+
+// Returns the error which terminated the walk
+func (it *SELIterator) Err() error { return it.err }
+
+// Returns the number of the records read so far
+func (it *SELIterator) Count() int { return it.count }
+
+// Returns the number of the entries when the walk was started
+func (it *SELIterator) Total() int { return it.total }
+
+// Conditions to select SEL records. The zero value selects all records.
+type SELFilter struct {
+	Since time.Time // Selects the records at or after the time (Zero means unbounded)
+	Until time.Time // Selects the records before the time (Zero means unbounded)
+
+	// Conditions of the event records, OEM records are not selected if any of them is specified
+	SensorTypes   []SensorType
+	SensorNumbers []uint8
+	AssertionOnly bool
+
+	// Additional condition (Optional)
+	Match func(r SELRecord) bool
+}
+
+// Returns true if the record satisfies all conditions.
+// A record without the absolute timestamp is not selected when the time range is specified.
+func (f *SELFilter) Matches(r SELRecord) bool {
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		var ts *Timestamp
+		switch e := r.(type) {
+		case *SELEventRecord:
+			ts = &e.Timestamp
+		case *SELTimestampedOEMRecord:
+			ts = &e.Timestamp
+		}
+		if ts == nil || ts.IsUnspecified() || ts.IsPostInit() {
+			return false
+		}
+		t := time.Unix(int64(ts.Value), 0)
+		if (!f.Since.IsZero() && t.Before(f.Since)) || (!f.Until.IsZero() && !t.Before(f.Until)) {
+			return false
+		}
+	}
+
+	if len(f.SensorTypes) > 0 || len(f.SensorNumbers) > 0 || f.AssertionOnly {
+		e, ok := r.(*SELEventRecord)
+		if !ok {
+			return false
+		}
+		if f.AssertionOnly && !e.IsAssertionEvent() {
+			return false
+		}
+		if len(f.SensorTypes) > 0 && !selContainsSensorType(f.SensorTypes, e.SensorType) {
+			return false
+		}
+		if len(f.SensorNumbers) > 0 && !selContainsSensorNumber(f.SensorNumbers, e.SensorNumber) {
+			return false
+		}
+	}
+
+	return f.Match == nil || f.Match(r)
+}
+
+func selContainsSensorType(types []SensorType, t SensorType) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+func selContainsSensorNumber(numbers []uint8, n uint8) bool {
+	for _, x := range numbers {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Walks SEL and returns the records selected by the filter, up to `limit` records (0 means no limit).
+// The walk stops as soon as the limit is reached.
+func SELFindEntries(c *Client, filter *SELFilter, limit int) ([]SELRecord, error) {
+	it, err := NewSELIterator(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []SELRecord
+	for (limit <= 0 || len(records) < limit) && it.Next() {
+		if r := it.Record(); filter == nil || filter.Matches(r) {
+			records = append(records, r)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Returns the last `num` records of SEL in newest-first order.
+// The records are probed backwards from the last record, and SEL is walked from the first record
+// only if the record IDs are not ascending.
+func SELGetLatestEntries(c *Client, num int) ([]SELRecord, error) {
+	if num <= 0 {
+		return nil, nil
+	}
+
+	gsi, err := selGetInfo(c)
+	if err != nil || gsi.Entries == 0 {
+		return nil, err
+	}
+	if total := int(gsi.Entries); num > total {
+		num = total
+	}
+
+	records := make([]SELRecord, 0, num)
+	last, _, err := selGetRecord(c, 0x00, selLastID)
+	if err != nil {
+		return nil, err
+	}
+	records = append(records, last)
+
+	// Deleted records leave gaps in the IDs, the probe is given up after too many misses
+	misses := 0
+	for id := last.ID(); len(records) < num && id > selFirstID && misses <= num*2; {
+		id--
+		r, next, err := selGetRecord(c, 0x00, id)
+		if err != nil {
+			if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionRequestDataNotPresent {
+				misses++
+				continue
+			}
+			return nil, err
+		}
+		if next != records[len(records)-1].ID() {
+			// The record is not the previous one
+			return selGetLatestEntriesByWalk(c, num)
 		}
 		records = append(records, r)
 	}
+	if len(records) < num {
+		return selGetLatestEntriesByWalk(c, num)
+	}
+	return records, nil
+}
 
-	if l := len(records); l > num {
-		records = records[l-num:]
+func selGetLatestEntriesByWalk(c *Client, num int) ([]SELRecord, error) {
+	it, err := NewSELIterator(c)
+	if err != nil {
+		return nil, err
 	}
-	return
+
+	// Keep the last records in the ring buffer
+	ring := make([]SELRecord, num)
+	n := 0
+	for it.Next() {
+		ring[n%num] = it.Record()
+		n++
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	if n > num {
+		n = num
+	}
+	records := make([]SELRecord, 0, n)
+	for i := 0; i < n; i++ {
+		records = append(records, ring[(it.Count()-1-i)%num])
+	}
+	return records, nil
+}
+
+// Usage of SEL
+type SELUsage struct {
+	Entries     int
+	FreeEntries int     // Number of entries that can be added
+	PercentUsed float64 // Percentage of the used space
+	Overflow    bool    // Events have been dropped due to lack of space
+	LastAddTime Timestamp
+	LastDelTime Timestamp
+}
+
+// Returns the usage of SEL.
+// The free space is taken from Get SEL Allocation Info if supported, otherwise from Get SEL Info.
+func SELGetUsage(c *Client) (*SELUsage, error) {
+	gsi, err := selGetInfo(c)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &SELUsage{
+		Entries:     int(gsi.Entries),
+		FreeEntries: int(gsi.FreeSpace) / selRecordSize,
+		Overflow:    gsi.Overflow,
+		LastAddTime: Timestamp{Value: gsi.LastAddTime},
+		LastDelTime: Timestamp{Value: gsi.LastDelTime},
+	}
+
+	if gsi.SupportAllocInfo {
+		gai := &GetSELAllocationInfoCommand{}
+		if err := c.Execute(gai); err != nil {
+			return nil, err
+		}
+		if gai.UnitSize > 0 {
+			u.FreeEntries = int(gai.FreeUnits) * int(gai.UnitSize) / selRecordSize
+		}
+	}
+
+	if total := u.Entries + u.FreeEntries; total > 0 {
+		u.PercentUsed = float64(u.Entries) * 100 / float64(total)
+	}
+	return u, nil
+}
+
+// Clears all entries of SEL, and waits until the erasure is completed or the context is done.
+func SELClear(ctx context.Context, c *Client) error {
+	rsc := &ReserveSELCommand{}
+	if err := c.Execute(rsc); err != nil {
+		return err
+	}
+
+	csc := &ClearSELCommand{ReservationID: rsc.ReservationID}
+	if err := c.Execute(csc); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(selClearPollInterval)
+	defer ticker.Stop()
+
+	for !csc.Completed {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		csc = &ClearSELCommand{ReservationID: rsc.ReservationID, GetStatus: true}
+		if err := c.Execute(csc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
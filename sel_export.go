@@ -0,0 +1,140 @@
+package ipmigo
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// A SEL record rendered for the export
+type selEntry struct {
+	ID          uint16 `json:"id"`
+	RecordType  string `json:"record_type"`
+	Date        string `json:"date"`
+	Time        string `json:"time"`
+	Timestamp   string `json:"timestamp,omitempty"` // RFC3339 (Only for the absolute timestamp)
+	Sensor      string `json:"sensor"`
+	Description string `json:"description"`
+	Direction   string `json:"direction"`
+	Data        string `json:"data"`
+}
+
+var selCSVHeader = []string{"id", "record_type", "date", "time", "timestamp", "sensor", "description", "direction", "data"}
+
+func (e *selEntry) csv() []string {
+	return []string{strconv.Itoa(int(e.ID)), e.RecordType, e.Date, e.Time, e.Timestamp,
+		e.Sensor, e.Description, e.Direction, e.Data}
+}
+
+func newSELEntry(r SELRecord, repo *SDRRepository) *selEntry {
+	e := &selEntry{ID: r.ID(), Data: hex.EncodeToString(r.Data())}
+
+	setTime := func(ts *Timestamp) {
+		switch {
+		case ts.IsUnspecified(), ts.IsPostInit():
+			e.Date, e.Time = ts.Format(""), ts.Format("")
+		default:
+			t := time.Unix(int64(ts.Value), 0)
+			e.Date, e.Time = t.Format("01/02/2006"), t.Format("15:04:05")
+			e.Timestamp = t.Format(time.RFC3339)
+		}
+	}
+
+	switch s := r.(type) {
+	case *SELEventRecord:
+		e.RecordType = "System Event"
+		setTime(&s.Timestamp)
+		e.Sensor = fmt.Sprintf("%s #0x%02x", s.SensorType, s.SensorNumber)
+		if repo != nil {
+			if sdr, ok := repo.BySensorNumber(uint8(s.GeneratorID), s.SensorNumber); ok {
+				if name := selSensorName(sdr, s.SensorNumber); name != "" {
+					e.Sensor = fmt.Sprintf("%s %s", s.SensorType, name)
+				}
+			}
+		}
+		e.Description = s.Description()
+		if s.IsAssertionEvent() {
+			e.Direction = "Asserted"
+		} else {
+			e.Direction = "Deasserted"
+		}
+	case *SELTimestampedOEMRecord:
+		e.RecordType = "OEM Timestamped"
+		setTime(&s.Timestamp)
+		e.Sensor = fmt.Sprintf("OEM record %02x", uint8(s.RecordType))
+		if desc, ok := s.oemDescription(); ok {
+			e.Description = desc
+		} else {
+			e.Description = fmt.Sprintf("%06x", s.ManufacturerID)
+			e.Direction = hex.EncodeToString(s.OEMDefined)
+		}
+	case *SELNonTimestampedOEMRecord:
+		e.RecordType = "OEM Non-Timestamped"
+		e.Sensor = fmt.Sprintf("OEM record %02x", uint8(s.RecordType))
+		e.Description = hex.EncodeToString(s.OEM)
+	default:
+		e.RecordType = fmt.Sprintf("Unknown(0x%02x)", uint8(r.Type()))
+	}
+	return e
+}
+
+// Returns the name of the sensor, which may be one of the sensors that share the record
+func selSensorName(sdr SDR, number uint8) string {
+	switch s := sdr.(type) {
+	case *SDRFullSensor:
+		return s.SensorID()
+	case *SDRCompactSensor:
+		return s.SharedSensorID(int(number - s.SensorNumber))
+	case *SDREventOnlySensor:
+		return s.SharedSensorID(int(number - s.SensorNumber))
+	}
+	return ""
+}
+
+// Writes the records in the text format of `ipmitool sel elist`.
+// The sensor names are resolved with the repository if it is not nil.
+func SELWriteText(w io.Writer, records []SELRecord, repo *SDRRepository) error {
+	for _, r := range records {
+		e := newSELEntry(r, repo)
+		line := fmt.Sprintf("%4x | %s | %s | %s | %s", e.ID, e.Date, e.Time, e.Sensor, e.Description)
+		if e.Direction != "" {
+			line += " | " + e.Direction
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the records in CSV with the header line.
+// The sensor names are resolved with the repository if it is not nil.
+func SELWriteCSV(w io.Writer, records []SELRecord, repo *SDRRepository) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(selCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write(newSELEntry(r, repo).csv()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes the records in JSON Lines, which is one JSON object per line.
+// The sensor names are resolved with the repository if it is not nil.
+func SELWriteJSONLines(w io.Writer, records []SELRecord, repo *SDRRepository) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(newSELEntry(r, repo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package ipmigo
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+const selWatcherQueueSize = 64
+
+// A SELWatcher polls SEL and delivers the records added since the last poll
+type SELWatcher struct {
+	client  *Client
+	records chan SELRecord
+	errs    chan error
+	poller  *poller
+
+	baseline  bool   // The last seen record has been determined
+	lastID    uint16 // ID of the last seen record (selLastID: SEL was empty)
+	lastAdd   uint32 // Last Add Time of the last poll
+	reconnect bool   // The session is reopened before the next poll
+}
+
+// Returns the channel of the new records, which is closed when the watcher is stopped
+func (w *SELWatcher) Records() <-chan SELRecord { return w.records }
+
+// Returns the channel of the polling errors, which is closed when the watcher is stopped.
+// The errors are dropped while the channel is full.
+func (w *SELWatcher) Errors() <-chan error { return w.errs }
+
+// Returns the last error of polling
+func (w *SELWatcher) Err() error { return w.poller.Err() }
+
+// Stops polling. It is safe to call more than once.
+func (w *SELWatcher) Stop() { w.poller.Stop() }
+
+func (w *SELWatcher) run(stop <-chan struct{}) error {
+	if w.reconnect {
+		// The state of the session is reset even if it fails to close
+		w.client.Close()
+		if err := w.client.Open(); err != nil {
+			w.report(err)
+			return err
+		}
+		w.reconnect = false
+	}
+
+	err := w.poll(stop)
+	if err != nil {
+		w.reconnect = selWatcherReconnectable(err)
+		w.report(err)
+	}
+	return err
+}
+
+func (w *SELWatcher) report(err error) {
+	select {
+	case w.errs <- err:
+	default:
+	}
+}
+
+// Returns true if the session should be reopened after the error
+func selWatcherReconnectable(err error) bool {
+	var ne net.Error
+	return errors.Is(err, ErrSessionExpired) || errors.As(err, &ne)
+}
+
+func (w *SELWatcher) poll(stop <-chan struct{}) error {
+	gsi, err := selGetInfo(w.client)
+	if err != nil {
+		return err
+	}
+
+	// The records existing at the first poll are not delivered
+	if !w.baseline {
+		if w.lastID, err = w.lastRecordID(gsi); err != nil {
+			return err
+		}
+		w.lastAdd = gsi.LastAddTime
+		w.baseline = true
+		return nil
+	}
+	if gsi.LastAddTime == w.lastAdd {
+		return nil
+	}
+
+	id, err := w.nextRecordID()
+	if err != nil {
+		return err
+	}
+	for id != selLastID {
+		var r SELRecord
+		if r, id, err = selGetRecord(w.client, 0x00, id); err != nil {
+			return err
+		}
+
+		select {
+		case w.records <- r:
+		case <-stop:
+			return nil
+		}
+		w.lastID = r.ID()
+	}
+	w.lastAdd = gsi.LastAddTime
+	return nil
+}
+
+func (w *SELWatcher) lastRecordID(gsi *GetSELInfoCommand) (uint16, error) {
+	if gsi.Entries == 0 {
+		return selLastID, nil
+	}
+	r, _, err := selGetRecord(w.client, 0x00, selLastID)
+	if err != nil {
+		return 0, err
+	}
+	return r.ID(), nil
+}
+
+// Returns the ID of the record following the last seen record
+func (w *SELWatcher) nextRecordID() (uint16, error) {
+	if w.lastID == selLastID {
+		return selFirstID, nil
+	}
+
+	_, next, err := selGetRecord(w.client, 0x00, w.lastID)
+	if e, ok := err.(*CommandError); ok && e.CompletionCode == CompletionRequestDataNotPresent {
+		// The last seen record has been deleted (e.g. SEL was cleared)
+		return selFirstID, nil
+	}
+	return next, err
+}
+
+// Polls SEL from a goroutine at the interval, and delivers the records added after the first poll.
+// The polling errors are reported on the Errors channel, and the session of the client is reopened
+// before the next poll if it has been expired or the network has failed.
+func (c *Client) WatchSEL(interval time.Duration) (*SELWatcher, error) {
+	if interval <= 0 {
+		return nil, &ArgumentError{
+			Value:   interval,
+			Message: "Polling interval must be positive",
+		}
+	}
+
+	w := &SELWatcher{
+		client:  c,
+		records: make(chan SELRecord, selWatcherQueueSize),
+		errs:    make(chan error, selWatcherQueueSize),
+	}
+	w.poller = newPoller(interval, true, w.run, func() {
+		close(w.records)
+		close(w.errs)
+	})
+	return w, nil
+}
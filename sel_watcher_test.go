@@ -0,0 +1,144 @@
+package ipmigo
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A session of BMC which has a SEL, and expires the session on demand
+type selTestSession struct {
+	mu      sync.Mutex
+	records [][]byte
+	addTime uint32
+	open    bool
+	expired bool
+	opens   int
+}
+
+func (s *selTestSession) Ping() (*Pong, error) { return &Pong{}, nil }
+func (s *selTestSession) Info() SessionInfo    { return SessionInfo{} }
+
+func (s *selTestSession) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.open {
+		s.open, s.expired = true, false
+		s.opens++
+	}
+	return nil
+}
+
+func (s *selTestSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expired := s.expired
+	s.open, s.expired = false, false
+	if expired {
+		return ErrSessionExpired
+	}
+	return nil
+}
+
+func (s *selTestSession) Execute(cmd Command, opts Options) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.open {
+		s.open = true
+		s.opens++
+	}
+	if s.expired {
+		return ErrSessionExpired
+	}
+
+	switch c := cmd.(type) {
+	case *GetSELInfoCommand:
+		c.SELVersion = 0x51
+		c.Entries = uint16(len(s.records))
+		c.LastAddTime = s.addTime
+	case *GetSELEntryCommand:
+		i := int(c.RecordID) - 1
+		switch c.RecordID {
+		case selFirstID:
+			i = 0
+		case selLastID:
+			i = len(s.records) - 1
+		}
+		if i < 0 || i >= len(s.records) {
+			return &CommandError{CompletionCode: CompletionRequestDataNotPresent, Command: cmd}
+		}
+		c.RecordData = s.records[i]
+		c.NextRecordID = selLastID
+		if i+1 < len(s.records) {
+			c.NextRecordID = uint16(i + 2)
+		}
+	default:
+		return &CommandError{CompletionCode: CompletionInvalidCommand, Command: cmd}
+	}
+	return nil
+}
+
+func (s *selTestSession) openCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opens
+}
+
+// Adds a system event record and expires the session
+func (s *selTestSession) addAndExpire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := len(s.records) + 1
+	r := make([]byte, selRecordSize)
+	r[0], r[1], r[2] = byte(id), byte(id>>8), 0x02
+	s.records = append(s.records, r)
+	s.addTime++
+	s.expired = true
+}
+
+func TestSELWatcherReconnect(t *testing.T) {
+	s := &selTestSession{}
+	args := Arguments{Version: V2_0, Address: "192.0.2.1"}
+	args.setDefault()
+	c := &Client{session: s, args: &args}
+
+	w, err := c.WatchSEL(10 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// Wait for the baseline
+	deadline := time.Now().Add(time.Second)
+	for s.openCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not poll")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	s.addAndExpire()
+
+	select {
+	case err := <-w.Errors():
+		if !errors.Is(err, ErrSessionExpired) {
+			t.Fatalf("err = %v, expected ErrSessionExpired", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expiration is not reported")
+	}
+
+	select {
+	case r := <-w.Records():
+		if r.ID() != 1 {
+			t.Errorf("record ID = %d, expected 1", r.ID())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("record is not delivered after the reconnection")
+	}
+
+	if n := s.openCount(); n < 2 {
+		t.Errorf("session is opened %d times, expected reopened", n)
+	}
+}
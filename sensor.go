@@ -42,6 +42,117 @@ func NewThresholdStatus(status uint8) ThresholdStatus {
 	}
 }
 
+// Returns the severity of the threshold status
+func (s ThresholdStatus) Severity() ThresholdSeverity {
+	switch s {
+	case ThresholdStatusLNC, ThresholdStatusUNC:
+		return ThresholdSeverityNonCritical
+	case ThresholdStatusLCR, ThresholdStatusUCR:
+		return ThresholdSeverityCritical
+	case ThresholdStatusLNR, ThresholdStatusUNR:
+		return ThresholdSeverityNonRecoverable
+	default:
+		return ThresholdSeverityOK
+	}
+}
+
+// Returns `true` if the status is critical or non-recoverable.
+func (s ThresholdStatus) IsCritical() bool {
+	return s.Severity() >= ThresholdSeverityCritical
+}
+
+// Returns the description of the threshold status (e.g. "Upper Critical")
+func (s ThresholdStatus) Description() string {
+	switch s {
+	case ThresholdStatusOK:
+		return "OK"
+	case ThresholdStatusLNR:
+		return "Lower Non-Recoverable"
+	case ThresholdStatusLCR:
+		return "Lower Critical"
+	case ThresholdStatusLNC:
+		return "Lower Non-Critical"
+	case ThresholdStatusUNR:
+		return "Upper Non-Recoverable"
+	case ThresholdStatusUCR:
+		return "Upper Critical"
+	case ThresholdStatusUNC:
+		return "Upper Non-Critical"
+	default:
+		return fmt.Sprintf("Unknown(%s)", string(s))
+	}
+}
+
+// Severity of the threshold status, which is ordered from OK to Non-Recoverable
+type ThresholdSeverity int
+
+const (
+	ThresholdSeverityOK ThresholdSeverity = iota
+	ThresholdSeverityNonCritical
+	ThresholdSeverityCritical
+	ThresholdSeverityNonRecoverable
+)
+
+func (s ThresholdSeverity) String() string {
+	switch s {
+	case ThresholdSeverityOK:
+		return "OK"
+	case ThresholdSeverityNonCritical:
+		return "Non-Critical"
+	case ThresholdSeverityCritical:
+		return "Critical"
+	case ThresholdSeverityNonRecoverable:
+		return "Non-Recoverable"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(s))
+	}
+}
+
+// Threshold comparison status of threshold-based sensor (Table 35-15)
+type ThresholdComparison struct {
+	LowerNonCritical    bool // At or below lower non-critical threshold
+	LowerCritical       bool // At or below lower critical threshold
+	LowerNonRecoverable bool // At or below lower non-recoverable threshold
+	UpperNonCritical    bool // At or above upper non-critical threshold
+	UpperCritical       bool // At or above upper critical threshold
+	UpperNonRecoverable bool // At or above upper non-recoverable threshold
+}
+
+func NewThresholdComparison(status uint8) ThresholdComparison {
+	return ThresholdComparison{
+		LowerNonCritical:    status&0x01 != 0,
+		LowerCritical:       status&0x02 != 0,
+		LowerNonRecoverable: status&0x04 != 0,
+		UpperNonCritical:    status&0x08 != 0,
+		UpperCritical:       status&0x10 != 0,
+		UpperNonRecoverable: status&0x20 != 0,
+	}
+}
+
+// Asserted states of discrete sensor, the bit n is set if the state of the offset n (0 - 14) is asserted
+type DiscreteStates uint16
+
+// Returns asserted states of discrete sensor from the sensor data 2 and 3 (Table 35-15)
+func NewDiscreteStates(data2, data3 uint8) DiscreteStates {
+	return DiscreteStates(data2) | DiscreteStates(data3&0x7f)<<8
+}
+
+// Returns `true` if the state of the offset is asserted.
+func (s DiscreteStates) IsAsserted(offset uint8) bool {
+	return offset < 15 && s&(1<<offset) != 0
+}
+
+// Returns the offsets of the asserted states.
+func (s DiscreteStates) Offsets() []uint8 {
+	var offsets []uint8
+	for i := uint8(0); i < 15; i++ {
+		if s.IsAsserted(i) {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
 // Sensor Type (Table 42-3)
 type SensorType uint8
 
@@ -103,6 +214,94 @@ func (t SensorType) String() string {
 	}
 }
 
+// Entity ID (Table 43-13)
+type EntityID uint8
+
+var entityIDDescriptions []string = []string{
+	"Unspecified",
+	"Other",
+	"Unknown",
+	"Processor",
+	"Disk or Disk Bay",
+	"Peripheral Bay",
+	"System Management Module",
+	"System Board",
+	"Memory Module",
+	"Processor Module",
+	"Power Supply",
+	"Add-in Card",
+	"Front Panel Board",
+	"Back Panel Board",
+	"Power System Board",
+	"Drive Backplane",
+	"System Internal Expansion Board",
+	"Other System Board",
+	"Processor Board",
+	"Power Unit / Power Domain",
+	"Power Module / DC-to-DC Converter",
+	"Power Management / Power Distribution Board",
+	"Chassis Back Panel Board",
+	"System Chassis",
+	"Sub-Chassis",
+	"Other Chassis Board",
+	"Disk Drive Bay",
+	"Peripheral Bay",
+	"Device Bay",
+	"Fan / Cooling Device",
+	"Cooling Unit / Cooling Domain",
+	"Cable / Interconnect",
+	"Memory Device",
+	"System Management Software",
+	"System Firmware",
+	"Operating System",
+	"System Bus",
+	"Group",
+	"Remote Management Communication Device",
+	"External Environment",
+	"Battery",
+	"Processing Blade",
+	"Connectivity Switch",
+	"Processor/Memory Module",
+	"I/O Module",
+	"Processor/IO Module",
+	"Management Controller Firmware",
+	"IPMI Channel",
+	"PCI Bus",
+	"PCI Express Bus",
+	"SCSI Bus (parallel)",
+	"SATA / SAS Bus",
+	"Processor / Front-side Bus",
+	"Real Time Clock (RTC)",
+	"reserved",
+	"Air Inlet",
+	"reserved",
+	"reserved",
+	"reserved",
+	"reserved",
+	"reserved",
+	"reserved",
+	"reserved",
+	"reserved",
+	"Air Inlet",
+	"Processor",
+	"Baseboard",
+}
+
+func (e EntityID) String() string {
+	switch i := int(e); {
+	case i < len(entityIDDescriptions):
+		return entityIDDescriptions[i]
+	case i >= 0x90 && i <= 0xaf:
+		return fmt.Sprintf("Chassis-specific(%d)", i)
+	case i >= 0xb0 && i <= 0xcf:
+		return fmt.Sprintf("Board-set specific(%d)", i)
+	case i >= 0xd0:
+		return fmt.Sprintf("OEM(%d)", i)
+	default:
+		return fmt.Sprintf("Reserved(%d)", i)
+	}
+}
+
 // Sensor Unit Type (Section 43.17)
 type UnitType uint8
 
@@ -206,3 +405,149 @@ func (u UnitType) String() string {
 	}
 	return fmt.Sprint("unknown(%d)", u)
 }
+
+// Sensor Rate Unit (Table 43-1, Sensor Units 1)
+type RateUnit uint8
+
+const (
+	RateUnitNone RateUnit = iota
+	RateUnitMicrosecond
+	RateUnitMillisecond
+	RateUnitSecond
+	RateUnitMinute
+	RateUnitHour
+	RateUnitDay
+)
+
+var rateUnitAbbreviations []string = []string{"", "us", "ms", "sec", "min", "hour", "day"}
+
+func (r RateUnit) String() string {
+	switch r {
+	case RateUnitNone:
+		return "none"
+	case RateUnitMicrosecond:
+		return "per microsecond"
+	case RateUnitMillisecond:
+		return "per millisecond"
+	case RateUnitSecond:
+		return "per second"
+	case RateUnitMinute:
+		return "per minute"
+	case RateUnitHour:
+		return "per hour"
+	case RateUnitDay:
+		return "per day"
+	default:
+		return fmt.Sprintf("reserved(%d)", uint8(r))
+	}
+}
+
+// Returns the abbreviation used in the unit string (e.g. "sec")
+func (r RateUnit) Abbreviation() string {
+	if i := int(r); i < len(rateUnitAbbreviations) {
+		return rateUnitAbbreviations[i]
+	}
+	return fmt.Sprintf("reserved(%d)", uint8(r))
+}
+
+// Reading of a sensor
+type SensorReading struct {
+	Name   string
+	Type   SensorType
+	Number uint8
+	Raw    uint8           // Raw reading
+	Value  float64         // Converted reading (Only for analog sensors)
+	Analog bool            // The reading is analog
+	Unit   string          // Unit string of the analog reading, "discrete" otherwise
+	Status ThresholdStatus // Threshold status (Only for threshold-base sensors)
+	States DiscreteStates  // Asserted states (Only for discrete sensors)
+	Valid  bool            // The reading is available
+
+	// Completion code if Get Sensor Reading failed
+	CompletionCode CompletionCode
+}
+
+// Returns the readings of all full and compact sensors in SDR repository.
+// Sensors that fail to read are returned with `Valid` false and the completion code.
+func SensorReadings(c *Client) ([]*SensorReading, error) {
+	records, err := SDRGetRecordsRepo(c, func(id uint16, t SDRType) bool {
+		return t == SDRTypeFullSensor || t == SDRTypeCompactSensor
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []*SensorReading
+	for _, ls := range ExpandSensors(records) {
+		switch s := ls.Record.(type) {
+		case *SDRFullSensor:
+			sr := &SensorReading{
+				Name:   ls.SensorID,
+				Type:   s.SensorType,
+				Number: ls.SensorNumber,
+				Analog: s.IsAnalogReading(),
+				Unit:   "discrete",
+			}
+			gsr, err := sensorReading(c, &s.SDRCommonSensor, sr.Number, sr)
+			if err != nil {
+				return nil, err
+			}
+			if sr.Valid {
+				if sr.Analog {
+					sr.Value = s.ConvertSensorReading(gsr.SensorReading)
+					sr.Unit = s.UnitString()
+				}
+				if s.IsThresholdBaseSensor() {
+					sr.Status = gsr.ThresholdStatus()
+				} else {
+					sr.States = gsr.DiscreteStates()
+				}
+			}
+			readings = append(readings, sr)
+		case *SDRCompactSensor:
+			sr := &SensorReading{
+				Name:   ls.SensorID,
+				Type:   s.SensorType,
+				Number: ls.SensorNumber,
+				Unit:   "discrete",
+			}
+			gsr, err := sensorReading(c, &s.SDRCommonSensor, sr.Number, sr)
+			if err != nil {
+				return nil, err
+			}
+			if sr.Valid {
+				if s.EventReadingType == 0x01 {
+					sr.Status = gsr.ThresholdStatus()
+				} else {
+					sr.States = gsr.DiscreteStates()
+				}
+			}
+			readings = append(readings, sr)
+		}
+	}
+	return readings, nil
+}
+
+// Executes Get Sensor Reading and fills the raw reading.
+// The request is bridged to the owner if the sensor is owned by a satellite controller.
+// Only errors other than the command error are returned.
+func sensorReading(c *Client, s *SDRCommonSensor, num uint8, sr *SensorReading) (*GetSensorReadingCommand, error) {
+	gsr := &GetSensorReadingCommand{RsLUN: s.OwnerLUN, SensorNumber: num}
+
+	var cmd Command = gsr
+	if target, ok := s.OwnerTarget(); ok {
+		cmd = NewBridgedCommand(gsr, target)
+	}
+	if err := c.Execute(cmd); err != nil {
+		e, ok := err.(*CommandError)
+		if !ok {
+			return nil, err
+		}
+		sr.CompletionCode = e.CompletionCode
+		return gsr, nil
+	}
+
+	sr.Raw = gsr.SensorReading
+	sr.Valid = gsr.IsValid()
+	return gsr, nil
+}
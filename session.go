@@ -2,6 +2,7 @@ package ipmigo
 
 import (
 	"fmt"
+	"net"
 )
 
 // Payload Type (Section 13.27.3)
@@ -49,30 +50,30 @@ func (p payloadType) Authenticated() bool {
 }
 
 // Authentication Type (Section 13.6)
-type authType uint8
+type AuthType uint8
 
 const (
-	authTypeNone     authType = 0x0
-	authTypeMD2               = 0x1
-	authTypeMD5               = 0x2
-	authTypePassword          = 0x4
-	authTypeOEM               = 0x5
-	authTypeRMCPPlus          = 0x6
+	AuthTypeNone     AuthType = 0x0
+	AuthTypeMD2      AuthType = 0x1
+	AuthTypeMD5      AuthType = 0x2
+	AuthTypePassword AuthType = 0x4
+	AuthTypeOEM      AuthType = 0x5
+	AuthTypeRMCPPlus AuthType = 0x6
 )
 
-func (a authType) String() string {
+func (a AuthType) String() string {
 	switch a {
-	case authTypeNone:
+	case AuthTypeNone:
 		return "NONE"
-	case authTypeMD2:
+	case AuthTypeMD2:
 		return "MD2"
-	case authTypeMD5:
+	case AuthTypeMD5:
 		return "MD5"
-	case authTypePassword:
+	case AuthTypePassword:
 		return "PASSWORD"
-	case authTypeOEM:
+	case AuthTypeOEM:
 		return "OEM"
-	case authTypeRMCPPlus:
+	case AuthTypeRMCPPlus:
 		return "RMCP+"
 	default:
 		return fmt.Sprintf("Reserved(%d)", a)
@@ -81,7 +82,8 @@ func (a authType) String() string {
 
 type sessionHeader interface {
 	ID() uint32
-	AuthType() authType
+	Sequence() uint32
+	AuthType() AuthType
 	PayloadType() payloadType
 	SetEncrypted(bool)
 	SetAuthenticated(bool)
@@ -93,8 +95,86 @@ type sessionHeader interface {
 }
 
 type session interface {
-	Ping() error
+	Ping() (*Pong, error)
 	Open() error
 	Close() error
-	Execute(Command) error
+	Execute(Command, Options) error
+	Info() SessionInfo
+}
+
+// Parameters of the session negotiated with BMC
+type SessionInfo struct {
+	Open           bool           // Whether the session is active
+	ID             uint32         // Managed system session ID
+	Address        string         // Address of the session
+	PrivilegeLevel PrivilegeLevel // Privilege level granted to the session
+	AuthType       AuthType       // Authentication type (v1.5 only)
+	CipherSuiteID  uint           // ID of the negotiated cipher suite (v2.0 only)
+}
+
+// Tries to open a session with each address in order, starting from the last working one
+func openFailover(args *Arguments, last *string, open func(addr string) error) (err error) {
+	addrs := append([]string{args.Address}, args.Addresses...)
+	for i, addr := range addrs {
+		if addr == *last {
+			addrs = append(addrs[i:], addrs[:i]...)
+			break
+		}
+	}
+
+	for _, addr := range addrs {
+		if addr == "" {
+			continue
+		}
+
+		// The hostname is resolved again on every attempt
+		targets := []string{addr}
+		if args.RotateAddresses {
+			targets = resolveAddresses(addr)
+		}
+		for _, target := range targets {
+			if err = open(target); err == nil {
+				// Remember the hostname rather than the resolved address
+				*last = addr
+				return
+			}
+		}
+	}
+	return
+}
+
+// Returns the addresses of all A/AAAA records of the host
+func resolveAddresses(addr string) []string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return []string{addr}
+	}
+
+	ips, err := net.LookupHost(host)
+	if err != nil || len(ips) == 0 {
+		// Leave the error to the dial
+		return []string{addr}
+	}
+
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.JoinHostPort(ip, port)
+	}
+	return addrs
+}
+
+// Returns the last working address, or the primary one
+func currentAddress(args *Arguments, last string) string {
+	if last != "" {
+		return last
+	}
+	return args.Address
+}
+
+// Connects to the address directly or via the dispatcher
+func dial(args *Arguments, addr string) (net.Conn, error) {
+	if d := args.Dispatcher; d != nil {
+		return d.dial(args.Network, addr, args.Version == V2_0)
+	}
+	return net.DialTimeout(args.Network, addr, args.Timeout)
 }
@@ -0,0 +1,259 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Parameter of the SOL configuration (Table 26-5)
+type SOLConfigParameter interface {
+	Selector() uint8
+	Marshal() ([]byte, error)
+	Unmarshal(buf []byte) ([]byte, error)
+}
+
+// SOL configuration parameter selectors (Table 26-5)
+const (
+	SOLParamSetInProgress       uint8 = 0
+	SOLParamEnable              uint8 = 1
+	SOLParamAuthentication      uint8 = 2
+	SOLParamCharacterAccumulate uint8 = 3
+	SOLParamRetry               uint8 = 4
+	SOLParamNonVolatileBitRate  uint8 = 5
+	SOLParamVolatileBitRate     uint8 = 6
+	SOLParamPayloadChannel      uint8 = 7
+	SOLParamPayloadPort         uint8 = 8
+)
+
+// SOL bit rate (Table 26-5, Parameter 5 and 6)
+type SOLBaudRate uint8
+
+const (
+	SOLBaudRateSerial SOLBaudRate = 0x00 // Use the setting of the system serial port
+	SOLBaudRate9600   SOLBaudRate = 0x06
+	SOLBaudRate19200  SOLBaudRate = 0x07
+	SOLBaudRate38400  SOLBaudRate = 0x08
+	SOLBaudRate57600  SOLBaudRate = 0x09
+	SOLBaudRate115200 SOLBaudRate = 0x0a
+)
+
+func (r SOLBaudRate) String() string {
+	switch r {
+	case SOLBaudRateSerial:
+		return "Serial"
+	case SOLBaudRate9600:
+		return "9.6"
+	case SOLBaudRate19200:
+		return "19.2"
+	case SOLBaudRate38400:
+		return "38.4"
+	case SOLBaudRate57600:
+		return "57.6"
+	case SOLBaudRate115200:
+		return "115.2"
+	default:
+		return fmt.Sprintf("Reserved(%d)", r)
+	}
+}
+
+// Set In Progress parameter (Selector 0)
+type SOLSetInProgress struct {
+	State uint8 // (0x00: Set complete, 0x01: Set in progress, 0x02: Commit write)
+}
+
+func (p *SOLSetInProgress) Selector() uint8          { return SOLParamSetInProgress }
+func (p *SOLSetInProgress) Marshal() ([]byte, error) { return []byte{p.State & 0x03}, nil }
+
+func (p *SOLSetInProgress) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.State = buf[0] & 0x03
+	return buf[1:], nil
+}
+
+// SOL Enable parameter (Selector 1)
+type SOLEnable struct {
+	Enabled bool
+}
+
+func (p *SOLEnable) Selector() uint8 { return SOLParamEnable }
+
+func (p *SOLEnable) Marshal() ([]byte, error) {
+	if p.Enabled {
+		return []byte{0x01}, nil
+	}
+	return []byte{0x00}, nil
+}
+
+func (p *SOLEnable) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.Enabled = buf[0]&0x01 != 0
+	return buf[1:], nil
+}
+
+// SOL Authentication parameter (Selector 2)
+type SOLAuthentication struct {
+	ForceEncryption     bool
+	ForceAuthentication bool
+	PrivilegeLevel      PrivilegeLevel // Minimum privilege level required to activate SOL
+}
+
+func (p *SOLAuthentication) Selector() uint8 { return SOLParamAuthentication }
+
+func (p *SOLAuthentication) Marshal() ([]byte, error) {
+	b := byte(p.PrivilegeLevel) & 0x0f
+	if p.ForceEncryption {
+		b |= 0x80
+	}
+	if p.ForceAuthentication {
+		b |= 0x40
+	}
+	return []byte{b}, nil
+}
+
+func (p *SOLAuthentication) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.ForceEncryption = buf[0]&0x80 != 0
+	p.ForceAuthentication = buf[0]&0x40 != 0
+	p.PrivilegeLevel = PrivilegeLevel(buf[0] & 0x0f)
+	return buf[1:], nil
+}
+
+// Character Accumulate Interval and Send Threshold parameter (Selector 3)
+type SOLCharacterAccumulate struct {
+	Interval  uint8 // Accumulate interval in 5 ms increments (1-based)
+	Threshold uint8 // Number of characters to send the packet (1-based)
+}
+
+func (p *SOLCharacterAccumulate) Selector() uint8 { return SOLParamCharacterAccumulate }
+
+func (p *SOLCharacterAccumulate) Marshal() ([]byte, error) {
+	if p.Interval == 0 || p.Threshold == 0 {
+		return nil, &ArgumentError{Value: p, Message: "Character accumulate interval and threshold must be 1-based"}
+	}
+	return []byte{p.Interval, p.Threshold}, nil
+}
+
+func (p *SOLCharacterAccumulate) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 2); err != nil {
+		return nil, err
+	}
+	p.Interval = buf[0]
+	p.Threshold = buf[1]
+	return buf[2:], nil
+}
+
+// SOL Retry parameter (Selector 4)
+type SOLRetry struct {
+	Count    uint8 // Retry count (0-7, 0: No retries)
+	Interval uint8 // Retry interval in 10 ms increments (0: Retries sent back-to-back)
+}
+
+func (p *SOLRetry) Selector() uint8 { return SOLParamRetry }
+
+func (p *SOLRetry) Marshal() ([]byte, error) {
+	if p.Count > 0x07 {
+		return nil, &ArgumentError{Value: p.Count, Message: "SOL retry count must be in the range 0 to 7"}
+	}
+	return []byte{p.Count, p.Interval}, nil
+}
+
+func (p *SOLRetry) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 2); err != nil {
+		return nil, err
+	}
+	p.Count = buf[0] & 0x07
+	p.Interval = buf[1]
+	return buf[2:], nil
+}
+
+// SOL Non-volatile Bit Rate (Selector 5) and SOL Volatile Bit Rate (Selector 6) parameter
+type SOLBitRate struct {
+	Volatile bool // Volatile bit rate (false: Non-volatile)
+	Rate     SOLBaudRate
+}
+
+func (p *SOLBitRate) Selector() uint8 {
+	if p.Volatile {
+		return SOLParamVolatileBitRate
+	}
+	return SOLParamNonVolatileBitRate
+}
+
+func (p *SOLBitRate) Marshal() ([]byte, error) { return []byte{byte(p.Rate) & 0x0f}, nil }
+
+func (p *SOLBitRate) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.Rate = SOLBaudRate(buf[0] & 0x0f)
+	return buf[1:], nil
+}
+
+// SOL Payload Channel parameter (Selector 7), which is read only
+type SOLPayloadChannel struct {
+	Channel uint8
+}
+
+func (p *SOLPayloadChannel) Selector() uint8 { return SOLParamPayloadChannel }
+
+func (p *SOLPayloadChannel) Marshal() ([]byte, error) {
+	return nil, &ArgumentError{Value: p, Message: "SOL payload channel is read only"}
+}
+
+func (p *SOLPayloadChannel) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 1); err != nil {
+		return nil, err
+	}
+	p.Channel = buf[0] & 0x0f
+	return buf[1:], nil
+}
+
+// SOL Payload Port Number parameter (Selector 8)
+type SOLPayloadPort struct {
+	Port uint16 // (The default is 623)
+}
+
+func (p *SOLPayloadPort) Selector() uint8 { return SOLParamPayloadPort }
+
+func (p *SOLPayloadPort) Marshal() ([]byte, error) {
+	return []byte{byte(p.Port), byte(p.Port >> 8)}, nil
+}
+
+func (p *SOLPayloadPort) Unmarshal(buf []byte) ([]byte, error) {
+	if err := solValidateLength(p, buf, 2); err != nil {
+		return nil, err
+	}
+	p.Port = uint16(buf[0]) | uint16(buf[1])<<8
+	return buf[2:], nil
+}
+
+// SOL configuration parameter which is not decoded
+type SOLConfigRaw struct {
+	ID   uint8 // Parameter selector
+	Data []byte
+}
+
+func (p *SOLConfigRaw) Selector() uint8          { return p.ID }
+func (p *SOLConfigRaw) Marshal() ([]byte, error) { return p.Data, nil }
+
+func (p *SOLConfigRaw) Unmarshal(buf []byte) ([]byte, error) {
+	p.Data = make([]byte, len(buf))
+	copy(p.Data, buf)
+	return nil, nil
+}
+
+func solValidateLength(p SOLConfigParameter, buf []byte, min int) error {
+	if l := len(buf); l < min {
+		return &MessageError{
+			Message: fmt.Sprintf("Invalid SOL configuration parameter %d size : %d/%d", p.Selector(), l, min),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,530 @@
+package ipmigo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	solPacketHeaderSize = 4
+	solSequenceMax      = 0x0f
+	solDefaultDataSize  = 64
+	solPollInterval     = 100 * time.Millisecond
+	solKeepAliveDefault = 30 * time.Second
+	solKeepAliveCheck   = time.Second
+)
+
+// Operation bits of the SOL packet sent to BMC (Table 15-2)
+type SOLOperation uint8
+
+const (
+	SOLOperationFlushOutbound SOLOperation = 1 << iota
+	SOLOperationFlushInbound
+	SOLOperationDeassertDCD // Deassert DCD/DSR
+	SOLOperationDeassertCTS
+	SOLOperationBreak // Generate BREAK
+	SOLOperationRing  // Ring/WOR
+	SOLOperationNACK
+)
+
+// Status bits of the SOL packet sent from BMC (Table 15-2)
+type SOLStatus uint8
+
+const (
+	SOLStatusBreak       SOLStatus = 1 << (iota + 2) // BREAK detected
+	SOLStatusOverrun                                 // Transmit overrun
+	SOLStatusDeactivated                             // SOL deactivated
+	SOLStatusUnavailable                             // Character transfer unavailable
+	SOLStatusNACK
+)
+
+// SOL Payload Packet (Section 15.9)
+type SOLPacket struct {
+	Sequence      uint8        // Packet sequence number (1-15, 0: ACK/NACK only packet)
+	AckSequence   uint8        // Sequence number of the packet being acknowledged (0: No ACK/NACK)
+	AcceptedCount uint8        // Number of characters accepted from the acknowledged packet
+	Operation     SOLOperation // Operation to BMC (Only for sending)
+	Status        SOLStatus    // Status from BMC (Only for receiving)
+	Data          []byte       // Character data
+}
+
+func (p *SOLPacket) Marshal() ([]byte, error) {
+	if p.Sequence > solSequenceMax || p.AckSequence > solSequenceMax {
+		return nil, &ArgumentError{
+			Value:   p,
+			Message: "SOL sequence number must be in the range 0 to 15",
+		}
+	}
+	buf := []byte{p.Sequence, p.AckSequence, p.AcceptedCount, byte(p.Operation) & 0x7f}
+	return append(buf, p.Data...), nil
+}
+
+func (p *SOLPacket) Unmarshal(buf []byte) ([]byte, error) {
+	if l := len(buf); l < solPacketHeaderSize {
+		return nil, &MessageError{
+			Message: fmt.Sprintf("Invalid SOL packet size : %d/%d", l, solPacketHeaderSize),
+			Detail:  hex.EncodeToString(buf),
+		}
+	}
+	p.Sequence = buf[0] & 0x0f
+	p.AckSequence = buf[1] & 0x0f
+	p.AcceptedCount = buf[2]
+	p.Status = SOLStatus(buf[3] & 0x7c)
+	p.Data = make([]byte, len(buf)-solPacketHeaderSize)
+	copy(p.Data, buf[solPacketHeaderSize:])
+	return nil, nil
+}
+
+func (p *SOLPacket) String() string {
+	return fmt.Sprintf(`{"Sequence":%d,"AckSequence":%d,"AcceptedCount":%d,"Operation":%d,"Status":%d,"Data":"%s"}`,
+		p.Sequence, p.AckSequence, p.AcceptedCount, p.Operation, p.Status, hex.EncodeToString(p.Data))
+}
+
+// Statistics of the SOL console
+type SOLStats struct {
+	PacketsSent        uint64
+	PacketsReceived    uint64
+	Retransmits        uint64 // Packets retransmitted because they were not acknowledged
+	DuplicatesReceived uint64 // Packets retransmitted by BMC
+	NACKs              uint64 // NACK packets received
+	Overruns           uint64 // Transmit overruns reported by BMC
+	DroppedCharacters  uint64 // Characters given up to write
+	KeepAlives         uint64 // Keepalive packets sent
+}
+
+// SOL console of the activated SOL payload, which implements io.ReadWriteCloser.
+// Read and Write can be called concurrently, and the console shares the session with the client.
+type SOLConsole struct {
+	c        *Client
+	session  *sessionV2_0
+	instance uint8
+	maxData  int // Maximum size of the character data sent to BMC
+
+	mu          sync.Mutex
+	sequence    uint8                // Sequence number of the last packet sent
+	acks        map[uint8]*SOLPacket // ACK/NACK packets by the acknowledged sequence number
+	received    []*SOLPacket         // Received packets which carry the character data
+	lastRecv    uint8                // Sequence number of the last packet read
+	pending     []byte               // Character data not yet read
+	deactivated bool                 // Deactivated by BMC
+	lines       SOLOperation         // Deasserted handshake lines, which are kept in every packet
+	stats       SOLStats             // Counters of the packets
+	lastSent    time.Time            // Time of the last packet sent
+	keepAlive   time.Duration        // Interval of the keepalive packets when idle (0: Disabled)
+	done        chan struct{}        // Closed when the console is closed
+	closed      bool
+}
+
+// Activates the SOL payload instance (1-based) and returns the console of it.
+// SOL requires IPMI v2.0, and the payload must be served on the port of the session.
+func (c *Client) ActivateSOL(instance uint8) (*SOLConsole, error) {
+	c.mu.Lock()
+	s, ok := c.session.(*sessionV2_0)
+	c.mu.Unlock()
+	if !ok {
+		return nil, &ArgumentError{
+			Value:   c.args.Version,
+			Message: "SOL requires IPMI v2.0",
+		}
+	}
+	if instance == 0 {
+		instance = 1
+	}
+	if err := c.Open(); err != nil {
+		return nil, err
+	}
+
+	cmd := &ActivatePayloadCommand{PayloadType: payloadTypeSOL, PayloadInstance: instance}
+	if requiredConfidentiality(s.cipherSuiteID) {
+		cmd.AuxData[0] |= 0x80
+	}
+	if requiredIntegrity(s.cipherSuiteID) {
+		cmd.AuxData[0] |= 0x40
+	}
+
+	con := &SOLConsole{
+		c:         c,
+		session:   s,
+		instance:  instance,
+		acks:      make(map[uint8]*SOLPacket),
+		keepAlive: solKeepAliveDefault,
+		done:      make(chan struct{}),
+	}
+
+	// Receives the SOL payload read by the other readers of the session from the activation
+	c.mu.Lock()
+	s.console = con
+	c.mu.Unlock()
+
+	if err := c.Execute(cmd); err != nil {
+		con.detach()
+		return nil, err
+	}
+
+	con.maxData = int(cmd.InboundSize) - solPacketHeaderSize
+	if con.maxData <= 0 {
+		con.maxData = solDefaultDataSize
+	}
+
+	if _, port, err := net.SplitHostPort(c.SessionInfo().Address); err == nil && cmd.Port != 0 &&
+		port != strconv.Itoa(int(cmd.Port)) {
+		con.Close()
+		return nil, &MessageError{Message: fmt.Sprintf("SOL payload port %d is not supported", cmd.Port)}
+	}
+
+	go con.keepAliveLoop()
+	return con, nil
+}
+
+// Returns the statistics of the console
+func (s *SOLConsole) Stats() SOLStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Sets the interval of the keepalive packets, which are sent when no packets are sent for the interval,
+// so that BMC does not deactivate the payload during quiet periods (The default is 30 seconds, 0 disables)
+func (s *SOLConsole) SetKeepAlive(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keepAlive = interval
+}
+
+func (s *SOLConsole) keepAliveLoop() {
+	ticker := time.NewTicker(solKeepAliveCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			idle := s.keepAlive > 0 && now.Sub(s.lastSent) >= s.keepAlive
+			s.mu.Unlock()
+			if !idle {
+				continue
+			}
+
+			// An empty packet which has neither the sequence number nor ACK
+			if err := s.write(&SOLPacket{Operation: s.operation(0)}); err == nil {
+				s.mu.Lock()
+				s.stats.KeepAlives++
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Returns the next packet sequence number (1-15)
+func (s *SOLConsole) NextSequence() uint8 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sequence = s.sequence%solSequenceMax + 1
+	return s.sequence
+}
+
+// Sends the packet to BMC. If the packet has the sequence number, the ACK/NACK packet of it is returned,
+// and the packet is retransmitted until it is acknowledged. Otherwise nil is returned.
+// Packets received while waiting are kept for RecvSOLPacket.
+func (s *SOLConsole) SendSOLPacket(p *SOLPacket) (*SOLPacket, error) {
+	if p.Sequence == 0 {
+		return nil, s.write(p)
+	}
+
+	s.mu.Lock()
+	delete(s.acks, p.Sequence)
+	s.mu.Unlock()
+
+	opts := s.c.args.options()
+	var ack *SOLPacket
+	attempts := 0
+	err := retry(opts.Retries, &s.c.args.Backoff, func() error {
+		if attempts++; attempts > 1 {
+			s.mu.Lock()
+			s.stats.Retransmits++
+			s.mu.Unlock()
+		}
+		if err := s.write(p); err != nil {
+			return err
+		}
+		deadline := time.Now().Add(opts.Timeout)
+		for {
+			s.mu.Lock()
+			ack = s.acks[p.Sequence]
+			delete(s.acks, p.Sequence)
+			s.mu.Unlock()
+			if ack != nil {
+				return nil
+			}
+			if err := s.poll(deadline); err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ack, nil
+}
+
+// Returns the next packet which carries the character data from BMC.
+// The caller must acknowledge the packet by SendSOLPacket, otherwise BMC retransmits it.
+func (s *SOLConsole) RecvSOLPacket(timeout time.Duration) (*SOLPacket, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		s.mu.Lock()
+		if len(s.received) > 0 {
+			p := s.received[0]
+			s.received = s.received[1:]
+			s.mu.Unlock()
+			return p, nil
+		}
+		deactivated := s.deactivated
+		s.mu.Unlock()
+		if deactivated {
+			return nil, io.EOF
+		}
+
+		if err := s.poll(deadline); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Reads the character data from BMC, which blocks until any data is received
+func (s *SOLConsole) Read(b []byte) (int, error) {
+	for {
+		s.mu.Lock()
+		if len(s.pending) > 0 {
+			n := copy(b, s.pending)
+			s.pending = s.pending[n:]
+			s.mu.Unlock()
+			return n, nil
+		}
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return 0, io.EOF
+		}
+
+		p, err := s.RecvSOLPacket(solPollInterval)
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				continue
+			}
+			return 0, err
+		}
+
+		ack := &SOLPacket{AckSequence: p.Sequence, AcceptedCount: uint8(len(p.Data)), Operation: s.operation(0)}
+		if _, err := s.SendSOLPacket(ack); err != nil {
+			return 0, err
+		}
+
+		s.mu.Lock()
+		if p.Sequence != s.lastRecv {
+			s.lastRecv = p.Sequence
+			s.pending = append(s.pending, p.Data...)
+		} else {
+			s.stats.DuplicatesReceived++
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Writes the character data to BMC, which returns after all data is accepted
+func (s *SOLConsole) Write(b []byte) (int, error) {
+	written, nacks := 0, 0
+	giveUp := func(err error) (int, error) {
+		s.mu.Lock()
+		s.stats.DroppedCharacters += uint64(len(b))
+		s.mu.Unlock()
+		return written, err
+	}
+
+	for len(b) > 0 {
+		n := len(b)
+		if n > s.maxData {
+			n = s.maxData
+		}
+
+		ack, err := s.SendSOLPacket(&SOLPacket{Sequence: s.NextSequence(), Operation: s.operation(0), Data: b[:n]})
+		if err != nil {
+			return giveUp(err)
+		}
+		if ack.Status&SOLStatusDeactivated != 0 {
+			return giveUp(io.ErrClosedPipe)
+		}
+
+		accepted := int(ack.AcceptedCount)
+		if ack.Status&SOLStatusNACK != 0 || accepted == 0 {
+			// BMC can not accept the characters now
+			if nacks++; nacks > int(s.c.args.Retries) {
+				return giveUp(&MessageError{Message: "SOL packet is not accepted", Detail: ack.String()})
+			}
+			time.Sleep(s.c.args.Backoff.Delay(nacks))
+			continue
+		}
+		if accepted > n {
+			accepted = n
+		}
+		nacks = 0
+		written += accepted
+		b = b[accepted:]
+	}
+	return written, nil
+}
+
+// Generates a serial BREAK
+func (s *SOLConsole) SendBreak() error { return s.control(SOLOperationBreak) }
+
+// Flushes the data which BMC has received from the remote console but not yet sent to the serial port
+func (s *SOLConsole) FlushInbound() error { return s.control(SOLOperationFlushInbound) }
+
+// Flushes the data which BMC has received from the serial port but not yet sent to the remote console
+func (s *SOLConsole) FlushOutbound() error { return s.control(SOLOperationFlushOutbound) }
+
+// Generates Ring/WOR (Wake On Ring)
+func (s *SOLConsole) Ring() error { return s.control(SOLOperationRing) }
+
+// Asserts or deasserts CTS to the system serial port, which pauses the output of the system
+func (s *SOLConsole) SetCTS(asserted bool) error { return s.setLine(SOLOperationDeassertCTS, asserted) }
+
+// Asserts or deasserts DCD/DSR to the system serial port (DTR of the console side)
+func (s *SOLConsole) SetDCD(asserted bool) error { return s.setLine(SOLOperationDeassertDCD, asserted) }
+
+func (s *SOLConsole) setLine(line SOLOperation, asserted bool) error {
+	s.mu.Lock()
+	if asserted {
+		s.lines &^= line
+	} else {
+		s.lines |= line
+	}
+	s.mu.Unlock()
+	return s.control(0)
+}
+
+// Returns the operation bits with the state of the handshake lines
+func (s *SOLConsole) operation(op SOLOperation) SOLOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return op | s.lines
+}
+
+// Sends the operation packet without the character data
+func (s *SOLConsole) control(op SOLOperation) error {
+	ack, err := s.SendSOLPacket(&SOLPacket{Sequence: s.NextSequence(), Operation: s.operation(op)})
+	if err != nil {
+		return err
+	}
+	if ack.Status&SOLStatusNACK != 0 {
+		return &MessageError{Message: "SOL operation is not accepted", Detail: ack.String()}
+	}
+	return nil
+}
+
+// Deactivates the SOL payload. The session of the client is kept open.
+func (s *SOLConsole) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	deactivated := s.deactivated
+	s.mu.Unlock()
+	close(s.done)
+	s.detach()
+
+	err := s.c.Execute(&DeactivatePayloadCommand{PayloadType: payloadTypeSOL, PayloadInstance: s.instance})
+	if deactivated {
+		// The payload has been deactivated by BMC
+		return nil
+	}
+	return err
+}
+
+// Sends the packet with the session header of the SOL payload
+func (s *SOLConsole) write(p *SOLPacket) error {
+	s.c.mu.Lock()
+	pkt := &ipmiPacket{
+		RMCPHeader:    newRMCPHeaderForIPMI(),
+		SessionHeader: s.session.Header(payloadTypeSOL),
+		Request:       p,
+	}
+	err := s.session.writePacket(pkt, s.c.args.Timeout)
+	s.c.mu.Unlock()
+
+	if err == nil {
+		s.mu.Lock()
+		s.stats.PacketsSent++
+		s.lastSent = time.Now()
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// Receives a packet until the deadline, which is delivered to the console by the session
+func (s *SOLConsole) poll(deadline time.Time) error {
+	timeout := deadline.Sub(time.Now())
+	if timeout <= 0 {
+		return errResponseTimeout
+	}
+	if timeout > solPollInterval {
+		// Not to block the other reader or writer for a long time
+		timeout = solPollInterval
+	}
+
+	s.c.mu.Lock()
+	_, err := s.session.RecvPacket(timeout)
+	s.c.mu.Unlock()
+	if err != nil {
+		if e, ok := err.(net.Error); ok && e.Timeout() {
+			return nil
+		}
+		if discardable(err) {
+			// Discard a broken or unexpected datagram
+			return nil
+		}
+		return err
+	}
+	// The SOL packet has been delivered by the session, and a stale response of the other payload is discarded
+	return nil
+}
+
+// Sorts the packet received by the session into the ACK/NACK and the received packets
+func (s *SOLConsole) deliver(p *SOLPacket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.PacketsReceived++
+	if p.Status&SOLStatusNACK != 0 {
+		s.stats.NACKs++
+	}
+	if p.Status&SOLStatusOverrun != 0 {
+		s.stats.Overruns++
+	}
+	if p.AckSequence != 0 {
+		s.acks[p.AckSequence] = p
+	}
+	if p.Sequence != 0 {
+		s.received = append(s.received, p)
+	}
+	if p.Status&SOLStatusDeactivated != 0 {
+		s.deactivated = true
+	}
+}
+
+// Stops receiving the SOL payload from the session
+func (s *SOLConsole) detach() {
+	s.c.mu.Lock()
+	if s.session.console == s {
+		s.session.console = nil
+	}
+	s.c.mu.Unlock()
+}
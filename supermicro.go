@@ -0,0 +1,176 @@
+package ipmigo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	SupermicroIANA = 0x002a7c // Super Micro Computer Inc.
+
+	supermicroNetFnOEM     NetFn = NetFnOEMReq
+	supermicroNetFnGeneric NetFn = 0x3c
+)
+
+func init() {
+	// Set Fan Mode shares the command code with Get Fan Mode
+	RegisterOEMCommand(SupermicroIANA, supermicroNetFnOEM, 0x45,
+		func() Command { return &GetSupermicroFanModeCommand{} })
+	RegisterOEMCommand(SupermicroIANA, supermicroNetFnGeneric, 0x20,
+		func() Command { return &GetSupermicroFirmwareInfoCommand{} })
+	RegisterOEMCommand(SupermicroIANA, supermicroNetFnOEM, 0x9d,
+		func() Command { return &GetSupermicroVirtualMediaStatusCommand{} })
+}
+
+// Supermicro fan mode
+type SupermicroFanMode uint8
+
+const (
+	SupermicroFanModeStandard SupermicroFanMode = 0x00
+	SupermicroFanModeFull     SupermicroFanMode = 0x01
+	SupermicroFanModeOptimal  SupermicroFanMode = 0x02
+	SupermicroFanModePUE      SupermicroFanMode = 0x03
+	SupermicroFanModeHeavyIO  SupermicroFanMode = 0x04
+)
+
+func (m SupermicroFanMode) String() string {
+	switch m {
+	case SupermicroFanModeStandard:
+		return "Standard"
+	case SupermicroFanModeFull:
+		return "Full"
+	case SupermicroFanModeOptimal:
+		return "Optimal"
+	case SupermicroFanModePUE:
+		return "PUE"
+	case SupermicroFanModeHeavyIO:
+		return "Heavy IO"
+	default:
+		return fmt.Sprintf("Unknown(%d)", m)
+	}
+}
+
+// Get Fan Mode Command (Supermicro OEM)
+type GetSupermicroFanModeCommand struct {
+	// Response Data
+	Mode SupermicroFanMode
+}
+
+func (c *GetSupermicroFanModeCommand) Name() string    { return "Get Supermicro Fan Mode" }
+func (c *GetSupermicroFanModeCommand) Code() uint8     { return 0x45 }
+func (c *GetSupermicroFanModeCommand) Retryable() bool { return true }
+
+func (c *GetSupermicroFanModeCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(supermicroNetFnOEM, 0)
+}
+
+func (c *GetSupermicroFanModeCommand) String() string           { return cmdToJSON(c) }
+func (c *GetSupermicroFanModeCommand) Marshal() ([]byte, error) { return []byte{0x00}, nil }
+
+func (c *GetSupermicroFanModeCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.Mode = SupermicroFanMode(buf[0])
+	return buf[1:], nil
+}
+
+// Set Fan Mode Command (Supermicro OEM)
+type SetSupermicroFanModeCommand struct {
+	// Request Data
+	Mode SupermicroFanMode
+}
+
+func (c *SetSupermicroFanModeCommand) Name() string { return "Set Supermicro Fan Mode" }
+func (c *SetSupermicroFanModeCommand) Code() uint8  { return 0x45 }
+
+func (c *SetSupermicroFanModeCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(supermicroNetFnOEM, 0)
+}
+
+func (c *SetSupermicroFanModeCommand) String() string { return cmdToJSON(c) }
+
+func (c *SetSupermicroFanModeCommand) Marshal() ([]byte, error) {
+	return []byte{0x01, uint8(c.Mode)}, nil
+}
+
+func (c *SetSupermicroFanModeCommand) Unmarshal(buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+// Get Extra Firmware Info Command (Supermicro OEM)
+type GetSupermicroFirmwareInfoCommand struct {
+	// Response Data
+	MajorVersion uint32
+	MinorVersion uint8
+	SubVersion   uint8
+	BuildNumber  uint32
+	HardwareID   uint8
+	Tag          string // Firmware tag
+}
+
+func (c *GetSupermicroFirmwareInfoCommand) Name() string    { return "Get Supermicro Extra Firmware Info" }
+func (c *GetSupermicroFirmwareInfoCommand) Code() uint8     { return 0x20 }
+func (c *GetSupermicroFirmwareInfoCommand) Retryable() bool { return true }
+
+func (c *GetSupermicroFirmwareInfoCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(supermicroNetFnGeneric, 0)
+}
+
+func (c *GetSupermicroFirmwareInfoCommand) String() string           { return cmdToJSON(c) }
+func (c *GetSupermicroFirmwareInfoCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetSupermicroFirmwareInfoCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 11); err != nil {
+		return nil, err
+	}
+	c.MajorVersion = binary.LittleEndian.Uint32(buf[0:4])
+	c.MinorVersion = buf[4]
+	c.SubVersion = buf[5]
+	c.BuildNumber = binary.LittleEndian.Uint32(buf[6:10])
+	c.HardwareID = buf[10]
+	tag := buf[11:]
+	if i := bytes.IndexByte(tag, 0); i >= 0 {
+		tag = tag[:i]
+	}
+	c.Tag = string(tag)
+	return nil, nil
+}
+
+// Returns the firmware version (e.g. "01.73.06")
+func (c *GetSupermicroFirmwareInfoCommand) Version() string {
+	return fmt.Sprintf("%02d.%02d.%02d", c.MajorVersion, c.MinorVersion, c.SubVersion)
+}
+
+// Get Virtual Media Status Command (Supermicro OEM)
+type GetSupermicroVirtualMediaStatusCommand struct {
+	// Response Data
+	FloppyMounted bool
+	CDROMMounted  bool
+	HDDMounted    bool
+}
+
+func (c *GetSupermicroVirtualMediaStatusCommand) Name() string {
+	return "Get Supermicro Virtual Media Status"
+}
+
+func (c *GetSupermicroVirtualMediaStatusCommand) Code() uint8     { return 0x9d }
+func (c *GetSupermicroVirtualMediaStatusCommand) Retryable() bool { return true }
+
+func (c *GetSupermicroVirtualMediaStatusCommand) NetFnRsLUN() NetFnRsLUN {
+	return NewNetFnRsLUN(supermicroNetFnOEM, 0)
+}
+
+func (c *GetSupermicroVirtualMediaStatusCommand) String() string           { return cmdToJSON(c) }
+func (c *GetSupermicroVirtualMediaStatusCommand) Marshal() ([]byte, error) { return []byte{}, nil }
+
+func (c *GetSupermicroVirtualMediaStatusCommand) Unmarshal(buf []byte) ([]byte, error) {
+	if err := cmdValidateLength(c, buf, 1); err != nil {
+		return nil, err
+	}
+	c.FloppyMounted = buf[0]&0x01 != 0
+	c.CDROMMounted = buf[0]&0x02 != 0
+	c.HDDMounted = buf[0]&0x04 != 0
+	return buf[1:], nil
+}
@@ -1,8 +1,12 @@
 package ipmigo
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"math/rand"
 	"net"
+	"strings"
+	"time"
 )
 
 func toJSON(s interface{}) string {
@@ -10,8 +14,71 @@ func toJSON(s interface{}) string {
 	return string(r)
 }
 
-func retry(retries int, f func() error) (err error) {
+// Returns the address with the default RMCP port if the port is omitted.
+// The host may be an IPv6 literal with or without brackets.
+func withDefaultPort(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	return net.JoinHostPort(host, defaultPort)
+}
+
+// Placeholder of the secret which is not revealed
+const redactedSecret = "REDACTED"
+
+// Returns the hex string of the secret if `reveal` is true, otherwise the placeholder
+func secretString(b []byte, reveal bool) string {
+	if !reveal {
+		return redactedSecret
+	}
+	return hex.EncodeToString(b)
+}
+
+// Backoff policy for retransmissions
+type Backoff struct {
+	Initial    time.Duration // Delay before the first retransmission (The default is `0` which no delay)
+	Multiplier float64       // Multiplier of the delay for each retransmission (The default is `2`)
+	Max        time.Duration // Maximum delay (The default is `0` which no limit)
+	Jitter     float64       // Randomization factor of the delay, 0.0 to 1.0 (The default is `0`)
+}
+
+// Returns the delay before the n-th retransmission
+func (b *Backoff) Delay(n int) time.Duration {
+	if b == nil || b.Initial <= 0 || n <= 0 {
+		return 0
+	}
+
+	m := b.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	d := float64(b.Initial)
+	for i := 1; i < n; i++ {
+		d *= m
+		if b.Max > 0 && d >= float64(b.Max) {
+			break
+		}
+	}
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+
+	if j := b.Jitter; j > 0 {
+		if j > 1 {
+			j = 1
+		}
+		d += d * j * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(d)
+}
+
+func retry(retries int, backoff *Backoff, f func() error) (err error) {
 	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			time.Sleep(backoff.Delay(i))
+		}
+
 		err = f()
 		switch e := err.(type) {
 		case net.Error:
@@ -0,0 +1,59 @@
+package ipmigo
+
+import (
+	"sync"
+	"time"
+)
+
+// A Watchdog resets the BMC watchdog timer periodically
+type Watchdog struct {
+	client *Client
+	set    SetWatchdogTimerCommand
+	poller *poller
+
+	once    sync.Once
+	stopErr error
+}
+
+// Returns the last error of resetting the timer
+func (w *Watchdog) Err() error { return w.poller.Err() }
+
+// Stops resetting and the watchdog timer.
+// It is safe to call more than once, and the later calls return the result of the first call.
+func (w *Watchdog) Stop() error {
+	w.once.Do(func() {
+		w.poller.Stop()
+
+		set := w.set
+		set.DontStopTimer = false
+		set.TimeoutAction = WatchdogActionNone
+		set.PreTimeoutInterrupt = WatchdogPreTimeoutNone
+		w.stopErr = w.client.Execute(&set)
+	})
+	return w.stopErr
+}
+
+// Arms the watchdog timer and resets it from a goroutine at the interval,
+// which should be shorter than the initial countdown.
+func (c *Client) StartWatchdog(set *SetWatchdogTimerCommand, interval time.Duration) (*Watchdog, error) {
+	if interval <= 0 || interval >= set.InitialCountdown {
+		return nil, &ArgumentError{
+			Value:   interval,
+			Message: "Interval must be positive and shorter than the initial countdown",
+		}
+	}
+
+	if err := c.Execute(set); err != nil {
+		return nil, err
+	}
+	// The timer starts by the first reset
+	if err := c.Execute(&ResetWatchdogTimerCommand{}); err != nil {
+		return nil, err
+	}
+
+	w := &Watchdog{client: c, set: *set}
+	w.poller = newPoller(interval, false, func(<-chan struct{}) error {
+		return c.Execute(&ResetWatchdogTimerCommand{})
+	}, nil)
+	return w, nil
+}